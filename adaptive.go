@@ -0,0 +1,98 @@
+package consume
+
+import (
+	"sort"
+)
+
+const (
+	kAdaptiveReorderEvery = 1024
+)
+
+// AdaptiveFilters returns a MapFilterer that accepts a value only if every
+// filter in filters accepts it. Each filter in filters is either a
+// function like the one argument functions passed to MapFilter or a
+// Filterer. Since all filters must accept a value, the order they run in
+// doesn't matter. The returned MapFilterer takes advantage of this by
+// tracking how often each filter accepts values and periodically
+// reordering the filters so that the ones that reject the most values run
+// first. The filters must be pure, that is, have no side effects, for
+// this to be safe. Each MapFilterContext tracks its own statistics.
+// AdaptiveFilters panics if any of filters is a mapper.
+func AdaptiveFilters(filters ...interface{}) MapFilterer {
+	var stages []mapFilterStage
+	for _, f := range filters {
+		mfAddStages(f, &stages)
+	}
+	for _, stage := range stages {
+		if stage.newScratch() != nil {
+			panic("AdaptiveFilters accepts only filters")
+		}
+	}
+	if len(stages) == 0 {
+		return nilMapFilterer{}
+	}
+	return &stageMapFilterer{
+		list: []mapFilterStage{&adaptiveFilterStage{filters: stages}}}
+}
+
+type adaptiveFilterStage struct {
+	filters []mapFilterStage
+}
+
+// adaptiveFilterStats is the scratch space of an adaptiveFilterStage.
+type adaptiveFilterStats struct {
+	order     []int
+	evaluated []int
+	passed    []int
+	count     int
+}
+
+func (a *adaptiveFilterStage) newScratch() interface{} {
+	result := &adaptiveFilterStats{
+		order:     make([]int, len(a.filters)),
+		evaluated: make([]int, len(a.filters)),
+		passed:    make([]int, len(a.filters)),
+	}
+	for i := range result.order {
+		result.order[i] = i
+	}
+	return result
+}
+
+func (a *adaptiveFilterStage) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	stats := scratch.(*adaptiveFilterStats)
+	stats.count++
+	if stats.count == kAdaptiveReorderEvery {
+		stats.reorder()
+	}
+	for _, idx := range stats.order {
+		stats.evaluated[idx]++
+		if a.filters[idx].mapFilter(ptr, nil) == nil {
+			return nil
+		}
+		stats.passed[idx]++
+	}
+	return ptr
+}
+
+// reorder sorts filters by the fraction of values they accept, lowest
+// first. It then halves the counts so that the statistics follow changes
+// in the data.
+func (a *adaptiveFilterStats) reorder() {
+	sort.SliceStable(a.order, func(i, j int) bool {
+		return a.passRate(a.order[i]) < a.passRate(a.order[j])
+	})
+	for i := range a.evaluated {
+		a.evaluated[i] /= 2
+		a.passed[i] /= 2
+	}
+	a.count = 0
+}
+
+func (a *adaptiveFilterStats) passRate(idx int) float64 {
+	if a.evaluated[idx] == 0 {
+		return 1.0
+	}
+	return float64(a.passed[idx]) / float64(a.evaluated[idx])
+}
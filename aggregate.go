@@ -0,0 +1,245 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// Reduce returns a Consumer that folds the values it consumes into the
+// accumulator that accPtr points to. f is of the form
+// func(acc *A, ptr *T) where accPtr is a *A and the returned consumer
+// consumes T values. For each value consumed, the returned consumer
+// calls f with accPtr and a pointer to the value. For example:
+//
+//	var total int
+//	consumer := consume.Reduce(&total, func(acc *int, o *Order) {
+//	  *acc += o.Amount
+//	})
+//
+// Reduce leaves the accumulator as is before the first value, so callers
+// can set its starting value. The returned consumer can always consume.
+// Reduce panics if accPtr is not a pointer or if f is not of the right
+// form.
+func Reduce(accPtr interface{}, f interface{}) Consumer {
+	acc := reflect.ValueOf(accPtr)
+	if acc.Kind() != reflect.Ptr {
+		panic("accPtr must be a pointer")
+	}
+	fvalue := reflect.ValueOf(f)
+	ftype := fvalue.Type()
+	if ftype.Kind() != reflect.Func {
+		panic("Parameter must be a function")
+	}
+	if ftype.NumIn() != 2 || ftype.NumOut() != 0 {
+		panic("Function parameter must take 2 parameters and return nothing")
+	}
+	if ftype.In(0) != acc.Type() {
+		panic("First function parameter must be the type of accPtr")
+	}
+	if ftype.In(1).Kind() != reflect.Ptr {
+		panic("Function parameter must accept pointer arguments")
+	}
+	return &reduceConsumer{f: fvalue, args: []reflect.Value{acc, {}}}
+}
+
+type reduceConsumer struct {
+	f    reflect.Value
+	args []reflect.Value
+}
+
+func (r *reduceConsumer) CanConsume() bool {
+	return true
+}
+
+func (r *reduceConsumer) Consume(ptr interface{}) {
+	r.args[1] = reflect.ValueOf(ptr)
+	r.f.Call(r.args)
+}
+
+// Sum returns a Consumer that adds up what extract returns for each value
+// it consumes and stores the running total in the number sumPtr points
+// to. extract is of the form func(ptr *T) N where sumPtr is a *N and N is
+// an int, uint, or float type. If extract is nil, Sum adds up the N
+// values it consumes. Sum sets the total to zero before returning. Since
+// the total is always current, there is nothing to finalize. The returned
+// consumer can always consume. Sum panics if sumPtr or extract are not of
+// the right form.
+func Sum(sumPtr interface{}, extract interface{}) Consumer {
+	sum := reflect.ValueOf(sumPtr)
+	if sum.Kind() != reflect.Ptr || !isNumberKind(sum.Elem().Kind()) {
+		panic("sumPtr must point to a number")
+	}
+	e := newExtractor(extract, sum.Elem().Type())
+	sum = sum.Elem()
+	sum.Set(reflect.Zero(sum.Type()))
+	return &sumConsumer{sum: sum, extract: e}
+}
+
+// Min returns a Consumer that finds the value with the smallest key. key
+// is of the form func(ptr *T) K where K is a number or string type and
+// the returned consumer consumes T values. The returned consumer stores a
+// copy of the value with the smallest key so far in the T value that
+// resultPtr points to. If key is nil, values are their own keys. The copy
+// is shallow. Of values with the same key, the first one wins. If no
+// values are consumed, *resultPtr is left as is. Since *resultPtr is
+// always current, there is nothing to finalize. The returned consumer can
+// always consume. Min panics if resultPtr or key are not of the right
+// form, e.g if key is nil and resultPtr does not point to a number or
+// string.
+func Min(resultPtr interface{}, key interface{}) Consumer {
+	return newMinMax(resultPtr, key, false)
+}
+
+// Max works like Min except that it finds the value with the largest
+// key.
+func Max(resultPtr interface{}, key interface{}) Consumer {
+	return newMinMax(resultPtr, key, true)
+}
+
+type sumConsumer struct {
+	sum     reflect.Value
+	extract *extractor
+}
+
+func (s *sumConsumer) CanConsume() bool {
+	return true
+}
+
+func (s *sumConsumer) Consume(ptr interface{}) {
+	x := s.extract.call(ptr)
+	switch s.sum.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		s.sum.SetInt(s.sum.Int() + x.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		s.sum.SetUint(s.sum.Uint() + x.Uint())
+	default:
+		s.sum.SetFloat(s.sum.Float() + x.Float())
+	}
+}
+
+type minMaxConsumer struct {
+	result reflect.Value
+	key    *extractor
+	max    bool
+	best   reflect.Value
+	found  bool
+}
+
+func newMinMax(resultPtr interface{}, key interface{}, max bool) Consumer {
+	result := reflect.ValueOf(resultPtr)
+	if result.Kind() != reflect.Ptr {
+		panic("resultPtr must be a pointer")
+	}
+	var k *extractor
+	if key == nil {
+		k = newExtractor(nil, result.Elem().Type())
+	} else {
+		k = newExtractor(key, nil)
+		if k.f.Type().In(0) != result.Type() {
+			panic("key must take the type resultPtr is")
+		}
+	}
+	if !k.isOrdered() {
+		panic("key must return a number or string")
+	}
+	return &minMaxConsumer{result: result.Elem(), key: k, max: max}
+}
+
+func (m *minMaxConsumer) CanConsume() bool {
+	return true
+}
+
+func (m *minMaxConsumer) Consume(ptr interface{}) {
+	k := m.key.call(ptr)
+	if !m.found {
+		m.best = reflect.New(k.Type()).Elem()
+	} else if m.max && !lessValue(m.best, k) ||
+		!m.max && !lessValue(k, m.best) {
+		return
+	}
+	m.found = true
+	m.best.Set(k)
+	m.result.Set(reflect.ValueOf(ptr).Elem())
+}
+
+// extractor calls a function of the form func(ptr *T) X on consumed
+// values or returns the values themselves if there is no function.
+type extractor struct {
+	f       reflect.Value
+	outType reflect.Type
+	args    []reflect.Value
+}
+
+// newExtractor returns an extractor for f. If outType is non nil, f must
+// return outType or values must be outType if f is nil.
+func newExtractor(f interface{}, outType reflect.Type) *extractor {
+	if f == nil {
+		return &extractor{outType: outType}
+	}
+	fvalue := reflect.ValueOf(f)
+	ftype := fvalue.Type()
+	if ftype.Kind() != reflect.Func {
+		panic("Parameter must be a function")
+	}
+	if ftype.NumIn() != 1 || ftype.NumOut() != 1 {
+		panic("Function parameter must take 1 parameter and return 1 value")
+	}
+	if ftype.In(0).Kind() != reflect.Ptr {
+		panic("Function parameter must accept pointer arguments")
+	}
+	if outType != nil && ftype.Out(0) != outType {
+		panic("Function parameter returns the wrong type")
+	}
+	return &extractor{
+		f:       fvalue,
+		outType: ftype.Out(0),
+		args:    make([]reflect.Value, 1),
+	}
+}
+
+// isOrdered returns true if the extracted values are numbers or strings.
+func (e *extractor) isOrdered() bool {
+	kind := e.outType.Kind()
+	return isNumberKind(kind) || kind == reflect.String
+}
+
+func (e *extractor) call(ptr interface{}) reflect.Value {
+	if !e.f.IsValid() {
+		result := reflect.ValueOf(ptr).Elem()
+		if e.outType != nil && result.Type() != e.outType {
+			panic("Consumed value is the wrong type")
+		}
+		return result
+	}
+	e.args[0] = reflect.ValueOf(ptr)
+	return e.f.Call(e.args)[0]
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// lessValue returns true if x < y. x and y are numbers or strings of the
+// same kind.
+func lessValue(x, y reflect.Value) bool {
+	switch x.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return x.Int() < y.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return x.Uint() < y.Uint()
+	case reflect.Float32, reflect.Float64:
+		return x.Float() < y.Float()
+	case reflect.String:
+		return x.String() < y.String()
+	}
+	panic("key must return a number or string")
+}
@@ -0,0 +1,153 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReduce(t *testing.T) {
+	assert := assert.New(t)
+	total := 100
+	consumer := consume.Reduce(&total, func(acc *int, p *person) {
+		*acc += p.Age
+	})
+	consume.FeedSlice(people, consumer)
+	assert.True(consumer.CanConsume())
+	assert.Equal(318, total)
+}
+
+func TestReduceCustom(t *testing.T) {
+	assert := assert.New(t)
+	names := make(map[string]bool)
+	consume.FeedSlice(
+		people,
+		consume.Reduce(&names, func(acc *map[string]bool, p *person) {
+			(*acc)[p.Name] = true
+		}))
+	assert.Len(names, len(people))
+	assert.True(names["Mark"])
+}
+
+func TestReducePanics(t *testing.T) {
+	assert := assert.New(t)
+	var total int
+	assert.Panics(func() {
+		consume.Reduce(total, func(acc *int, ptr *int) {})
+	})
+	assert.Panics(func() {
+		consume.Reduce(&total, func(acc *int64, ptr *int) {})
+	})
+	assert.Panics(func() {
+		consume.Reduce(&total, func(acc *int, ptr int) {})
+	})
+	assert.Panics(func() {
+		consume.Reduce(&total, func(acc *int, ptr *int) bool { return true })
+	})
+	assert.Panics(func() {
+		consume.Reduce(&total, 3)
+	})
+}
+
+func TestSum(t *testing.T) {
+	assert := assert.New(t)
+	total := 7
+	consume.FeedSlice(
+		people,
+		consume.Sum(&total, func(p *person) int { return p.Age }))
+	assert.Equal(218, total)
+	var floatTotal float64
+	consume.FeedSlice(
+		[]float64{1.5, 2.25, 3.0}, consume.Sum(&floatTotal, nil))
+	assert.Equal(6.75, floatTotal)
+	var uintTotal uint8
+	consume.FeedSlice([]uint8{3, 4}, consume.Sum(&uintTotal, nil))
+	assert.Equal(uint8(7), uintTotal)
+}
+
+func TestSumPanics(t *testing.T) {
+	assert := assert.New(t)
+	var total int
+	var name string
+	assert.Panics(func() {
+		consume.Sum(total, nil)
+	})
+	assert.Panics(func() {
+		consume.Sum(&name, nil)
+	})
+	assert.Panics(func() {
+		consume.Sum(&total, func(p *person) int64 { return 0 })
+	})
+	assert.Panics(func() {
+		consume.Sum(&total, func(p person) int { return 0 })
+	})
+	consumer := consume.Sum(&total, nil)
+	assert.Panics(func() {
+		consumer.Consume(&name)
+	})
+}
+
+func TestMinMax(t *testing.T) {
+	assert := assert.New(t)
+	var youngest, oldest, first person
+	age := func(p *person) int { return p.Age }
+	consume.FeedSlice(
+		people,
+		consume.Compose(
+			consume.Min(&youngest, age),
+			consume.Max(&oldest, age),
+			consume.Min(&first, func(p *person) string { return p.Name })))
+	assert.Equal(people[dillon], youngest)
+	assert.Equal(people[beth], oldest)
+	assert.Equal(people[beth], first)
+}
+
+func TestMinMaxNoKey(t *testing.T) {
+	assert := assert.New(t)
+	smallest, largest := -1, -1
+	consume.FeedRange(3, 8, 1, consume.Min(&smallest, nil))
+	consume.FeedRange(3, 8, 1, consume.Max(&largest, nil))
+	assert.Equal(3, smallest)
+	assert.Equal(7, largest)
+}
+
+func TestMinMaxTies(t *testing.T) {
+	assert := assert.New(t)
+	var result person
+	consume.FeedSlice(
+		[]person{{Name: "a", Age: 3}, {Name: "b", Age: 3}},
+		consume.Max(&result, func(p *person) int { return p.Age }))
+	assert.Equal("a", result.Name)
+}
+
+func TestMinMaxEmpty(t *testing.T) {
+	assert := assert.New(t)
+	result := person{Name: "unchanged"}
+	consume.FeedSlice(
+		[]person{},
+		consume.Max(&result, func(p *person) int { return p.Age }))
+	assert.Equal("unchanged", result.Name)
+}
+
+func TestMinMaxPanics(t *testing.T) {
+	assert := assert.New(t)
+	var result person
+	assert.Panics(func() {
+		consume.Max(result, nil)
+	})
+	assert.Panics(func() {
+		consume.Max(&result, func(p *person) []int { return nil })
+	})
+	assert.Panics(func() {
+		consume.Min(&result, nil)
+	})
+	assert.Panics(func() {
+		consume.Min(&result, func(x *int) int { return *x })
+	})
+	var smallest int
+	consumer := consume.Min(&smallest, nil)
+	assert.Panics(func() {
+		consumer.Consume(&result)
+	})
+}
@@ -0,0 +1,62 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// ApplyTo returns a Consumer that updates the elements of an existing
+// slice in place. destSlicePtr points to the slice of elements to update.
+// For each consumed value, the returned consumer calls applyFunc on every
+// element for which matchFunc returns true. matchFunc takes a pointer to
+// an element followed by a pointer to the consumed value and returns a
+// bool, e.g func(dest *Person, src *Address) bool. applyFunc takes the
+// same arguments and returns nothing, e.g func(dest *Person, src *Address).
+// Each consumed value is compared against every element, so consuming N
+// values into a slice of M elements takes O(N*M) time. The returned
+// consumer can always consume. ApplyTo panics if destSlicePtr is not a
+// pointer to a slice or if matchFunc or applyFunc have the wrong types.
+func ApplyTo(destSlicePtr, matchFunc, applyFunc interface{}) Consumer {
+	aSliceValue := sliceValueFromP(destSlicePtr, false)
+	destPtrType := reflect.PtrTo(aSliceValue.Type().Elem())
+	matchType := reflect.TypeOf(matchFunc)
+	if matchType == nil || matchType.Kind() != reflect.Func ||
+		matchType.NumIn() != 2 || matchType.NumOut() != 1 ||
+		matchType.In(0) != destPtrType ||
+		matchType.In(1).Kind() != reflect.Ptr ||
+		matchType.Out(0).Kind() != reflect.Bool {
+		panic("matchFunc must be like func(dest *D, src *S) bool")
+	}
+	applyType := reflect.TypeOf(applyFunc)
+	if applyType == nil || applyType.Kind() != reflect.Func ||
+		applyType.NumIn() != 2 || applyType.NumOut() != 0 ||
+		applyType.In(0) != destPtrType ||
+		applyType.In(1) != matchType.In(1) {
+		panic("applyFunc must be like func(dest *D, src *S)")
+	}
+	return &applyToConsumer{
+		dest:      aSliceValue,
+		matchFunc: reflect.ValueOf(matchFunc),
+		applyFunc: reflect.ValueOf(applyFunc),
+	}
+}
+
+type applyToConsumer struct {
+	dest      reflect.Value
+	matchFunc reflect.Value
+	applyFunc reflect.Value
+}
+
+func (a *applyToConsumer) CanConsume() bool {
+	return true
+}
+
+func (a *applyToConsumer) Consume(ptr interface{}) {
+	src := reflect.ValueOf(ptr)
+	length := a.dest.Len()
+	for i := 0; i < length; i++ {
+		params := [...]reflect.Value{a.dest.Index(i).Addr(), src}
+		if a.matchFunc.Call(params[:])[0].Bool() {
+			a.applyFunc.Call(params[:])
+		}
+	}
+}
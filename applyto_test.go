@@ -0,0 +1,46 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyTo(t *testing.T) {
+	assert := assert.New(t)
+	type birthday struct {
+		Name string
+	}
+	dest := []person{people[mark], people[stoney], people[mark]}
+	consumer := consume.ApplyTo(
+		&dest,
+		func(dest *person, src *birthday) bool {
+			return dest.Name == src.Name
+		},
+		func(dest *person, src *birthday) {
+			dest.Age++
+		})
+	assert.True(consumer.CanConsume())
+	consumer.Consume(&birthday{Name: people[mark].Name})
+	consumer.Consume(&birthday{Name: "Nobody"})
+	assert.Equal(people[mark].Age+1, dest[0].Age)
+	assert.Equal(people[stoney].Age, dest[1].Age)
+	assert.Equal(people[mark].Age+1, dest[2].Age)
+}
+
+func TestApplyToPanics(t *testing.T) {
+	assert := assert.New(t)
+	var dest []person
+	match := func(dest *person, src *int) bool { return true }
+	apply := func(dest *person, src *int) {}
+	assert.Panics(func() { consume.ApplyTo(dest, match, apply) })
+	assert.Panics(func() {
+		consume.ApplyTo(
+			&dest, func(dest *int, src *int) bool { return true }, apply)
+	})
+	assert.Panics(func() {
+		consume.ApplyTo(&dest, match, func(dest *person, src *string) {})
+	})
+	assert.Panics(func() { consume.ApplyTo(&dest, match, nil) })
+}
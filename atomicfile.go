@@ -0,0 +1,126 @@
+package consume
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ToFileAtomic returns a ConsumeFinalizer that works like the
+// ConsumeFinalizer that newSink returns except that newSink writes to a
+// temporary file in the same directory as path. Finalize finalizes that
+// ConsumeFinalizer and then renames the temporary file to path so that
+// readers of path never see a partially written file. The renamed file
+// has mode 0644 so that other users can read it like files from
+// os.Create usually can. If the
+// ConsumeFinalizer from newSink implements Health and reports an error,
+// or if writing the temporary file fails, Finalize deletes the temporary
+// file instead, leaving path untouched. If the temporary file can't be
+// created, the returned consumer can't consume. The returned consumer
+// also implements TransactionalConsumer and Health. Rollback deletes the
+// temporary file, and Healthy reports the first error encountered.
+func ToFileAtomic(
+	path string,
+	newSink func(w io.Writer) ConsumeFinalizer) ConsumeFinalizer {
+	file, err := ioutil.TempFile(
+		filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return &atomicFileConsumer{path: path, err: err}
+	}
+	sink := newSink(file)
+	awaitCommit(sink)
+	return &atomicFileConsumer{path: path, file: file, sink: sink}
+}
+
+// kAtomicFileMode is the mode of files that ToFileAtomic writes.
+// ioutil.TempFile creates files with mode 0600.
+const kAtomicFileMode = 0644
+
+type atomicFileConsumer struct {
+	path     string
+	file     *os.File
+	sink     ConsumeFinalizer
+	err      error
+	prepared bool
+	done     bool
+}
+
+func (a *atomicFileConsumer) children() []interface{} {
+	return []interface{}{a.sink}
+}
+
+func (a *atomicFileConsumer) CanConsume() bool {
+	return a.err == nil && !a.prepared && !a.done && a.sink.CanConsume()
+}
+
+func (a *atomicFileConsumer) Consume(ptr interface{}) {
+	MustCanConsume(a)
+	a.sink.Consume(ptr)
+}
+
+func (a *atomicFileConsumer) Finalize() {
+	a.Commit()
+}
+
+func (a *atomicFileConsumer) Prepare() error {
+	if a.done {
+		return ErrTransactionDone
+	}
+	if a.prepared || a.file == nil {
+		a.prepared = true
+		return a.err
+	}
+	a.prepared = true
+	a.sink.Finalize()
+	a.setErr(CheckHealth(a.sink))
+	a.setErr(a.file.Chmod(kAtomicFileMode))
+	a.setErr(a.file.Sync())
+	a.setErr(a.file.Close())
+	return a.err
+}
+
+func (a *atomicFileConsumer) Commit() error {
+	if a.done {
+		return ErrTransactionDone
+	}
+	a.Prepare()
+	a.done = true
+	if a.file == nil {
+		return a.err
+	}
+	if a.err == nil {
+		a.setErr(os.Rename(a.file.Name(), a.path))
+	}
+	if a.err != nil {
+		os.Remove(a.file.Name())
+		return a.err
+	}
+	notifyCommitted(a.sink)
+	return nil
+}
+
+func (a *atomicFileConsumer) Rollback() error {
+	if a.done {
+		return ErrTransactionDone
+	}
+	a.done = true
+	if a.file == nil {
+		return nil
+	}
+	if !a.prepared {
+		a.sink.Finalize()
+		a.file.Close()
+	}
+	return os.Remove(a.file.Name())
+}
+
+func (a *atomicFileConsumer) Healthy() error {
+	return a.err
+}
+
+func (a *atomicFileConsumer) setErr(err error) {
+	if a.err == nil {
+		a.err = err
+	}
+}
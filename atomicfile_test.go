@@ -0,0 +1,85 @@
+package consume_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToFileAtomic(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "people.csv")
+	assert.NoError(ioutil.WriteFile(path, []byte("old"), 0600))
+	cf := consume.ToFileAtomic(path, newCSVSink)
+	consume.FeedSlice(people[:2], cf)
+	assertFileContents(t, path, "old")
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.NoError(consume.CheckHealth(cf))
+	assertFileContents(t, path, "Name,Age\nMark,50\nStoney,49\n")
+	assertDirLen(t, dir, 1)
+	info, err := os.Stat(path)
+	if assert.NoError(err) {
+		assert.Equal(os.FileMode(0644), info.Mode().Perm())
+	}
+}
+
+func TestToFileAtomicSinkError(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.avro")
+	cf := consume.ToFileAtomic(
+		path,
+		func(w io.Writer) consume.ConsumeFinalizer {
+			return consume.ToAvro(w, `"string"`)
+		})
+	x := 3
+	cf.Consume(&x)
+	cf.Finalize()
+	assert.Error(consume.CheckHealth(cf))
+	assertDirLen(t, dir, 0)
+}
+
+func TestToFileAtomicRollback(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "people.csv")
+	cf := consume.ToFileAtomic(path, newCSVSink)
+	consume.FeedSlice(people, cf)
+	tc := cf.(consume.TransactionalConsumer)
+	assert.NoError(tc.Rollback())
+	assert.Equal(consume.ErrTransactionDone, tc.Commit())
+	assertDirLen(t, dir, 0)
+}
+
+func TestToFileAtomicBadDir(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "missing", "people.csv")
+	cf := consume.ToFileAtomic(path, newCSVSink)
+	assert.False(cf.CanConsume())
+	assert.Error(consume.CheckHealth(cf))
+	cf.Finalize()
+	_, err := os.Stat(path)
+	assert.True(os.IsNotExist(err))
+}
+
+func assertFileContents(t *testing.T, path, expected string) {
+	t.Helper()
+	contents, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(contents))
+}
+
+func assertDirLen(t *testing.T, dir string, expected int) {
+	t.Helper()
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, expected)
+}
@@ -0,0 +1,92 @@
+package consume
+
+import (
+	"io"
+	"sync"
+)
+
+// Auditor logs the transformations that stages make to values. Use
+// AuditTo to create one. An Auditor is safe to use from multiple
+// goroutines.
+type Auditor struct {
+	w           io.Writer
+	format      func(stage string, before, after interface{}) string
+	sampleEvery int
+	mu          sync.Mutex
+	count       int
+	failed      bool
+}
+
+// AuditOption is an option for AuditTo.
+type AuditOption func(a *Auditor)
+
+// AuditSample has the Auditor log only every every th transformation.
+// AuditSample panics if every is not positive.
+func AuditSample(every int) AuditOption {
+	if every <= 0 {
+		panic("every must be positive")
+	}
+	return func(a *Auditor) {
+		a.sampleEvery = every
+	}
+}
+
+// AuditTo returns an Auditor that writes a line to w for each value that
+// goes through one of its stages. format takes the name of the stage, a
+// pointer to the value before the stage, and a pointer to the value
+// after the stage and returns the text of the line without the trailing
+// newline. after is nil if the stage filtered the value out. If a write
+// to w fails, the Auditor stops logging.
+func AuditTo(
+	w io.Writer,
+	format func(stage string, before, after interface{}) string,
+	options ...AuditOption) *Auditor {
+	result := &Auditor{w: w, format: format, sampleEvery: 1}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+// Stage returns a MapFilterer that applies funcs and logs each value
+// before and after funcs under stage. funcs can be anything that
+// MapFilter accepts. Pass the returned MapFilterer to MapFilter or
+// NewMapFilterer.
+func (a *Auditor) Stage(stage string, funcs ...interface{}) MapFilterer {
+	return &stageMapFilterer{list: []mapFilterStage{&auditStage{
+		auditor:    a,
+		stage:      stage,
+		mapFilters: NewMapFilterer(funcs...),
+	}}}
+}
+
+func (a *Auditor) log(stage string, before, after interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.count++
+	if a.failed || (a.count-1)%a.sampleEvery != 0 {
+		return
+	}
+	line := a.format(stage, before, after) + "\n"
+	if _, err := io.WriteString(a.w, line); err != nil {
+		a.failed = true
+	}
+}
+
+type auditStage struct {
+	auditor    *Auditor
+	stage      string
+	mapFilters MapFilterer
+}
+
+func (a *auditStage) newScratch() interface{} {
+	return a.mapFilters.NewContext()
+}
+
+func (a *auditStage) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	after := a.mapFilters.MapFilterWithContext(
+		scratch.(*MapFilterContext), ptr)
+	a.auditor.log(a.stage, ptr, after)
+	return after
+}
@@ -0,0 +1,60 @@
+package consume_test
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditTo(t *testing.T) {
+	assert := assert.New(t)
+	var buffer bytes.Buffer
+	auditor := consume.AuditTo(&buffer, formatAudit)
+	var strs []string
+	feedInts(t, consume.MapFilter(
+		consume.Slice(consume.AppendTo(&strs), 0, 2),
+		auditor.Stage("evens", func(ptr *int) bool { return *ptr%2 == 0 }),
+		auditor.Stage("itoa", func(src *int, dest *string) bool {
+			*dest = "#" + strconv.Itoa(*src)
+			return true
+		})))
+	assert.Equal([]string{"#0", "#2"}, strs)
+	assert.Equal(
+		"evens: 0 -> 0\n"+
+			"itoa: 0 -> #0\n"+
+			"evens: 1 -> <nil>\n"+
+			"evens: 2 -> 2\n"+
+			"itoa: 2 -> #2\n",
+		buffer.String())
+}
+
+func TestAuditToSampled(t *testing.T) {
+	assert := assert.New(t)
+	var buffer bytes.Buffer
+	auditor := consume.AuditTo(&buffer, formatAudit, consume.AuditSample(3))
+	var ints []int
+	feedInts(t, consume.MapFilter(
+		consume.Slice(consume.AppendTo(&ints), 0, 7),
+		auditor.Stage("all", func(ptr *int) bool { return true })))
+	assert.Equal("all: 0 -> 0\nall: 3 -> 3\nall: 6 -> 6\n", buffer.String())
+	assert.Panics(func() { consume.AuditSample(0) })
+}
+
+func formatAudit(stage string, before, after interface{}) string {
+	return fmt.Sprintf("%s: %v -> %v", stage, deref(before), deref(after))
+}
+
+func deref(ptr interface{}) interface{} {
+	switch p := ptr.(type) {
+	case *int:
+		return *p
+	case *string:
+		return *p
+	default:
+		return ptr
+	}
+}
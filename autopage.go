@@ -0,0 +1,71 @@
+package consume
+
+// AutoPage works like Page for the first page except that the page ends
+// when the values on it reach a total size rather than a fixed number of
+// items. sizeFunc takes a pointer to a consumed value and returns its
+// size, typically in bytes. The returned consumer puts values on the
+// page as long as their total size stays within targetBytes. Once a value
+// won't fit, AutoPage sets morePages to true and the returned consumer
+// stops consuming. The first value always goes on the page even if it
+// is larger than targetBytes so that each page makes progress. Like
+// Page, the values stored at aValueSlicePointer and morePages are
+// undefined until caller calls Finalize(). AutoPage panics if
+// targetBytes is not positive or if aValueSlicePointer is not a pointer to
+// a slice.
+func AutoPage(
+	targetBytes int,
+	sizeFunc func(ptr interface{}) int,
+	aValueSlicePointer interface{},
+	morePages *bool) ConsumeFinalizer {
+	if targetBytes <= 0 {
+		panic("targetBytes must be positive")
+	}
+	aSliceValue := sliceValueFromP(aValueSlicePointer, false)
+	truncateTo(aSliceValue, 0)
+	return &autoPageConsumer{
+		cf:          AppendToSaveMemory(aValueSlicePointer),
+		targetBytes: targetBytes,
+		sizeFunc:    sizeFunc,
+		morePages:   morePages,
+	}
+}
+
+type autoPageConsumer struct {
+	cf          ConsumeFinalizer
+	targetBytes int
+	sizeFunc    func(ptr interface{}) int
+	morePages   *bool
+	total       int
+	count       int
+	more        bool
+	finalized   bool
+}
+
+func (a *autoPageConsumer) children() []interface{} {
+	return []interface{}{a.cf}
+}
+
+func (a *autoPageConsumer) CanConsume() bool {
+	return !a.finalized && !a.more
+}
+
+func (a *autoPageConsumer) Consume(ptr interface{}) {
+	MustCanConsume(a)
+	size := a.sizeFunc(ptr)
+	if a.count > 0 && a.total+size > a.targetBytes {
+		a.more = true
+		return
+	}
+	a.cf.Consume(ptr)
+	a.total += size
+	a.count++
+}
+
+func (a *autoPageConsumer) Finalize() {
+	if a.finalized {
+		return
+	}
+	a.finalized = true
+	a.cf.Finalize()
+	*a.morePages = a.more
+}
@@ -0,0 +1,45 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoPage(t *testing.T) {
+	assert := assert.New(t)
+	words := []string{"abc", "de", "fghij", "k", "lm"}
+	var page []string
+	var morePages bool
+	cf := consume.AutoPage(10, stringLen, &page, &morePages)
+	consume.FeedSlice(words, cf)
+	assert.False(cf.CanConsume())
+	cf.Finalize()
+	assert.Equal([]string{"abc", "de", "fghij"}, page)
+	assert.True(morePages)
+
+	cf = consume.AutoPage(10, stringLen, &page, &morePages)
+	consume.FeedSlice(words[3:], cf)
+	cf.Finalize()
+	assert.Equal([]string{"k", "lm"}, page)
+	assert.False(morePages)
+}
+
+func TestAutoPageLargeValue(t *testing.T) {
+	assert := assert.New(t)
+	var page []string
+	var morePages bool
+	cf := consume.AutoPage(3, stringLen, &page, &morePages)
+	consume.FeedSlice([]string{"abcdef", "g"}, cf)
+	cf.Finalize()
+	assert.Equal([]string{"abcdef"}, page)
+	assert.True(morePages)
+	assert.Panics(func() {
+		consume.AutoPage(0, stringLen, &page, &morePages)
+	})
+}
+
+func stringLen(ptr interface{}) int {
+	return len(*ptr.(*string))
+}
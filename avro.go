@@ -0,0 +1,589 @@
+package consume
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+)
+
+const (
+	kAvroBlockSize = 64 * 1024
+	kAvroSyncSize  = 16
+)
+
+var (
+	kAvroMagic = []byte{'O', 'b', 'j', 1}
+)
+
+// ToAvro returns a ConsumeFinalizer that writes consumed values to w as an
+// Avro object container file. schemaJSON is the Avro schema of each value
+// in JSON. ToAvro supports the primitive types along with records, enums,
+// arrays, maps, unions, and fixed. Once defined, records, enums, and
+// fixed can be referred to by name or full name including from within
+// themselves. Values of type int must fit in 32 bits. A fixed takes a
+// byte array or byte slice of exactly its size. The fields of a record
+// come from the struct fields of the same name as described in
+// SchemaField. A missing struct
+// field or nil pointer encodes as null in a union that includes null.
+// The returned consumer writes the header to w right away and then
+// buffers encoded values, writing them as a block whenever the buffer
+// gets large and on Finalize. Blocks are not compressed. Caller must call
+// Finalize() on the returned consumer when done so that the last block
+// gets written to w. If a value does not match the schema or a write to
+// w fails, CanConsume() returns false from then on, and the Healthy
+// method of the returned consumer reports the error. ToAvro panics if
+// schemaJSON is not a valid Avro schema.
+func ToAvro(w io.Writer, schemaJSON string) ConsumeFinalizer {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &parsed); err != nil {
+		panic(err)
+	}
+	schema, err := newAvroParser().parse(parsed)
+	if err != nil {
+		panic(err)
+	}
+	result := &avroConsumer{w: w, schema: schema}
+	if _, err := rand.Read(result.sync[:]); err != nil {
+		panic(err)
+	}
+	result.writeHeader(schemaJSON)
+	return result
+}
+
+type avroConsumer struct {
+	w         io.Writer
+	schema    avroSchema
+	sync      [kAvroSyncSize]byte
+	block     bytes.Buffer
+	count     int64
+	err       error
+	finalized bool
+}
+
+func (a *avroConsumer) CanConsume() bool {
+	return a.err == nil && !a.finalized
+}
+
+func (a *avroConsumer) Consume(ptr interface{}) {
+	MustCanConsume(a)
+	mark := a.block.Len()
+	a.err = a.schema.encode(&a.block, reflect.ValueOf(ptr))
+	if a.err != nil {
+		a.block.Truncate(mark)
+		return
+	}
+	a.count++
+	if a.block.Len() >= kAvroBlockSize {
+		a.flush()
+	}
+}
+
+func (a *avroConsumer) Finalize() {
+	if a.finalized {
+		return
+	}
+	a.finalized = true
+	a.flush()
+}
+
+func (a *avroConsumer) writeHeader(schemaJSON string) {
+	var header bytes.Buffer
+	header.Write(kAvroMagic)
+	writeAvroLong(&header, 2)
+	writeAvroString(&header, "avro.codec")
+	writeAvroString(&header, "null")
+	writeAvroString(&header, "avro.schema")
+	writeAvroString(&header, schemaJSON)
+	writeAvroLong(&header, 0)
+	header.Write(a.sync[:])
+	a.write(header.Bytes())
+}
+
+func (a *avroConsumer) flush() {
+	if a.count == 0 {
+		return
+	}
+	var prefix bytes.Buffer
+	writeAvroLong(&prefix, a.count)
+	writeAvroLong(&prefix, int64(a.block.Len()))
+	a.write(prefix.Bytes())
+	a.write(a.block.Bytes())
+	a.write(a.sync[:])
+	a.block.Reset()
+	a.count = 0
+}
+
+func (a *avroConsumer) write(p []byte) {
+	if a.err != nil {
+		return
+	}
+	_, a.err = a.w.Write(p)
+}
+
+func (a *avroConsumer) Healthy() error {
+	return a.err
+}
+
+// avroSchema encodes values of one Avro type. value may be invalid if
+// the value is missing.
+type avroSchema interface {
+	encode(buf *bytes.Buffer, value reflect.Value) error
+}
+
+// avroParser parses Avro schemas keeping track of the named types.
+type avroParser struct {
+	named map[string]avroSchema
+}
+
+func newAvroParser() *avroParser {
+	return &avroParser{named: make(map[string]avroSchema)}
+}
+
+func (p *avroParser) parse(parsed interface{}) (avroSchema, error) {
+	switch t := parsed.(type) {
+	case string:
+		return p.parseName(t)
+	case []interface{}:
+		return p.parseUnion(t)
+	case map[string]interface{}:
+		return p.parseComplex(t)
+	}
+	return nil, fmt.Errorf("consume: invalid avro schema: %v", parsed)
+}
+
+func (p *avroParser) parseName(name string) (avroSchema, error) {
+	switch name {
+	case "null":
+		return avroNull{}, nil
+	case "boolean":
+		return avroBoolean{}, nil
+	case "int":
+		return avroInt{}, nil
+	case "long":
+		return avroLong{}, nil
+	case "float":
+		return avroFloat{}, nil
+	case "double":
+		return avroDouble{}, nil
+	case "bytes":
+		return avroBytes{}, nil
+	case "string":
+		return avroString{}, nil
+	}
+	if schema, ok := p.named[name]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("consume: unsupported avro type: %s", name)
+}
+
+func (p *avroParser) parseComplex(
+	parsed map[string]interface{}) (avroSchema, error) {
+	switch parsed["type"] {
+	case "record":
+		return p.parseRecord(parsed)
+	case "enum":
+		return p.parseEnum(parsed)
+	case "fixed":
+		return p.parseFixed(parsed)
+	case "array":
+		items, err := p.parse(parsed["items"])
+		if err != nil {
+			return nil, err
+		}
+		return avroArray{items: items}, nil
+	case "map":
+		values, err := p.parse(parsed["values"])
+		if err != nil {
+			return nil, err
+		}
+		return avroMap{values: values}, nil
+	}
+	return p.parse(parsed["type"])
+}
+
+// define makes schema available under the name and full name of the
+// named type that parsed defines if parsed has a name.
+func (p *avroParser) define(
+	parsed map[string]interface{}, schema avroSchema) {
+	name, ok := parsed["name"].(string)
+	if !ok || name == "" {
+		return
+	}
+	fullName := name
+	if namespace, ok := parsed["namespace"].(string); ok &&
+		namespace != "" && !strings.Contains(name, ".") {
+		fullName = namespace + "." + name
+	}
+	p.named[fullName] = schema
+	p.named[fullName[strings.LastIndex(fullName, ".")+1:]] = schema
+}
+
+func (p *avroParser) parseRecord(
+	parsed map[string]interface{}) (avroSchema, error) {
+	fields, ok := parsed["fields"].([]interface{})
+	if !ok {
+		return nil, errors.New("consume: avro record needs fields")
+	}
+	result := &avroRecord{}
+
+	// Define the record before its fields so that fields can refer to it.
+	p.define(parsed, result)
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("consume: invalid avro field: %v", f)
+		}
+		name, ok := field["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("consume: avro field needs name: %v", f)
+		}
+		schema, err := p.parse(field["type"])
+		if err != nil {
+			return nil, err
+		}
+		result.fields = append(
+			result.fields, avroField{name: name, schema: schema})
+	}
+	return result, nil
+}
+
+func (p *avroParser) parseEnum(
+	parsed map[string]interface{}) (avroSchema, error) {
+	symbols, ok := parsed["symbols"].([]interface{})
+	if !ok {
+		return nil, errors.New("consume: avro enum needs symbols")
+	}
+	result := avroEnum{symbols: make(map[string]int64)}
+	for i, s := range symbols {
+		symbol, ok := s.(string)
+		if !ok {
+			return nil, fmt.Errorf("consume: invalid avro symbol: %v", s)
+		}
+		result.symbols[symbol] = int64(i)
+	}
+	p.define(parsed, result)
+	return result, nil
+}
+
+func (p *avroParser) parseFixed(
+	parsed map[string]interface{}) (avroSchema, error) {
+	size, ok := parsed["size"].(float64)
+	if !ok || size < 0 || size != math.Trunc(size) {
+		return nil, errors.New("consume: avro fixed needs size")
+	}
+	result := avroFixed{size: int(size)}
+	p.define(parsed, result)
+	return result, nil
+}
+
+func (p *avroParser) parseUnion(
+	branches []interface{}) (avroSchema, error) {
+	result := avroUnion{nullIndex: -1}
+	for i, b := range branches {
+		schema, err := p.parse(b)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := schema.(avroNull); ok {
+			result.nullIndex = int64(i)
+		}
+		result.branches = append(result.branches, schema)
+	}
+	return result, nil
+}
+
+type avroNull struct{}
+
+func (avroNull) encode(buf *bytes.Buffer, value reflect.Value) error {
+	return nil
+}
+
+type avroBoolean struct{}
+
+func (avroBoolean) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.Bool {
+		return avroMismatch("boolean", value)
+	}
+	if value.Bool() {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	return nil
+}
+
+type avroInt struct{}
+
+func (avroInt) encode(buf *bytes.Buffer, value reflect.Value) error {
+	x, err := avroIntegerValue("int", value)
+	if err != nil {
+		return err
+	}
+	if x < math.MinInt32 || x > math.MaxInt32 {
+		return fmt.Errorf("consume: %d overflows avro int", x)
+	}
+	writeAvroLong(buf, x)
+	return nil
+}
+
+type avroLong struct{}
+
+func (avroLong) encode(buf *bytes.Buffer, value reflect.Value) error {
+	x, err := avroIntegerValue("long", value)
+	if err != nil {
+		return err
+	}
+	writeAvroLong(buf, x)
+	return nil
+}
+
+// avroIntegerValue returns value as an int64 for encoding as avroType.
+func avroIntegerValue(avroType string, value reflect.Value) (int64, error) {
+	value = indirectValue(value)
+	if !value.IsValid() {
+		return 0, avroMismatch(avroType, value)
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf(
+				"consume: %d overflows avro %s", value.Uint(), avroType)
+		}
+		return int64(value.Uint()), nil
+	}
+	return 0, avroMismatch(avroType, value)
+}
+
+type avroFloat struct{}
+
+func (avroFloat) encode(buf *bytes.Buffer, value reflect.Value) error {
+	f, ok := floatValue(value)
+	if !ok {
+		return avroMismatch("float", value)
+	}
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+	buf.Write(b[:])
+	return nil
+}
+
+type avroDouble struct{}
+
+func (avroDouble) encode(buf *bytes.Buffer, value reflect.Value) error {
+	f, ok := floatValue(value)
+	if !ok {
+		return avroMismatch("double", value)
+	}
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	buf.Write(b[:])
+	return nil
+}
+
+type avroBytes struct{}
+
+func (avroBytes) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.Slice ||
+		value.Type().Elem().Kind() != reflect.Uint8 {
+		return avroMismatch("bytes", value)
+	}
+	writeAvroLong(buf, int64(value.Len()))
+	buf.Write(value.Bytes())
+	return nil
+}
+
+type avroFixed struct {
+	size int
+}
+
+func (a avroFixed) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() ||
+		(value.Kind() != reflect.Slice && value.Kind() != reflect.Array) ||
+		value.Type().Elem().Kind() != reflect.Uint8 {
+		return avroMismatch("fixed", value)
+	}
+	if value.Len() != a.size {
+		return fmt.Errorf(
+			"consume: %d bytes don't fit avro fixed of size %d",
+			value.Len(),
+			a.size)
+	}
+	for i := 0; i < a.size; i++ {
+		buf.WriteByte(byte(value.Index(i).Uint()))
+	}
+	return nil
+}
+
+type avroString struct{}
+
+func (avroString) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return avroMismatch("string", value)
+	}
+	writeAvroString(buf, value.String())
+	return nil
+}
+
+type avroField struct {
+	name   string
+	schema avroSchema
+}
+
+type avroRecord struct {
+	fields   []avroField
+	lastType reflect.Type
+	indexes  []int
+}
+
+func (a *avroRecord) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.Struct {
+		return avroMismatch("record", value)
+	}
+	if value.Type() != a.lastType {
+		a.lastType = value.Type()
+		a.indexes = make([]int, len(a.fields))
+		for i := range a.fields {
+			a.indexes[i] = schemaFieldIndex(a.lastType, a.fields[i].name)
+		}
+	}
+
+	// A recursive record may change a.indexes while encoding its fields.
+	indexes := a.indexes
+	for i := range a.fields {
+		var field reflect.Value
+		if indexes[i] >= 0 {
+			field = value.Field(indexes[i])
+		}
+		if err := a.fields[i].schema.encode(buf, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type avroEnum struct {
+	symbols map[string]int64
+}
+
+func (a avroEnum) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.String {
+		return avroMismatch("enum", value)
+	}
+	index, ok := a.symbols[value.String()]
+	if !ok {
+		return fmt.Errorf(
+			"consume: %q not an avro enum symbol", value.String())
+	}
+	writeAvroLong(buf, index)
+	return nil
+}
+
+type avroArray struct {
+	items avroSchema
+}
+
+func (a avroArray) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() ||
+		(value.Kind() != reflect.Slice && value.Kind() != reflect.Array) {
+		return avroMismatch("array", value)
+	}
+	if value.Len() > 0 {
+		writeAvroLong(buf, int64(value.Len()))
+		for i := 0; i < value.Len(); i++ {
+			if err := a.items.encode(buf, value.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	writeAvroLong(buf, 0)
+	return nil
+}
+
+type avroMap struct {
+	values avroSchema
+}
+
+func (a avroMap) encode(buf *bytes.Buffer, value reflect.Value) error {
+	value = indirectValue(value)
+	if !value.IsValid() || value.Kind() != reflect.Map ||
+		value.Type().Key().Kind() != reflect.String {
+		return avroMismatch("map", value)
+	}
+	if value.Len() > 0 {
+		writeAvroLong(buf, int64(value.Len()))
+		iter := value.MapRange()
+		for iter.Next() {
+			writeAvroString(buf, iter.Key().String())
+			if err := a.values.encode(buf, iter.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	writeAvroLong(buf, 0)
+	return nil
+}
+
+// avroUnion encodes a missing or nil value as its null branch. It
+// encodes any other value as the first non null branch that accepts it.
+type avroUnion struct {
+	branches  []avroSchema
+	nullIndex int64
+}
+
+func (a avroUnion) encode(buf *bytes.Buffer, value reflect.Value) error {
+	if !indirectValue(value).IsValid() {
+		if a.nullIndex < 0 {
+			return avroMismatch("union", value)
+		}
+		writeAvroLong(buf, a.nullIndex)
+		return nil
+	}
+	mark := buf.Len()
+	for i, branch := range a.branches {
+		if int64(i) == a.nullIndex {
+			continue
+		}
+		writeAvroLong(buf, int64(i))
+		if branch.encode(buf, value) == nil {
+			return nil
+		}
+		buf.Truncate(mark)
+	}
+	return avroMismatch("union", value)
+}
+
+func avroMismatch(avroType string, value reflect.Value) error {
+	if !value.IsValid() {
+		return fmt.Errorf("consume: missing value for avro %s", avroType)
+	}
+	return fmt.Errorf(
+		"consume: cannot encode %v as avro %s", value.Type(), avroType)
+}
+
+func writeAvroLong(buf *bytes.Buffer, x int64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutVarint(b[:], x)])
+}
+
+func writeAvroString(buf *bytes.Buffer, s string) {
+	writeAvroLong(buf, int64(len(s)))
+	buf.WriteString(s)
+}
@@ -0,0 +1,187 @@
+package consume_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+const personAvroSchema = `{
+  "type": "record",
+  "name": "Person",
+  "fields": [
+    {"name": "Name", "type": "string"},
+    {"name": "Age", "type": "int"},
+    {"name": "Email", "type": ["null", "string"]}
+  ]
+}`
+
+func TestToAvro(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	cf := consume.ToAvro(&buf, personAvroSchema)
+	header := avroHeader(personAvroSchema)
+	assert.Equal(header, buf.Bytes()[:len(header)])
+	sync := buf.Bytes()[len(header) : len(header)+16]
+	consume.FeedSlice(people[:2], cf)
+	assert.Len(buf.Bytes(), len(header)+16)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+
+	var record []byte
+	record = appendAvroString(record, "Mark")
+	record = appendAvroLong(record, 50)
+	record = appendAvroLong(record, 0)
+	record = appendAvroString(record, "Stoney")
+	record = appendAvroLong(record, 49)
+	record = appendAvroLong(record, 0)
+	var block []byte
+	block = appendAvroLong(block, 2)
+	block = appendAvroLong(block, int64(len(record)))
+	block = append(block, record...)
+	block = append(block, sync...)
+	assert.Equal(block, buf.Bytes()[len(header)+16:])
+}
+
+func TestToAvroUnionAndEnum(t *testing.T) {
+	assert := assert.New(t)
+	type event struct {
+		Kind  string
+		Email *string
+		Tags  []string
+	}
+	email := "a@b.com"
+	var buf bytes.Buffer
+	cf := consume.ToAvro(&buf, `{
+  "type": "record",
+  "name": "Event",
+  "fields": [
+    {"name": "Kind", "type": {"type": "enum", "name": "K",
+      "symbols": ["open", "close"]}},
+    {"name": "Email", "type": ["null", "string"]},
+    {"name": "Tags", "type": {"type": "array", "items": "string"}}
+  ]
+}`)
+	start := buf.Len()
+	consume.FeedSlice(
+		[]event{{Kind: "close", Email: &email, Tags: []string{"x"}}}, cf)
+	cf.Finalize()
+	var record []byte
+	record = appendAvroLong(record, 1)
+	record = appendAvroLong(record, 1)
+	record = appendAvroString(record, email)
+	record = appendAvroLong(record, 1)
+	record = appendAvroString(record, "x")
+	record = appendAvroLong(record, 0)
+	out := buf.Bytes()[start:]
+	assert.Equal(record, out[2:2+len(record)])
+}
+
+func TestToAvroMismatch(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	cf := consume.ToAvro(&buf, `"string"`)
+	x := 3
+	cf.Consume(&x)
+	assert.False(cf.CanConsume())
+	cf.Finalize()
+	assert.Equal(avroHeader(`"string"`), buf.Bytes()[:buf.Len()-16])
+}
+
+func TestToAvroWriteError(t *testing.T) {
+	assert := assert.New(t)
+	cf := consume.ToAvro(errorWriter{}, `"string"`)
+	assert.False(cf.CanConsume())
+}
+
+func TestToAvroBadSchema(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	assert.Panics(func() { consume.ToAvro(&buf, `{`) })
+	assert.Panics(func() { consume.ToAvro(&buf, `"fixed32"`) })
+	assert.Panics(func() {
+		consume.ToAvro(&buf, `{"type": "record", "name": "X"}`)
+	})
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func avroHeader(schemaJSON string) []byte {
+	result := []byte{'O', 'b', 'j', 1}
+	result = appendAvroLong(result, 2)
+	result = appendAvroString(result, "avro.codec")
+	result = appendAvroString(result, "null")
+	result = appendAvroString(result, "avro.schema")
+	result = appendAvroString(result, schemaJSON)
+	return appendAvroLong(result, 0)
+}
+
+func appendAvroLong(b []byte, x int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], x)]...)
+}
+
+func appendAvroString(b []byte, s string) []byte {
+	return append(appendAvroLong(b, int64(len(s))), s...)
+}
+
+func TestToAvroIntRange(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	cf := consume.ToAvro(&buf, `"int"`)
+	x := int64(1) << 31
+	cf.Consume(&x)
+	assert.Error(consume.CheckHealth(cf))
+	cf = consume.ToAvro(&buf, `"long"`)
+	cf.Consume(&x)
+	assert.NoError(consume.CheckHealth(cf))
+}
+
+func TestToAvroNamedAndFixed(t *testing.T) {
+	assert := assert.New(t)
+	type node struct {
+		ID   [2]byte
+		Next *node
+	}
+	schema := `{
+  "type": "record",
+  "name": "Node",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "ID", "type": {"type": "fixed", "name": "ID", "size": 2}},
+    {"name": "Next", "type": ["null", "com.example.Node"]}
+  ]
+}`
+	var buf bytes.Buffer
+	cf := consume.ToAvro(&buf, schema)
+	header := avroHeader(schema)
+	sync := buf.Bytes()[len(header) : len(header)+16]
+	value := node{ID: [2]byte{1, 2}, Next: &node{ID: [2]byte{3, 4}}}
+	cf.Consume(&value)
+	cf.Finalize()
+	assert.NoError(consume.CheckHealth(cf))
+
+	record := []byte{1, 2}
+	record = appendAvroLong(record, 1)
+	record = append(record, 3, 4)
+	record = appendAvroLong(record, 0)
+	var block []byte
+	block = appendAvroLong(block, 1)
+	block = appendAvroLong(block, int64(len(record)))
+	block = append(block, record...)
+	block = append(block, sync...)
+	assert.Equal(block, buf.Bytes()[len(header)+16:])
+
+	cf = consume.ToAvro(&buf, `{"type": "fixed", "name": "F", "size": 3}`)
+	cf.Consume(&[]byte{1, 2})
+	assert.Error(consume.CheckHealth(cf))
+}
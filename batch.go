@@ -0,0 +1,62 @@
+package consume
+
+// BatchHinter is implemented by consumers that know how many values they
+// would like to consume next. Feeders can use the hint to size the
+// batches they fetch.
+type BatchHinter interface {
+
+	// PreferredBatchSize returns how many values this instance would like
+	// to consume next or 0 if it has no preference.
+	PreferredBatchSize() int
+}
+
+// PreferredBatchSize returns the preferred batch size of c if c
+// implements BatchHinter or 0 otherwise. 0 means no preference.
+func PreferredBatchSize(c Consumer) int {
+	if hinter, ok := c.(BatchHinter); ok {
+		return hinter.PreferredBatchSize()
+	}
+	return 0
+}
+
+// PreferredBatchSize returns the number of values s needs to consume to
+// reach its end or less if the underlying consumer prefers less.
+func (s *sliceConsumer) PreferredBatchSize() int {
+	if !s.CanConsume() {
+		return 0
+	}
+	result := s.end - s.idx
+	if inner := PreferredBatchSize(s.consumer); inner > 0 {
+		if s.idx < s.start {
+			inner += s.start - s.idx
+		}
+		if inner < result {
+			return inner
+		}
+	}
+	return result
+}
+
+func (p *pageConsumer) PreferredBatchSize() int {
+	return PreferredBatchSize(p.Consumer)
+}
+
+func (m *mapFilterConsumer) PreferredBatchSize() int {
+	return PreferredBatchSize(m.Consumer)
+}
+
+func (t *takeWhileConsumer) PreferredBatchSize() int {
+	return PreferredBatchSize(t.consumer)
+}
+
+// PreferredBatchSize returns the largest preferred batch size of the
+// consumers m contains.
+func (m *multiConsumer) PreferredBatchSize() int {
+	result := 0
+	for _, consumer := range m.consumers {
+		if size := PreferredBatchSize(consumer); size > result {
+			result = size
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferredBatchSize(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	assert.Equal(0, consume.PreferredBatchSize(consume.AppendTo(&ints)))
+	slice := consume.Slice(consume.AppendTo(&ints), 5, 15)
+	assert.Equal(15, consume.PreferredBatchSize(slice))
+	for i := 0; i < 7; i++ {
+		slice.Consume(&i)
+	}
+	assert.Equal(8, consume.PreferredBatchSize(slice))
+	assert.Equal(
+		8,
+		consume.PreferredBatchSize(consume.Compose(
+			slice, consume.Slice(consume.AppendTo(&ints), 0, 3))))
+	assert.Equal(
+		8,
+		consume.PreferredBatchSize(consume.MapFilter(
+			slice, func(ptr *int) bool { return true })))
+	feedInts(t, slice)
+	assert.Equal(0, consume.PreferredBatchSize(slice))
+}
+
+func TestPreferredBatchSizePage(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var morePages bool
+	pager := consume.Page(2, 10, &ints, &morePages)
+	assert.Equal(31, consume.PreferredBatchSize(pager))
+	pager.Finalize()
+	assert.Equal(0, consume.PreferredBatchSize(pager))
+}
@@ -0,0 +1,124 @@
+package consume
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Bridge has c consume the values from p running p on a separate
+// goroutine. Instead of sending each value across the goroutine boundary
+// on its own, Bridge copies up to chunkSize values into a batch and
+// sends whole batches. Bridge reuses batches once c has consumed them,
+// so c must not hold onto the pointers it consumes. The copies are
+// shallow. Since p runs ahead of c by up to a few batches, p may produce
+// values that c never consumes. Bridge stops when p runs out of values
+// or c can no longer consume. Then it finalizes c the same way Run does.
+// If p panics, Bridge returns an error describing the panic. Bridge
+// panics if chunkSize is not positive.
+func Bridge(p Producer, c Consumer, chunkSize int) error {
+	if chunkSize <= 0 {
+		panic("chunkSize must be positive")
+	}
+	b := &bridge{
+		chunkSize: chunkSize,
+		full:      make(chan reflect.Value, 1),
+		free:      make(chan reflect.Value, 2),
+		stop:      make(chan struct{}),
+	}
+	go b.produce(p)
+	b.consume(c)
+	finalizeAll(c)
+	return b.err
+}
+
+type bridge struct {
+	chunkSize int
+
+	// full carries batches of values from the producer. It is closed
+	// when the producer is done.
+	full chan reflect.Value
+
+	// free carries batches back to the producer for reuse.
+	free     chan reflect.Value
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// err is written by the producer before it closes full.
+	err error
+}
+
+func (b *bridge) produce(p Producer) {
+	defer close(b.full)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			b.err = fmt.Errorf("consume: producer panicked: %v", recovered)
+		}
+	}()
+	var batch reflect.Value
+	n := 0
+	for ptr := p.Produce(); ptr != nil; ptr = p.Produce() {
+		value := reflect.ValueOf(ptr).Elem()
+		if !batch.IsValid() {
+			batch = b.newBatch(value.Type())
+		}
+		batch.Index(n).Set(value)
+		n++
+		if n == b.chunkSize {
+			if !b.send(batch) {
+				return
+			}
+			batch = reflect.Value{}
+			n = 0
+		}
+	}
+	if n > 0 {
+		b.send(batch.Slice(0, n))
+	}
+}
+
+// newBatch returns a batch from the free list or a new one if the free
+// list is empty.
+func (b *bridge) newBatch(valueType reflect.Type) reflect.Value {
+	select {
+	case batch := <-b.free:
+		return batch
+	default:
+		return reflect.MakeSlice(
+			reflect.SliceOf(valueType), b.chunkSize, b.chunkSize)
+	}
+}
+
+// send sends batch to the consumer returning false if the consumer has
+// stopped.
+func (b *bridge) send(batch reflect.Value) bool {
+	select {
+	case b.full <- batch:
+		return true
+	case <-b.stop:
+		return false
+	}
+}
+
+func (b *bridge) consume(c Consumer) {
+
+	// Let the producer finish even if c panics.
+	defer b.stopOnce.Do(func() { close(b.stop) })
+	for batch := range b.full {
+		for i := 0; i < batch.Len() && c.CanConsume(); i++ {
+			c.Consume(batch.Index(i).Addr().Interface())
+		}
+		if !c.CanConsume() {
+			break
+		}
+		select {
+		case b.free <- batch.Slice(0, batch.Cap()):
+		default:
+		}
+	}
+	b.stopOnce.Do(func() { close(b.stop) })
+
+	// Wait for the producer to finish so that b.err is safe to read.
+	for range b.full {
+	}
+}
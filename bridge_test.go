@@ -0,0 +1,76 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBridge(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	assert.NoError(
+		consume.Bridge(countingProducer(10), consume.AppendTo(&result), 3))
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+}
+
+func TestBridgeEmpty(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	assert.NoError(
+		consume.Bridge(countingProducer(0), consume.AppendTo(&result), 3))
+	assert.Empty(result)
+}
+
+func TestBridgeConsumerStops(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	finalized := false
+	consumer := &finalizer{
+		Consumer: consume.Slice(consume.AppendTo(&result), 0, 5),
+		finalize: func() { finalized = true },
+	}
+	assert.NoError(consume.Bridge(countingProducer(1000), consumer, 4))
+	assert.Equal([]int{0, 1, 2, 3, 4}, result)
+	assert.True(finalized)
+}
+
+func TestBridgeProducerPanics(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	next := 0
+	producer := consume.ProducerFunc(func() interface{} {
+		if next == 5 {
+			panic("oops")
+		}
+		value := next
+		next++
+		return &value
+	})
+	err := consume.Bridge(producer, consume.AppendTo(&result), 2)
+	assert.Error(err)
+	assert.Equal([]int{0, 1, 2, 3}, result)
+}
+
+func TestBridgePanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.Bridge(countingProducer(3), consume.Nil(), 0)
+	})
+}
+
+func TestBridgeFinalizesNested(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	finalized := false
+	consumer := consume.MapFilter(
+		&finalizer{
+			Consumer: consume.AppendTo(&result),
+			finalize: func() { finalized = true },
+		},
+		func(ptr *int) bool { return (*ptr)%2 == 0 })
+	assert.NoError(consume.Bridge(countingProducer(5), consumer, 2))
+	assert.Equal([]int{0, 2, 4}, result)
+	assert.True(finalized)
+}
@@ -0,0 +1,123 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// BulkConsumer is implemented by consumers that can consume a whole slice
+// of values at once more efficiently than one value at a time.
+type BulkConsumer interface {
+	Consumer
+
+	// ConsumeSlice consumes each value in the slice that slicePtr points
+	// to in order as if by calling Consume on a pointer to each value. It
+	// stops early if CanConsume() returns false. ConsumeSlice panics if
+	// CanConsume() returns false before it starts.
+	ConsumeSlice(slicePtr interface{})
+}
+
+// consumeSlice has c consume the values in the slice slicePtr points to
+// stopping early if c can no longer consume. It uses the ConsumeSlice
+// method of c if c is a BulkConsumer.
+func consumeSlice(c Consumer, slicePtr interface{}) {
+	if !c.CanConsume() {
+		return
+	}
+	if bulk, ok := c.(BulkConsumer); ok {
+		bulk.ConsumeSlice(slicePtr)
+		return
+	}
+	consumeSliceValue(c, sliceValueFromP(slicePtr, false))
+}
+
+func consumeSliceValue(c Consumer, aSliceValue reflect.Value) {
+	length := aSliceValue.Len()
+	for i := 0; i < length && c.CanConsume(); i++ {
+		c.Consume(aSliceValue.Index(i).Addr().Interface())
+	}
+}
+
+func (a *appendConsumer) ConsumeSlice(slicePtr interface{}) {
+	values := sliceValueFromP(slicePtr, false)
+	if a.allocType == nil && values.Type() == a.buffer.Type() {
+		a.buffer.Set(reflect.AppendSlice(a.buffer, values))
+		return
+	}
+	consumeSliceValue(a, values)
+}
+
+func (a *appendSaveMemoryConsumer) ConsumeSlice(slicePtr interface{}) {
+	MustCanConsume(a)
+	values := sliceValueFromP(slicePtr, false)
+	if values.Type() != a.buffer.Type() {
+		consumeSliceValue(a, values)
+		return
+	}
+	newLength := a.length + values.Len()
+	if newLength > a.buffer.Len() {
+		newCapacity := 2 * a.buffer.Len()
+		if newCapacity < newLength {
+			newCapacity = newLength
+		}
+		truncateTo(a.buffer, newCapacity)
+	}
+	reflect.Copy(a.buffer.Slice(a.length, newLength), values)
+	a.length = newLength
+}
+
+func (m *multiConsumer) ConsumeSlice(slicePtr interface{}) {
+	MustCanConsume(m)
+	for _, consumer := range m.consumers {
+		consumeSlice(consumer, slicePtr)
+	}
+}
+
+func (m *mapFilterConsumer) ConsumeSlice(slicePtr interface{}) {
+	MustCanConsume(m)
+	values := sliceValueFromP(slicePtr, false)
+	if m.bulk == nil {
+		filters, ok := bulkFilters(m.mapFilters)
+		m.bulk = &bulkFilterScratch{filters: filters}
+		m.useBulk = ok
+	}
+	if m.useBulk {
+		consumeSlice(m.Consumer, m.bulk.filter(values))
+		return
+	}
+	length := values.Len()
+	for i := 0; i < length && m.Consumer.CanConsume(); i++ {
+		ptr := m.mapFilters.MapFilterWithContext(
+			m.ctx, values.Index(i).Addr().Interface())
+		if ptr != nil {
+			m.Consumer.Consume(ptr)
+		}
+	}
+}
+
+func (s *sliceConsumer) ConsumeSlice(slicePtr interface{}) {
+	MustCanConsume(s)
+	values := sliceValueFromP(slicePtr, false)
+	length := values.Len()
+
+	// Only the values in [lo, hi) go to the underlying consumer. s stops
+	// consuming after the value at hi-1.
+	lo := clamp(s.start-s.idx, 0, length)
+	hi := clamp(s.end-s.idx, lo, length)
+	s.idx += hi
+	if lo == hi {
+		return
+	}
+	subSlicePtr := reflect.New(values.Type())
+	subSlicePtr.Elem().Set(values.Slice(lo, hi))
+	consumeSlice(s.consumer, subSlicePtr.Interface())
+}
+
+func clamp(x, lo, hi int) int {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
@@ -0,0 +1,102 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkAppendTo(t *testing.T) {
+	assert := assert.New(t)
+	ints := []int{4}
+	consumer := consume.AppendTo(&ints).(consume.BulkConsumer)
+	consumer.ConsumeSlice(&[]int{5, 6, 7})
+	assert.Equal([]int{4, 5, 6, 7}, ints)
+}
+
+func TestBulkAppendPtrsTo(t *testing.T) {
+	assert := assert.New(t)
+	var ptrs []*int
+	consumer := consume.AppendPtrsTo(&ptrs).(consume.BulkConsumer)
+	values := []int{5, 6}
+	consumer.ConsumeSlice(&values)
+	values[0] = 100
+	assert.Len(ptrs, 2)
+	assert.Equal(5, *ptrs[0])
+	assert.Equal(6, *ptrs[1])
+}
+
+func TestBulkAppendToSaveMemory(t *testing.T) {
+	assert := assert.New(t)
+	ints := []int{1, 2}
+	cf := consume.AppendToSaveMemory(&ints)
+	consumer := cf.(consume.BulkConsumer)
+	consumer.ConsumeSlice(&[]int{3, 4, 5})
+	consumer.ConsumeSlice(&[]int{})
+	consumer.ConsumeSlice(&[]int{6, 7, 8, 9, 10, 11})
+	cf.Finalize()
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, ints)
+	assert.Panics(func() { consumer.ConsumeSlice(&[]int{12}) })
+}
+
+func TestBulkComposeSliceMapFilter(t *testing.T) {
+	assert := assert.New(t)
+	var evens []string
+	var threeToSeven, all []int
+	consumer := consume.Compose(
+		consume.MapFilter(
+			consume.Slice(consume.AppendTo(&evens), 0, 3),
+			func(ptr *int) bool { return (*ptr)%2 == 0 },
+			func(src *int, dest *string) bool {
+				*dest = strconv.Itoa(*src)
+				return true
+			}),
+		consume.Slice(consume.AppendTo(&threeToSeven), 3, 7),
+		consume.ConsumerFunc(func(ptr interface{}) {
+			all = append(all, *ptr.(*int))
+		}),
+	).(consume.BulkConsumer)
+	values := []int{0, 1, 2, 3, 4, 5}
+	consumer.ConsumeSlice(&values)
+	assert.Equal([]string{"0", "2", "4"}, evens)
+	assert.Equal([]int{3, 4, 5}, threeToSeven)
+	values = []int{6, 7, 8}
+	consumer.ConsumeSlice(&values)
+	assert.Equal([]string{"0", "2", "4"}, evens)
+	assert.Equal([]int{3, 4, 5, 6}, threeToSeven)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8}, all)
+}
+
+func TestBulkSlice(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.Slice(
+		consume.AppendTo(&ints), 2, 5).(consume.BulkConsumer)
+	consumer.ConsumeSlice(&[]int{0})
+	assert.Equal(4, consume.PreferredBatchSize(consumer))
+	consumer.ConsumeSlice(&[]int{1, 2, 3})
+	consumer.ConsumeSlice(&[]int{4, 5, 6})
+	assert.Equal([]int{2, 3, 4}, ints)
+	assert.False(consumer.CanConsume())
+}
+
+func BenchmarkBulkPagerSimple(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var result []person
+		var morePages bool
+		pager := consume.Page(17, 100, &result, &morePages)
+		consumer := consume.MapFilter(
+			pager,
+			func(p *person) bool {
+				return p.Name == "Beth"
+			},
+		).(consume.BulkConsumer)
+		for consumer.CanConsume() {
+			consumer.ConsumeSlice(&people)
+		}
+		pager.Finalize()
+	}
+}
@@ -0,0 +1,205 @@
+package consume
+
+import (
+	"reflect"
+)
+
+var (
+	intsType     = reflect.TypeOf([]int(nil))
+	int64sType   = reflect.TypeOf([]int64(nil))
+	float64sType = reflect.TypeOf([]float64(nil))
+	stringsType  = reflect.TypeOf([]string(nil))
+)
+
+// BulkFilterer is a Filterer that can also filter a whole slice of values
+// in one call. When every function passed to MapFilter is a BulkFilterer,
+// the ConsumeSlice method of the returned consumer filters whole slices
+// at once instead of one value at a time.
+type BulkFilterer interface {
+	Filterer
+
+	// FilterSlice appends the values in the slice src that pass this
+	// filter to the slice dstPtr points to. src and the slice dstPtr
+	// points to must have the same type.
+	FilterSlice(dstPtr interface{}, src interface{})
+}
+
+// FilterInts appends the values in src for which pred returns true to
+// the slice dst points to.
+func FilterInts(dst *[]int, src []int, pred func(int) bool) {
+	result := *dst
+	for _, x := range src {
+		if pred(x) {
+			result = append(result, x)
+		}
+	}
+	*dst = result
+}
+
+// FilterInt64s appends the values in src for which pred returns true to
+// the slice dst points to.
+func FilterInt64s(dst *[]int64, src []int64, pred func(int64) bool) {
+	result := *dst
+	for _, x := range src {
+		if pred(x) {
+			result = append(result, x)
+		}
+	}
+	*dst = result
+}
+
+// FilterFloat64s appends the values in src for which pred returns true to
+// the slice dst points to.
+func FilterFloat64s(
+	dst *[]float64, src []float64, pred func(float64) bool) {
+	result := *dst
+	for _, x := range src {
+		if pred(x) {
+			result = append(result, x)
+		}
+	}
+	*dst = result
+}
+
+// FilterStrings appends the values in src for which pred returns true to
+// the slice dst points to.
+func FilterStrings(dst *[]string, src []string, pred func(string) bool) {
+	result := *dst
+	for _, x := range src {
+		if pred(x) {
+			result = append(result, x)
+		}
+	}
+	*dst = result
+}
+
+// IntFilter returns a BulkFilterer of *int values that accepts the values
+// for which pred returns true.
+func IntFilter(pred func(int) bool) BulkFilterer {
+	return intFilter(pred)
+}
+
+// Int64Filter returns a BulkFilterer of *int64 values that accepts the
+// values for which pred returns true.
+func Int64Filter(pred func(int64) bool) BulkFilterer {
+	return int64Filter(pred)
+}
+
+// Float64Filter returns a BulkFilterer of *float64 values that accepts the
+// values for which pred returns true.
+func Float64Filter(pred func(float64) bool) BulkFilterer {
+	return float64Filter(pred)
+}
+
+// StringFilter returns a BulkFilterer of *string values that accepts the
+// values for which pred returns true.
+func StringFilter(pred func(string) bool) BulkFilterer {
+	return stringFilter(pred)
+}
+
+type intFilter func(int) bool
+
+func (f intFilter) Filter(ptr interface{}) bool {
+	return f(*ptr.(*int))
+}
+
+func (f intFilter) FilterSlice(dstPtr interface{}, src interface{}) {
+	FilterInts(
+		convertSlice(dstPtr, reflect.PtrTo(intsType)).(*[]int),
+		convertSlice(src, intsType).([]int),
+		f)
+}
+
+type int64Filter func(int64) bool
+
+func (f int64Filter) Filter(ptr interface{}) bool {
+	return f(*ptr.(*int64))
+}
+
+func (f int64Filter) FilterSlice(dstPtr interface{}, src interface{}) {
+	FilterInt64s(
+		convertSlice(dstPtr, reflect.PtrTo(int64sType)).(*[]int64),
+		convertSlice(src, int64sType).([]int64),
+		f)
+}
+
+type float64Filter func(float64) bool
+
+func (f float64Filter) Filter(ptr interface{}) bool {
+	return f(*ptr.(*float64))
+}
+
+func (f float64Filter) FilterSlice(dstPtr interface{}, src interface{}) {
+	FilterFloat64s(
+		convertSlice(dstPtr, reflect.PtrTo(float64sType)).(*[]float64),
+		convertSlice(src, float64sType).([]float64),
+		f)
+}
+
+type stringFilter func(string) bool
+
+func (f stringFilter) Filter(ptr interface{}) bool {
+	return f(*ptr.(*string))
+}
+
+func (f stringFilter) FilterSlice(dstPtr interface{}, src interface{}) {
+	FilterStrings(
+		convertSlice(dstPtr, reflect.PtrTo(stringsType)).(*[]string),
+		convertSlice(src, stringsType).([]string),
+		f)
+}
+
+// convertSlice returns x converted to t so that the FilterSlice methods
+// also work with named slice types such as a type Ints []int.
+func convertSlice(x interface{}, t reflect.Type) interface{} {
+	if reflect.TypeOf(x) == t {
+		return x
+	}
+	return reflect.ValueOf(x).Convert(t).Interface()
+}
+
+// bulkFilters returns the BulkFilterers making up mapFilters. bulkFilters
+// returns false if any stage of mapFilters is not a BulkFilterer.
+func bulkFilters(mapFilters MapFilterer) ([]BulkFilterer, bool) {
+	stages := mapFilters.stages()
+	result := make([]BulkFilterer, 0, len(stages))
+	for _, stage := range stages {
+		wrapper, ok := stage.(*filtererInterfaceWrapper)
+		if !ok {
+			return nil, false
+		}
+		bulk, ok := wrapper.value.(BulkFilterer)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, bulk)
+	}
+	return result, true
+}
+
+// bulkFilterScratch holds the slices that a mapFilterConsumer filters
+// into. Filters take turns writing to each slice so that the slices can
+// be reused from one call of ConsumeSlice to the next.
+type bulkFilterScratch struct {
+	filters []BulkFilterer
+	buffers [2]reflect.Value
+}
+
+// filter runs values through all the filters and returns a pointer to
+// the slice of values that pass.
+func (b *bulkFilterScratch) filter(values reflect.Value) interface{} {
+	sliceType := values.Type()
+	if !b.buffers[0].IsValid() || b.buffers[0].Type().Elem() != sliceType {
+		b.buffers[0] = reflect.New(sliceType)
+		b.buffers[1] = reflect.New(sliceType)
+	}
+	src := values
+	var dstPtr reflect.Value
+	for i, f := range b.filters {
+		dstPtr = b.buffers[i%2]
+		dstPtr.Elem().SetLen(0)
+		f.FilterSlice(dstPtr.Interface(), src.Interface())
+		src = dstPtr.Elem()
+	}
+	return dstPtr.Interface()
+}
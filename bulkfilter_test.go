@@ -0,0 +1,95 @@
+package consume_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterInts(t *testing.T) {
+	assert := assert.New(t)
+	dst := []int{100}
+	consume.FilterInts(
+		&dst, []int{1, 2, 3, 4, 5}, func(x int) bool { return x%2 == 1 })
+	assert.Equal([]int{100, 1, 3, 5}, dst)
+}
+
+func TestFilterStrings(t *testing.T) {
+	assert := assert.New(t)
+	var dst []string
+	consume.FilterStrings(
+		&dst,
+		[]string{"apple", "banana", "avocado"},
+		func(s string) bool { return strings.HasPrefix(s, "a") })
+	assert.Equal([]string{"apple", "avocado"}, dst)
+}
+
+func TestBulkFilterMapFilter(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.MapFilter(
+		consume.Slice(consume.AppendTo(&ints), 0, 4),
+		consume.IntFilter(func(x int) bool { return x%2 == 0 }),
+		consume.IntFilter(func(x int) bool { return x%3 != 0 }),
+	).(consume.BulkConsumer)
+	consumer.ConsumeSlice(&[]int{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	assert.Equal([]int{2, 4, 8}, ints)
+	consumer.ConsumeSlice(&[]int{9, 10, 11, 12, 14, 16})
+	assert.Equal([]int{2, 4, 8, 10}, ints)
+	assert.False(consumer.CanConsume())
+}
+
+func TestBulkFilterOneAtATime(t *testing.T) {
+	assert := assert.New(t)
+	var floats []float64
+	consumer := consume.MapFilter(
+		consume.AppendTo(&floats),
+		consume.Float64Filter(func(x float64) bool { return x > 1.5 }))
+	for _, x := range []float64{1.0, 2.0, 1.5, 3.5} {
+		consumer.Consume(&x)
+	}
+	assert.Equal([]float64{2.0, 3.5}, floats)
+}
+
+func TestBulkFilterMixed(t *testing.T) {
+	assert := assert.New(t)
+	var int64s []int64
+	consumer := consume.MapFilter(
+		consume.AppendTo(&int64s),
+		consume.Int64Filter(func(x int64) bool { return x > 2 }),
+		func(ptr *int64) bool { return *ptr < 5 },
+	).(consume.BulkConsumer)
+	consumer.ConsumeSlice(&[]int64{1, 2, 3, 4, 5, 6})
+	assert.Equal([]int64{3, 4}, int64s)
+}
+
+func BenchmarkBulkFilterInts(b *testing.B) {
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+	var ints []int
+	consumer := consume.MapFilter(
+		consume.AppendTo(&ints),
+		consume.IntFilter(func(x int) bool { return x%2 == 0 }),
+	).(consume.BulkConsumer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ints = ints[:0]
+		consumer.ConsumeSlice(&values)
+	}
+}
+
+type namedInts []int
+
+func TestBulkFilterNamedSlice(t *testing.T) {
+	assert := assert.New(t)
+	var ints namedInts
+	consumer := consume.MapFilter(
+		consume.AppendTo(&ints),
+		consume.IntFilter(func(x int) bool { return x%2 == 0 }))
+	consume.FeedSlice(namedInts{1, 2, 3, 4}, consumer)
+	assert.Equal(namedInts{2, 4}, ints)
+}
@@ -0,0 +1,81 @@
+package consume
+
+// CheckpointState is the progress that a checkpointed consumer saves.
+type CheckpointState struct {
+
+	// Count is the number of values consumed so far.
+	Count int
+
+	// LastKey is the key of the last value consumed. LastKey is nil
+	// unless the CheckpointKey option is given.
+	LastKey interface{}
+}
+
+// CheckpointOption is an option for Checkpointed.
+type CheckpointOption func(c *checkpointConsumer)
+
+// CheckpointKey has Checkpointed record the key of the last value
+// consumed in each CheckpointState it saves. keyFunc takes a pointer to a
+// consumed value and returns its key. keyFunc can be a
+// func(ptr interface{}) interface{} or a function taking one pointer
+// argument and returning one value, e.g func(ptr *Order) int64.
+func CheckpointKey(keyFunc interface{}) CheckpointOption {
+	kf := newKeyFunc(keyFunc)
+	return func(c *checkpointConsumer) {
+		c.keyFunc = &kf
+	}
+}
+
+// Checkpointed returns a Consumer that passes the values it consumes onto
+// c and calls save with its progress after every every values so that an
+// interrupted job can resume where it left off. save is called after c
+// consumes the value that completes the checkpoint. If save returns an
+// error, the returned consumer stops consuming since progress can no
+// longer be saved. Checkpointed panics if every is not positive.
+func Checkpointed(
+	c Consumer,
+	every int,
+	save func(state CheckpointState) error,
+	options ...CheckpointOption) Consumer {
+	if every <= 0 {
+		panic("every must be positive")
+	}
+	result := &checkpointConsumer{Consumer: c, every: every, save: save}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+type checkpointConsumer struct {
+	Consumer
+	every   int
+	save    func(state CheckpointState) error
+	keyFunc *keyFunc
+	count   int
+	failed  bool
+}
+
+func (c *checkpointConsumer) children() []interface{} {
+	return []interface{}{c.Consumer}
+}
+
+func (c *checkpointConsumer) CanConsume() bool {
+	return !c.failed && c.Consumer.CanConsume()
+}
+
+func (c *checkpointConsumer) Consume(ptr interface{}) {
+	MustCanConsume(c)
+	c.Consumer.Consume(ptr)
+	c.count++
+	if c.count%c.every != 0 {
+		return
+	}
+	state := CheckpointState{Count: c.count}
+	if c.keyFunc != nil {
+		state.LastKey = c.keyFunc.key(ptr)
+	}
+	if err := c.save(state); err != nil {
+		c.failed = true
+	}
+}
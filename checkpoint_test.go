@@ -0,0 +1,55 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointed(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var states []consume.CheckpointState
+	consumer := consume.Checkpointed(
+		consume.Slice(consume.AppendTo(&ints), 0, 7),
+		3,
+		func(state consume.CheckpointState) error {
+			states = append(states, state)
+			return nil
+		},
+		consume.CheckpointKey(func(ptr *int) int { return 100 + *ptr }))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6}, ints)
+	assert.Equal(
+		[]consume.CheckpointState{
+			{Count: 3, LastKey: 102},
+			{Count: 6, LastKey: 105},
+		},
+		states)
+}
+
+func TestCheckpointedSaveError(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.Checkpointed(
+		consume.AppendTo(&ints),
+		2,
+		func(state consume.CheckpointState) error {
+			assert.Nil(state.LastKey)
+			return errors.New("save failed")
+		})
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1}, ints)
+}
+
+func TestCheckpointedPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.Checkpointed(
+			consume.Nil(),
+			0,
+			func(state consume.CheckpointState) error { return nil })
+	})
+}
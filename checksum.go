@@ -0,0 +1,31 @@
+package consume
+
+import (
+	"hash"
+)
+
+// Checksum returns a Consumer that passes the values it consumes onto c
+// unchanged while writing encode(ptr) to h for each consumed value. When
+// consumption is done, h.Sum() contains the checksum of everything that
+// was passed onto c. The CanConsume method of returned consumer returns
+// the same as c.CanConsume().
+func Checksum(
+	c Consumer, h hash.Hash, encode func(ptr interface{}) []byte) Consumer {
+	return &checksumConsumer{Consumer: c, hash: h, encode: encode}
+}
+
+type checksumConsumer struct {
+	Consumer
+	hash   hash.Hash
+	encode func(ptr interface{}) []byte
+}
+
+func (c *checksumConsumer) children() []interface{} {
+	return []interface{}{c.Consumer}
+}
+
+func (c *checksumConsumer) Consume(ptr interface{}) {
+	MustCanConsume(c)
+	c.hash.Write(c.encode(ptr))
+	c.Consumer.Consume(ptr)
+}
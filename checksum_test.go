@@ -0,0 +1,20 @@
+package consume_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	h := sha256.New()
+	feedInts(t, consume.Checksum(
+		consume.Slice(consume.AppendTo(&ints), 0, 3), h, formatInt))
+	assert.Equal([]int{0, 1, 2}, ints)
+	expected := sha256.Sum256([]byte("012"))
+	assert.Equal(expected[:], h.Sum(nil))
+}
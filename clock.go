@@ -0,0 +1,18 @@
+package consume
+
+import (
+	"time"
+)
+
+// Clock tells the current time.
+type Clock interface {
+
+	// Now returns the current time.
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (s systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,313 @@
+package consume
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// CoerceTo returns a Mapper that converts values to the type of
+// prototype. prototype may be a value or a pointer to a value of the
+// target type. The returned Mapper converts numbers between numeric types
+// as long as no precision is lost, so float64 values decoded from JSON
+// can become ints. It converts maps with string keys such as the
+// map[string]interface{} values decoded from JSON or other struct values
+// into structs matching keys and field names to the struct field names
+// as described in SchemaField. If no name matches exactly, a case
+// insensitive match is used. Keys and fields without a match are ignored,
+// and struct fields with no matching key are left as zero values. The
+// returned Mapper also converts slices, maps, and pointers element by
+// element. The field names of each struct type are looked up once and
+// cached. Since the Map method of returned Mapper returns nil for values
+// that can't be converted, MapFilter drops those values.
+func CoerceTo(prototype interface{}) Mapper {
+	targetType := reflect.TypeOf(prototype)
+	if targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	return newCoerceMapper(targetType, &coercer{})
+}
+
+type coerceMapper struct {
+	targetType reflect.Type
+	coercer    *coercer
+	result     reflect.Value
+	iresult    interface{}
+}
+
+func newCoerceMapper(targetType reflect.Type, c *coercer) *coerceMapper {
+	result := reflect.New(targetType)
+	return &coerceMapper{
+		targetType: targetType,
+		coercer:    c,
+		result:     result,
+		iresult:    result.Interface(),
+	}
+}
+
+func (c *coerceMapper) Map(ptr interface{}) interface{} {
+	if !c.coercer.coerce(c.result.Elem(), reflect.ValueOf(ptr).Elem()) {
+		return nil
+	}
+	return c.iresult
+}
+
+func (c *coerceMapper) Clone() Mapper {
+	return newCoerceMapper(c.targetType, c.coercer)
+}
+
+// coercer is shared among clones of a coerceMapper.
+type coercer struct {
+
+	// fields maps a struct type to its *structFields.
+	fields sync.Map
+}
+
+// structFields finds struct fields by schema name.
+type structFields struct {
+	exact  map[string]int
+	folded map[string]int
+}
+
+func (s *structFields) index(name string) int {
+	if index, ok := s.exact[name]; ok {
+		return index
+	}
+	if index, ok := s.folded[strings.ToLower(name)]; ok {
+		return index
+	}
+	return -1
+}
+
+func (c *coercer) structFields(structType reflect.Type) *structFields {
+	if fields, ok := c.fields.Load(structType); ok {
+		return fields.(*structFields)
+	}
+	result := &structFields{
+		exact:  make(map[string]int),
+		folded: make(map[string]int),
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		name, ok := schemaName(structType.Field(i))
+		if !ok {
+			continue
+		}
+		result.exact[name] = i
+		if _, ok := result.folded[strings.ToLower(name)]; !ok {
+			result.folded[strings.ToLower(name)] = i
+		}
+	}
+	c.fields.Store(structType, result)
+	return result
+}
+
+// coerce converts src and stores it in dest returning false if src
+// can't be converted.
+func (c *coercer) coerce(dest, src reflect.Value) bool {
+	src = indirectValue(src)
+	if !src.IsValid() {
+		dest.Set(reflect.Zero(dest.Type()))
+		return true
+	}
+	if src.Type().AssignableTo(dest.Type()) {
+		dest.Set(src)
+		return true
+	}
+	switch dest.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(dest.Type().Elem())
+		if !c.coerce(elem.Elem(), src) {
+			return false
+		}
+		dest.Set(elem)
+		return true
+	case reflect.Struct:
+		return c.coerceStruct(dest, src)
+	case reflect.Slice:
+		return c.coerceSlice(dest, src)
+	case reflect.Map:
+		return c.coerceMap(dest, src)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return coerceInt(dest, src)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return coerceUint(dest, src)
+	case reflect.Float32, reflect.Float64:
+		return coerceFloat(dest, src)
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			return false
+		}
+		dest.SetString(src.String())
+		return true
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			return false
+		}
+		dest.SetBool(src.Bool())
+		return true
+	}
+	return false
+}
+
+func (c *coercer) coerceStruct(dest, src reflect.Value) bool {
+	fields := c.structFields(dest.Type())
+	switch src.Kind() {
+	case reflect.Map:
+		if src.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		dest.Set(reflect.Zero(dest.Type()))
+		iter := src.MapRange()
+		for iter.Next() {
+			index := fields.index(iter.Key().String())
+			if index < 0 {
+				continue
+			}
+			if !c.coerce(dest.Field(index), iter.Value()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		dest.Set(reflect.Zero(dest.Type()))
+		srcType := src.Type()
+		for i := 0; i < srcType.NumField(); i++ {
+			name, ok := schemaName(srcType.Field(i))
+			if !ok {
+				continue
+			}
+			index := fields.index(name)
+			if index < 0 {
+				continue
+			}
+			if !c.coerce(dest.Field(index), src.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (c *coercer) coerceSlice(dest, src reflect.Value) bool {
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		return false
+	}
+	result := reflect.MakeSlice(dest.Type(), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		if !c.coerce(result.Index(i), src.Index(i)) {
+			return false
+		}
+	}
+	dest.Set(result)
+	return true
+}
+
+func (c *coercer) coerceMap(dest, src reflect.Value) bool {
+	if src.Kind() != reflect.Map {
+		return false
+	}
+	destType := dest.Type()
+	result := reflect.MakeMapWithSize(destType, src.Len())
+	key := reflect.New(destType.Key()).Elem()
+	value := reflect.New(destType.Elem()).Elem()
+	iter := src.MapRange()
+	for iter.Next() {
+		if !c.coerce(key, iter.Key()) || !c.coerce(value, iter.Value()) {
+			return false
+		}
+		result.SetMapIndex(key, value)
+	}
+	dest.Set(result)
+	return true
+}
+
+func coerceInt(dest, src reflect.Value) bool {
+	var x int64
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		x = src.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		if src.Uint() > math.MaxInt64 {
+			return false
+		}
+		x = int64(src.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return false
+		}
+		x = int64(f)
+	default:
+		return false
+	}
+	if dest.OverflowInt(x) {
+		return false
+	}
+	dest.SetInt(x)
+	return true
+}
+
+// coerceFloat stores src in dest only if converting it back gives src.
+func coerceFloat(dest, src reflect.Value) bool {
+	var f float64
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		x := src.Int()
+		f = float64(x)
+		if f >= math.MaxInt64 || int64(f) != x {
+			return false
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		x := src.Uint()
+		f = float64(x)
+		if f >= math.MaxUint64 || uint64(f) != x {
+			return false
+		}
+	case reflect.Float32, reflect.Float64:
+		f = src.Float()
+	default:
+		return false
+	}
+	if dest.Kind() == reflect.Float32 && float64(float32(f)) != f &&
+		!math.IsNaN(f) {
+		return false
+	}
+	dest.SetFloat(f)
+	return true
+}
+
+func coerceUint(dest, src reflect.Value) bool {
+	var x uint64
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		if src.Int() < 0 {
+			return false
+		}
+		x = uint64(src.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		x = src.Uint()
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return false
+		}
+		x = uint64(f)
+	default:
+		return false
+	}
+	if dest.OverflowUint(x) {
+		return false
+	}
+	dest.SetUint(x)
+	return true
+}
@@ -0,0 +1,93 @@
+package consume_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID       int64 `consume:"id"`
+	Customer person
+	Items    []string
+	Counts   map[string]uint8
+	Note     *string
+}
+
+func TestCoerceTo(t *testing.T) {
+	assert := assert.New(t)
+	docs := []string{
+		`{"id": 7, "customer": {"Name": "Mark", "Age": 50},
+		  "items": ["a", "b"], "Counts": {"a": 2}, "Note": "rush",
+		  "extra": true}`,
+		`{"id": 7.5}`,
+		`{"id": 8, "Counts": {"a": 300}}`,
+		`{"id": 9}`,
+	}
+	var values []interface{}
+	for _, doc := range docs {
+		var value map[string]interface{}
+		assert.NoError(json.Unmarshal([]byte(doc), &value))
+		values = append(values, value)
+	}
+	var orders []order
+	consume.FeedSlice(
+		values,
+		consume.MapFilter(
+			consume.AppendTo(&orders), consume.CoerceTo(order{})))
+	assert.Len(orders, 2)
+	assert.Equal(int64(7), orders[0].ID)
+	assert.Equal(people[mark], orders[0].Customer)
+	assert.Equal([]string{"a", "b"}, orders[0].Items)
+	assert.Equal(map[string]uint8{"a": 2}, orders[0].Counts)
+	assert.Equal("rush", *orders[0].Note)
+	assert.Equal(order{ID: 9}, orders[1])
+}
+
+func TestCoerceToFloat(t *testing.T) {
+	assert := assert.New(t)
+	values := []interface{}{
+		1.5,
+		0.1,
+		math.MaxFloat64,
+		int64(1 << 24),
+		int64(1<<24 + 1),
+		int64(1<<53 + 1),
+		uint64(math.MaxUint64),
+	}
+	var float32s []float32
+	consume.FeedSlice(
+		values,
+		consume.MapFilter(
+			consume.AppendTo(&float32s), consume.CoerceTo(float32(0))))
+	assert.Equal([]float32{1.5, 1 << 24}, float32s)
+	var float64s []float64
+	consume.FeedSlice(
+		values,
+		consume.MapFilter(
+			consume.AppendTo(&float64s), consume.CoerceTo(float64(0))))
+	assert.Equal(
+		[]float64{1.5, 0.1, math.MaxFloat64, 1 << 24, 1<<24 + 1}, float64s)
+}
+
+func TestCoerceToStruct(t *testing.T) {
+	assert := assert.New(t)
+	type named struct {
+		Name string
+	}
+	var names []named
+	consume.FeedSlice(
+		people[:2],
+		consume.MapFilter(
+			consume.AppendTo(&names), consume.CoerceTo(&named{})))
+	assert.Equal([]named{{Name: "Mark"}, {Name: "Stoney"}}, names)
+
+	var ints []int
+	consume.FeedSlice(
+		[]interface{}{3.0, "x", uint(4), 5},
+		consume.MapFilter(consume.AppendTo(&ints), consume.CoerceTo(0)))
+	assert.Equal([]int{3, 4, 5}, ints)
+}
@@ -0,0 +1,89 @@
+package consume
+
+const (
+	kMaxInt = int(^uint(0) >> 1)
+)
+
+// Compile returns a Consumer that behaves like c but has fewer layers.
+// Compile knows about the consumers that this package creates. It merges
+// MapFilter over MapFilter into a single MapFilter, Slice over Slice into
+// a single Slice, and Compose of Compose into a single Compose. Compile
+// leaves consumers it does not know about as is. Compile should be called
+// before c consumes any values. After calling Compile, caller should use
+// only the returned consumer and not the consumers making up c.
+func Compile(c Consumer) Consumer {
+	switch t := c.(type) {
+	case *mapFilterConsumer:
+		inner := Compile(t.Consumer)
+		if innerMapFilter, ok := inner.(*mapFilterConsumer); ok {
+			merged := NewMapFilterer(t.mapFilters, innerMapFilter.mapFilters)
+			return &mapFilterConsumer{
+				Consumer:   innerMapFilter.Consumer,
+				mapFilters: merged,
+				ctx:        merged.NewContext(),
+			}
+		}
+		return &mapFilterConsumer{
+			Consumer: inner, mapFilters: t.mapFilters, ctx: t.ctx}
+	case *takeWhileConsumer:
+		return &takeWhileConsumer{
+			consumer:   Compile(t.consumer),
+			mapFilters: t.mapFilters,
+			ctx:        t.ctx,
+			done:       t.done,
+		}
+	case *sliceConsumer:
+		inner := Compile(t.consumer)
+		innerSlice, ok := inner.(*sliceConsumer)
+		// An inner slice with end <= 0 can't consume at all, so the outer
+		// slice can't either. A merged slice would still consume the
+		// values in the outer range.
+		if !ok || t.idx != 0 || innerSlice.idx != 0 || innerSlice.end <= 0 {
+			return &sliceConsumer{
+				consumer: inner, start: t.start, end: t.end, idx: t.idx}
+		}
+
+		// The outer slice passes its value i to the inner slice as value
+		// i - start. So the inner slice passes on the values in
+		// [start + innerStart, start + innerEnd) of the outer slice.
+		start := nonNegative(t.start)
+		end := t.end
+		if innerEnd := addSaturating(start, innerSlice.end); innerEnd < end {
+			end = innerEnd
+		}
+		return &sliceConsumer{
+			consumer: innerSlice.consumer,
+			start:    addSaturating(start, nonNegative(innerSlice.start)),
+			end:      end,
+		}
+	case *multiConsumer:
+		var consumers []Consumer
+		for _, consumer := range t.consumers {
+			compiled := Compile(consumer)
+			if multi, ok := compiled.(*multiConsumer); ok {
+				consumers = append(consumers, multi.consumers...)
+			} else {
+				consumers = append(consumers, compiled)
+			}
+		}
+		return Compose(consumers...)
+	default:
+		return c
+	}
+}
+
+func nonNegative(x int) int {
+	if x < 0 {
+		return 0
+	}
+	return x
+}
+
+// addSaturating returns x + y for non-negative x and y or the largest int
+// if x + y overflows.
+func addSaturating(x, y int) int {
+	if sum := x + y; sum >= x {
+		return sum
+	}
+	return kMaxInt
+}
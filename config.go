@@ -0,0 +1,127 @@
+package consume
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry maps names to stages and sinks so that pipelines can be
+// defined as data with PipelineConfig. A Registry is safe to use from
+// multiple goroutines.
+type Registry struct {
+	mu     sync.RWMutex
+	stages map[string]MapFilterer
+	sinks  map[string]func() ConsumeFinalizer
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		stages: make(map[string]MapFilterer),
+		sinks:  make(map[string]func() ConsumeFinalizer),
+	}
+}
+
+// RegisterStage registers a filter or mapper under name. funcs can be
+// anything that MapFilter accepts. If there are multiple funcs, they are
+// chained together into one stage. RegisterStage replaces any stage
+// already registered under name. RegisterStage panics if funcs are not
+// valid for MapFilter.
+func (r *Registry) RegisterStage(name string, funcs ...interface{}) {
+	mapFilters := NewMapFilterer(funcs...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stages[name] = mapFilters
+}
+
+// RegisterSink registers a sink under name. build creates a new instance
+// of the sink each time a pipeline is built. RegisterSink replaces any
+// sink already registered under name.
+func (r *Registry) RegisterSink(name string, build func() ConsumeFinalizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks[name] = build
+}
+
+func (r *Registry) stage(name string) (MapFilterer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result, ok := r.stages[name]
+	return result, ok
+}
+
+func (r *Registry) sink(name string) (func() ConsumeFinalizer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result, ok := r.sinks[name]
+	return result, ok
+}
+
+// PipelineConfig defines a pipeline as data. A pipeline built from a
+// PipelineConfig runs each consumed value through the named stages in
+// order, keeps the values from Start inclusive to End exclusive, and
+// passes those onto the named sink.
+type PipelineConfig struct {
+
+	// Stages are the names of registered stages.
+	Stages []string `json:"stages,omitempty" yaml:"stages,omitempty"`
+
+	// Start is the zero based index of the first value that reaches the
+	// sink.
+	Start int `json:"start,omitempty" yaml:"start,omitempty"`
+
+	// End is the zero based index of the value that stops the pipeline.
+	// Zero means no end.
+	End int `json:"end,omitempty" yaml:"end,omitempty"`
+
+	// Sink is the name of a registered sink.
+	Sink string `json:"sink" yaml:"sink"`
+}
+
+// BuildFromConfig builds the pipeline that cfg defines using the stages
+// and sinks in registry. Finalizing the returned pipeline finalizes its
+// sink. BuildFromConfig returns an error if cfg names a stage or sink not
+// in registry or if its bounds are invalid. Bounds are invalid if either
+// is negative or if End is non zero and less than Start.
+func BuildFromConfig(
+	cfg PipelineConfig, registry *Registry) (ConsumeFinalizer, error) {
+	if cfg.Start < 0 || cfg.End < 0 || cfg.End > 0 && cfg.Start > cfg.End {
+		return nil, fmt.Errorf(
+			"consume: invalid bounds start=%d end=%d", cfg.Start, cfg.End)
+	}
+	stages := make([]interface{}, len(cfg.Stages))
+	for i, name := range cfg.Stages {
+		stage, ok := registry.stage(name)
+		if !ok {
+			return nil, fmt.Errorf("consume: unknown stage %q", name)
+		}
+		stages[i] = stage
+	}
+	build, ok := registry.sink(cfg.Sink)
+	if !ok {
+		return nil, fmt.Errorf("consume: unknown sink %q", cfg.Sink)
+	}
+	sink := build()
+	var consumer Consumer = sink
+	if cfg.End > 0 {
+		consumer = Slice(consumer, cfg.Start, cfg.End)
+	} else if cfg.Start > 0 {
+		consumer, _ = SliceFrom(consumer, ResumeToken{Offset: cfg.Start})
+	}
+	consumer = MapFilter(consumer, stages...)
+	return &finalizingConsumer{Consumer: consumer, finalizer: sink}, nil
+}
+
+// finalizingConsumer consumes with Consumer and finalizes finalizer.
+type finalizingConsumer struct {
+	Consumer
+	finalizer ConsumeFinalizer
+}
+
+func (f *finalizingConsumer) children() []interface{} {
+	return []interface{}{f.Consumer}
+}
+
+func (f *finalizingConsumer) Finalize() {
+	f.finalizer.Finalize()
+}
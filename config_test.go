@@ -0,0 +1,79 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFromConfig(t *testing.T) {
+	assert := assert.New(t)
+	var strs []string
+	registry := consume.NewRegistry()
+	registry.RegisterStage("evens", func(ptr *int) bool {
+		return (*ptr)%2 == 0
+	})
+	registry.RegisterStage("itoa", func(src *int, dest *string) bool {
+		*dest = strconv.Itoa(*src)
+		return true
+	})
+	registry.RegisterSink("strings", func() consume.ConsumeFinalizer {
+		return consume.AppendToSaveMemory(&strs)
+	})
+	cf, err := consume.BuildFromConfig(
+		consume.PipelineConfig{
+			Stages: []string{"evens", "itoa"},
+			Start:  1,
+			End:    4,
+			Sink:   "strings",
+		},
+		registry)
+	assert.NoError(err)
+	feedInts(t, cf)
+	cf.Finalize()
+	assert.Equal([]string{"2", "4", "6"}, strs)
+
+	strs = nil
+	cf, err = consume.BuildFromConfig(
+		consume.PipelineConfig{Stages: []string{"itoa"}, Sink: "strings"},
+		registry)
+	assert.NoError(err)
+	feedInts(t, consume.Slice(cf, 0, 3))
+	cf.Finalize()
+	assert.Equal([]string{"0", "1", "2"}, strs)
+
+	strs = nil
+	cf, err = consume.BuildFromConfig(
+		consume.PipelineConfig{
+			Stages: []string{"itoa"}, Start: 2, Sink: "strings"},
+		registry)
+	assert.NoError(err)
+	feedInts(t, consume.Slice(cf, 0, 4))
+	assert.True(cf.CanConsume())
+	cf.Finalize()
+	assert.Equal([]string{"2", "3"}, strs)
+}
+
+func TestBuildFromConfigErrors(t *testing.T) {
+	assert := assert.New(t)
+	registry := consume.NewRegistry()
+	registry.RegisterSink("nil", func() consume.ConsumeFinalizer {
+		return consume.SliceFromEnd(consume.Nil(), 1)
+	})
+	_, err := consume.BuildFromConfig(
+		consume.PipelineConfig{Stages: []string{"missing"}, Sink: "nil"},
+		registry)
+	assert.Error(err)
+	_, err = consume.BuildFromConfig(
+		consume.PipelineConfig{Sink: "missing"}, registry)
+	assert.Error(err)
+	_, err = consume.BuildFromConfig(
+		consume.PipelineConfig{Start: -1, Sink: "nil"}, registry)
+	assert.Error(err)
+	_, err = consume.BuildFromConfig(
+		consume.PipelineConfig{Start: 5, End: 2, Sink: "nil"}, registry)
+	assert.Error(err)
+	assert.Panics(func() { registry.RegisterStage("bad", 3) })
+}
@@ -0,0 +1,141 @@
+package consume
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ConformsTo returns a Filterer that accepts only the struct values that
+// conform to schema as described in SchemaField. The returned Filterer
+// looks up the struct fields of each struct type just once. It is safe
+// to use with multiple goroutines.
+func ConformsTo(schema Schema) Filterer {
+	return ConformsToReport(schema, nil)
+}
+
+// ConformsToReport works like ConformsTo except that it calls report
+// with each rejected value and the reason it was rejected. report may be
+// nil.
+func ConformsToReport(
+	schema Schema, report func(ptr interface{}, err error)) Filterer {
+	fields := make([]SchemaField, len(schema.Fields))
+	copy(fields, schema.Fields)
+	return &conformsToFilterer{
+		fields:  fields,
+		report:  report,
+		indexes: make(map[reflect.Type][]int),
+	}
+}
+
+// Validate returns an error if the struct value ptr points to does not
+// conform to s as described in SchemaField.
+func (s *Schema) Validate(ptr interface{}) error {
+	value := reflect.ValueOf(ptr).Elem()
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("consume: %v is not a struct", value.Type())
+	}
+	indexes := fieldIndexes(s.Fields, value.Type())
+	return validateFields(s.Fields, indexes, value)
+}
+
+type conformsToFilterer struct {
+	fields  []SchemaField
+	report  func(ptr interface{}, err error)
+	mu      sync.Mutex
+	indexes map[reflect.Type][]int
+}
+
+func (c *conformsToFilterer) Filter(ptr interface{}) bool {
+	value := reflect.ValueOf(ptr).Elem()
+	var err error
+	if value.Kind() != reflect.Struct {
+		err = fmt.Errorf("consume: %v is not a struct", value.Type())
+	} else {
+		err = validateFields(c.fields, c.fieldIndexes(value.Type()), value)
+	}
+	if err != nil && c.report != nil {
+		c.report(ptr, err)
+	}
+	return err == nil
+}
+
+func (c *conformsToFilterer) fieldIndexes(structType reflect.Type) []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.indexes[structType]
+	if !ok {
+		result = fieldIndexes(c.fields, structType)
+		c.indexes[structType] = result
+	}
+	return result
+}
+
+// fieldIndexes returns the index of each field in structType. An index
+// is -1 if structType does not have that field.
+func fieldIndexes(fields []SchemaField, structType reflect.Type) []int {
+	result := make([]int, len(fields))
+	for i := range fields {
+		result[i] = schemaFieldIndex(structType, fields[i].Name)
+	}
+	return result
+}
+
+func validateFields(
+	fields []SchemaField, indexes []int, value reflect.Value) error {
+	for i := range fields {
+		if indexes[i] < 0 {
+			if fields[i].Required {
+				return fmt.Errorf(
+					"consume: missing required field %s", fields[i].Name)
+			}
+			continue
+		}
+		err := validateField(&fields[i], value.Field(indexes[i]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateField(field *SchemaField, value reflect.Value) error {
+	if field.Type != nil && !value.Type().AssignableTo(field.Type) {
+		return fmt.Errorf(
+			"consume: field %s has type %v, want %v",
+			field.Name, value.Type(), field.Type)
+	}
+	value = indirectValue(value)
+	if !value.IsValid() || isNilValue(value) {
+		if field.Required {
+			return fmt.Errorf("consume: required field %s is nil", field.Name)
+		}
+		return nil
+	}
+	if field.Min == nil && field.Max == nil {
+		return nil
+	}
+	number, ok := floatValue(value)
+	if !ok {
+		return fmt.Errorf("consume: field %s is not a number", field.Name)
+	}
+	if field.Min != nil && number < *field.Min {
+		return fmt.Errorf(
+			"consume: field %s: %v below min %v",
+			field.Name, number, *field.Min)
+	}
+	if field.Max != nil && number > *field.Max {
+		return fmt.Errorf(
+			"consume: field %s: %v above max %v",
+			field.Name, number, *field.Max)
+	}
+	return nil
+}
+
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package consume_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Name  string
+	Age   int
+	Email *string
+}
+
+var signupSchema = consume.Schema{
+	Fields: []consume.SchemaField{
+		{Name: "Name", Type: reflect.TypeOf(""), Required: true},
+		{Name: "Age", Min: float64Ptr(18), Max: float64Ptr(120)},
+		{Name: "Email", Required: true},
+	},
+}
+
+func TestConformsTo(t *testing.T) {
+	assert := assert.New(t)
+	email := "a@b.com"
+	signups := []signup{
+		{Name: "Mark", Age: 50, Email: &email},
+		{Name: "Dillon", Age: 17, Email: &email},
+		{Name: "Beth", Age: 54},
+		{Name: "Stoney", Age: 121, Email: &email},
+		{Name: "Matt", Age: 18, Email: &email},
+	}
+	var names []string
+	var rejected []string
+	consume.FeedSlice(
+		signups,
+		consume.MapFilter(
+			consume.AppendTo(&names),
+			consume.ConformsToReport(
+				signupSchema,
+				func(ptr interface{}, err error) {
+					rejected = append(rejected, err.Error())
+				}),
+			func(src *signup, dest *string) bool {
+				*dest = src.Name
+				return true
+			}))
+	assert.Equal([]string{"Mark", "Matt"}, names)
+	assert.Equal(
+		[]string{
+			"consume: field Age: 17 below min 18",
+			"consume: required field Email is nil",
+			"consume: field Age: 121 above max 120",
+		},
+		rejected)
+}
+
+func TestSchemaValidate(t *testing.T) {
+	assert := assert.New(t)
+	assert.NoError(signupSchema.Validate(
+		&signup{Name: "a", Age: 30, Email: new(string)}))
+	assert.EqualError(
+		signupSchema.Validate(&people[mark]),
+		"consume: missing required field Email")
+	schema := consume.Schema{
+		Fields: []consume.SchemaField{
+			{Name: "Age", Type: reflect.TypeOf("")},
+			{Name: "Name", Min: float64Ptr(0)},
+		},
+	}
+	assert.EqualError(
+		schema.Validate(&people[mark]),
+		"consume: field Age has type int, want string")
+	schema.Fields = schema.Fields[1:]
+	assert.EqualError(
+		schema.Validate(&people[mark]),
+		"consume: field Name is not a number")
+	x := 3
+	assert.Error(schema.Validate(&x))
+	assert.False(consume.ConformsTo(schema).Filter(&x))
+}
+
+func float64Ptr(x float64) *float64 {
+	return &x
+}
@@ -3,6 +3,7 @@ package consume
 
 import (
 	"reflect"
+	"sync"
 )
 
 const (
@@ -71,11 +72,7 @@ func Nil() Consumer {
 func AppendToSaveMemory(aValueSlicePointer interface{}) ConsumeFinalizer {
 	aSliceValue := sliceValueFromP(aValueSlicePointer, false)
 	length := aSliceValue.Len()
-	if aSliceValue.Cap() < 4 {
-		truncateTo(aSliceValue, 4)
-	} else {
-		truncateTo(aSliceValue, aSliceValue.Cap())
-	}
+	expandToCapacity(aSliceValue)
 	return &appendSaveMemoryConsumer{
 		buffer: aSliceValue, length: length}
 }
@@ -108,7 +105,8 @@ func AppendPtrsTo(aPointerSlicePointer interface{}) Consumer {
 // returned consumer consumes a value, each consumer passed in that is able to
 // consume a value consumes that value. CanConsume() of returned consumer
 // returns false when the CanConsume() method of each consumer passed in
-// returns false.
+// returns false. The returned consumer implements Health reporting the
+// first error from the consumers passed in that implement Health.
 func Compose(consumers ...Consumer) Consumer {
 	clen := len(consumers)
 	switch clen {
@@ -119,7 +117,10 @@ func Compose(consumers ...Consumer) Consumer {
 	default:
 		consumerList := make([]Consumer, clen)
 		copy(consumerList, consumers)
-		return &multiConsumer{consumers: consumerList}
+		return &multiConsumer{
+			consumers: consumerList,
+			all:       append([]Consumer(nil), consumerList...),
+		}
 	}
 }
 
@@ -136,8 +137,31 @@ func Slice(consumer Consumer, start, end int) Consumer {
 	return &sliceConsumer{consumer: consumer, start: start, end: end}
 }
 
+// SliceStep works like Slice except that it passes only every step th
+// value onto consumer starting with the start th value. For example,
+// SliceStep(consumer, 5, 50, 10) passes the 5th, 15th, 25th, 35th, and
+// 45th values onto consumer. SliceStep(consumer, start, end, 1) is the
+// same as Slice(consumer, start, end). SliceStep panics if step is not
+// positive.
+func SliceStep(consumer Consumer, start, end, step int) Consumer {
+	if step <= 0 {
+		panic("step must be positive")
+	}
+	if step == 1 {
+		return Slice(consumer, start, end)
+	}
+	if start < 0 {
+		start = 0
+	}
+	return &stepSliceConsumer{
+		consumer: consumer, start: start, end: end, step: step}
+}
+
 // MapFilterer represents zero or more functions like the ones passed
-// to MapFilter chained together.
+// to MapFilter chained together. The functions themselves never change.
+// The mapped values they produce are stored in a MapFilterContext. This
+// way, a single MapFilterer can be shared safely as long as each user of
+// it has its own MapFilterContext.
 type MapFilterer interface {
 
 	// MapFilter applies the chained filter and map functions to what ptr
@@ -145,23 +169,45 @@ type MapFilterer interface {
 	// should be filtered out; returns ptr itself; or returns a pointer to
 	// a mapped value. If MapFilter returns a pointer to a mapped value,
 	// it gets overwritten with each call to MapFilter because such values
-	// are stored within this MapFilterer to avoid memory allocations.
+	// are stored within a default MapFilterContext that this MapFilterer
+	// owns to avoid memory allocations. Because of that default context,
+	// MapFilter is not safe to call from multiple goroutines at once.
 	MapFilter(ptr interface{}) interface{}
 
-	addClones(result *[]MapFilterer)
+	// NewContext returns a new MapFilterContext for use with
+	// MapFilterWithContext.
+	NewContext() *MapFilterContext
+
+	// MapFilterWithContext works like MapFilter except that it stores
+	// mapped values in ctx instead of in the default context. ctx must
+	// come from the NewContext method of this instance. Multiple
+	// goroutines may call MapFilterWithContext at once as long as each
+	// uses its own ctx.
+	MapFilterWithContext(ctx *MapFilterContext, ptr interface{}) interface{}
+
+	stages() []mapFilterStage
 	size() int
 }
 
+// MapFilterContext holds the mapped values for one user of a MapFilterer.
+// A MapFilterContext must not be used by multiple goroutines at once.
+type MapFilterContext struct {
+	scratch []interface{}
+}
+
 // Mapper maps a value to a new value.
 type Mapper interface {
 
 	// Map takes a pointer to a value and returns a pointer to the
-	// mapped value. Map returns the same pointer every time, but the
-	// value that pointer points to changes.
+	// mapped value or nil if the value should be filtered out. When Map
+	// returns a non nil pointer, it returns the same pointer every time,
+	// but the value that pointer points to changes.
 	Map(ptr interface{}) interface{}
 
 	// Clone clones this Mapper. The Map method of the cloned Mapper
-	// returns a different pointer from the original.
+	// returns a different pointer from the original. Each
+	// MapFilterContext gets its own clone of a Mapper, and the original
+	// Mapper is never used to map values.
 	Clone() Mapper
 }
 
@@ -176,26 +222,28 @@ type Filterer interface {
 // NewMapFilterer creates a MapFilterer from multiple functions like the ones
 // passed to MapFilter chained together. The returned MapFilterer can be
 // passed as a parameter to MapFilter or to NewMapFilterer. The returned
-// MapFilterer contains copies of any MapFilterers passed in. Therefore,
-// the returned MapFilterer and any passed in MapFilterers can safely be used
-// at the same time.
+// MapFilterer shares the functions of any MapFilterers passed in, but it
+// has its own default MapFilterContext. Therefore, the returned
+// MapFilterer and any passed in MapFilterers can safely be used at the
+// same time.
 func NewMapFilterer(funcs ...interface{}) MapFilterer {
+	if len(funcs) == 1 {
+		if mf, ok := funcs[0].(MapFilterer); ok && !needsScratch(mf) {
+			return mf
+		}
+	}
 	resultSize := 0
 	for _, f := range funcs {
 		resultSize += mfSize(f)
 	}
-	result := make([]MapFilterer, 0, resultSize)
+	result := make([]mapFilterStage, 0, resultSize)
 	for _, f := range funcs {
-		mfAddClones(f, &result)
+		mfAddStages(f, &result)
 	}
-	switch len(result) {
-	case 0:
+	if len(result) == 0 {
 		return nilMapFilterer{}
-	case 1:
-		return result[0]
-	default:
-		return sliceMapFilterer(result)
 	}
+	return &stageMapFilterer{list: result}
 }
 
 // MapFilter returns a Consumer that passes only filtered and mapped
@@ -218,9 +266,13 @@ func NewMapFilterer(funcs ...interface{}) MapFilterer {
 // The NewMapFilterer function can return a MapFilterer which represents zero
 // or more of these functions chained together. MapFilterer instances can be
 // passed as parameters to MapFilter just like the functions mentioned above.
-// The MapFilter function takes a copy of any passed in MapFilterer instance,
+// The returned Consumer stores mapped values in its own MapFilterContext,
 // so any passed MapFilterer instance can safely be used at the same time as
 // the returned Consumer.
+//
+// If a function, Mapper, or Filterer panics, the returned Consumer panics
+// with a *StagePanicError telling which stage panicked and the type of
+// the value it was processing.
 func MapFilter(consumer Consumer, funcs ...interface{}) Consumer {
 	mapFilters := NewMapFilterer(funcs...)
 	if mapFilters.size() == 0 {
@@ -229,6 +281,7 @@ func MapFilter(consumer Consumer, funcs ...interface{}) Consumer {
 	return &mapFilterConsumer{
 		Consumer:   consumer,
 		mapFilters: mapFilters,
+		ctx:        mapFilters.NewContext(),
 	}
 }
 
@@ -243,6 +296,7 @@ func TakeWhile(consumer Consumer, funcs ...interface{}) Consumer {
 	return &takeWhileConsumer{
 		consumer:   consumer,
 		mapFilters: mapFilters,
+		ctx:        mapFilters.NewContext(),
 	}
 }
 
@@ -260,6 +314,15 @@ func Page(
 	itemsPerPage int,
 	aValueSlicePointer interface{},
 	morePages *bool) ConsumeFinalizer {
+	return newPageConsumer(
+		zeroBasedPageNo, itemsPerPage, aValueSlicePointer, morePages)
+}
+
+func newPageConsumer(
+	zeroBasedPageNo int,
+	itemsPerPage int,
+	aValueSlicePointer interface{},
+	morePages *bool) *pageConsumer {
 	if zeroBasedPageNo < 0 {
 		panic("zeroBasedPageNo must be non-negative")
 	}
@@ -275,14 +338,18 @@ func Page(
 		(zeroBasedPageNo+1)*itemsPerPage+1)
 	return &pageConsumer{
 		Consumer:     consumer,
+		slice:        consumer,
 		cf:           cf,
 		itemsPerPage: itemsPerPage,
 		aSliceValue:  aSliceValue,
 		morePages:    morePages}
 }
 
+// pageConsumer keeps the consumer that it replaces with nilConsumer upon
+// finalization in slice so that Reset can restore it.
 type pageConsumer struct {
 	Consumer
+	slice        Consumer
 	cf           ConsumeFinalizer
 	itemsPerPage int
 	aSliceValue  reflect.Value
@@ -290,6 +357,10 @@ type pageConsumer struct {
 	finalized    bool
 }
 
+func (p *pageConsumer) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
 func (p *pageConsumer) Finalize() {
 	if p.finalized {
 		return
@@ -305,6 +376,10 @@ func (p *pageConsumer) Finalize() {
 	}
 }
 
+func (p *pageConsumer) consumeValue(value reflect.Value) {
+	consumeValue(p.Consumer, value)
+}
+
 func ensureEmptyWithCapacity(aSliceValue reflect.Value, capacity int) {
 	if aSliceValue.Cap() < capacity {
 		typ := aSliceValue.Type()
@@ -314,6 +389,16 @@ func ensureEmptyWithCapacity(aSliceValue reflect.Value, capacity int) {
 	}
 }
 
+// expandToCapacity expands aSliceValue to its capacity making sure that
+// its length is at least 4.
+func expandToCapacity(aSliceValue reflect.Value) {
+	if aSliceValue.Cap() < 4 {
+		truncateTo(aSliceValue, 4)
+	} else {
+		truncateTo(aSliceValue, aSliceValue.Cap())
+	}
+}
+
 func truncateTo(aSliceValue reflect.Value, newLength int) {
 	if newLength <= aSliceValue.Cap() {
 		aSliceValue.Set(aSliceValue.Slice(0, newLength))
@@ -331,20 +416,64 @@ type sliceConsumer struct {
 	idx      int
 }
 
+func (s *sliceConsumer) children() []interface{} {
+	return []interface{}{s.consumer}
+}
+
 func (s *sliceConsumer) CanConsume() bool {
 	return s.consumer.CanConsume() && s.idx < s.end
 }
 
 func (s *sliceConsumer) Consume(ptr interface{}) {
+	s.consumeValue(reflect.ValueOf(ptr).Elem())
+}
+
+func (s *sliceConsumer) consumeValue(value reflect.Value) {
 	MustCanConsume(s)
 	if s.idx >= s.start {
-		s.consumer.Consume(ptr)
+		consumeValue(s.consumer, value)
+	}
+	s.idx++
+}
+
+type stepSliceConsumer struct {
+	consumer Consumer
+	start    int
+	end      int
+	step     int
+	idx      int
+}
+
+func (s *stepSliceConsumer) children() []interface{} {
+	return []interface{}{s.consumer}
+}
+
+func (s *stepSliceConsumer) CanConsume() bool {
+	return s.consumer.CanConsume() && s.idx < s.end
+}
+
+func (s *stepSliceConsumer) Consume(ptr interface{}) {
+	s.consumeValue(reflect.ValueOf(ptr).Elem())
+}
+
+func (s *stepSliceConsumer) consumeValue(value reflect.Value) {
+	MustCanConsume(s)
+	if s.idx >= s.start && (s.idx-s.start)%s.step == 0 {
+		consumeValue(s.consumer, value)
 	}
 	s.idx++
 }
 
 type multiConsumer struct {
 	consumers []Consumer
+
+	// all holds every consumer including the finished ones so that
+	// Healthy can report on them.
+	all []Consumer
+}
+
+func (m *multiConsumer) children() []interface{} {
+	return asStages(m.all)
 }
 
 func (m *multiConsumer) CanConsume() bool {
@@ -353,9 +482,13 @@ func (m *multiConsumer) CanConsume() bool {
 }
 
 func (m *multiConsumer) Consume(ptr interface{}) {
+	m.consumeValue(reflect.ValueOf(ptr).Elem())
+}
+
+func (m *multiConsumer) consumeValue(value reflect.Value) {
 	MustCanConsume(m)
 	for _, consumer := range m.consumers {
-		consumer.Consume(ptr)
+		consumeValue(consumer, value)
 	}
 }
 
@@ -383,13 +516,16 @@ func (a *appendConsumer) CanConsume() bool {
 }
 
 func (a *appendConsumer) Consume(ptr interface{}) {
-	valueToConsume := reflect.ValueOf(ptr).Elem()
+	a.consumeValue(reflect.ValueOf(ptr).Elem())
+}
+
+func (a *appendConsumer) consumeValue(value reflect.Value) {
 	if a.allocType == nil {
-		a.buffer.Set(reflect.Append(a.buffer, valueToConsume))
+		appendValue(a.buffer, value)
 	} else {
 		newPtr := reflect.New(a.allocType)
-		newPtr.Elem().Set(valueToConsume)
-		a.buffer.Set(reflect.Append(a.buffer, newPtr))
+		newPtr.Elem().Set(value)
+		appendValue(a.buffer, newPtr)
 	}
 }
 
@@ -404,13 +540,17 @@ func (a *appendSaveMemoryConsumer) CanConsume() bool {
 }
 
 func (a *appendSaveMemoryConsumer) Consume(ptr interface{}) {
+	a.consumeValue(reflect.ValueOf(ptr).Elem())
+}
+
+func (a *appendSaveMemoryConsumer) consumeValue(value reflect.Value) {
 	if a.finalized {
 		panic(kCantConsume)
 	}
 	if a.length == a.buffer.Len() {
 		truncateTo(a.buffer, 2*a.length)
 	}
-	a.buffer.Index(a.length).Set(reflect.ValueOf(ptr).Elem())
+	a.buffer.Index(a.length).Set(value)
 	a.length++
 }
 
@@ -460,14 +600,13 @@ func mfSize(f interface{}) int {
 	return 1
 }
 
-func mfAddClones(f interface{}, result *[]MapFilterer) {
+func mfAddStages(f interface{}, result *[]mapFilterStage) {
 	if mf, ok := f.(MapFilterer); ok {
-		mf.addClones(result)
+		*result = append(*result, mf.stages()...)
 		return
 	}
 	if aMapper, ok := f.(Mapper); ok {
-		*result = append(
-			*result, &mapperInterfaceWrapper{value: aMapper.Clone()})
+		*result = append(*result, &mapperInterfaceWrapper{value: aMapper})
 		return
 	}
 	if aFilterer, ok := f.(Filterer); ok {
@@ -475,26 +614,33 @@ func mfAddClones(f interface{}, result *[]MapFilterer) {
 			*result, &filtererInterfaceWrapper{value: aFilterer})
 		return
 	}
-	*result = append(*result, newMapFilterer(f))
+	*result = append(*result, newMapFilterStage(f))
 }
 
-func newMapFilterer(f interface{}) MapFilterer {
+func needsScratch(mf MapFilterer) bool {
+	for _, stage := range mf.stages() {
+		if stage.newScratch() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func newMapFilterStage(f interface{}) mapFilterStage {
 	fvalue := reflect.ValueOf(f)
 	ftype := reflect.TypeOf(f)
 	validateFuncType(ftype)
 	numIn := ftype.NumIn()
 	if numIn == 1 {
 		return &filterer{
-			value: fvalue,
+			value:  fvalue,
+			unwrap: ftype.In(0) != envelopePtrType,
 		}
 	} else if numIn == 2 {
-		resultType := ftype.In(1).Elem()
-		resultPtr := reflect.New(resultType)
 		return &mapper{
 			value:      fvalue,
-			resultType: resultType,
-			resultPtr:  resultPtr,
-			iresultPtr: resultPtr.Interface(),
+			resultType: ftype.In(1).Elem(),
+			unwrap:     ftype.In(0) != envelopePtrType,
 		}
 	} else {
 		panic("Function parameter must take 1 or 2 parameters")
@@ -519,92 +665,142 @@ func validateFuncType(ftype reflect.Type) {
 	}
 }
 
+// mapFilterStage is a single function in a MapFilterer. Stages never
+// change once created. Any mapped values they produce go in scratch space
+// that lives in a MapFilterContext.
+type mapFilterStage interface {
+
+	// newScratch returns new scratch space for this stage or nil if this
+	// stage needs none.
+	newScratch() interface{}
+
+	// mapFilter works like MapFilterer.MapFilter for this stage alone
+	// storing any mapped value in scratch.
+	mapFilter(ptr interface{}, scratch interface{}) interface{}
+}
+
 type filtererInterfaceWrapper struct {
 	value Filterer
 }
 
-func (f *filtererInterfaceWrapper) MapFilter(ptr interface{}) interface{} {
+func (f *filtererInterfaceWrapper) newScratch() interface{} { return nil }
+
+func (f *filtererInterfaceWrapper) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
 	if f.value.Filter(ptr) {
 		return ptr
 	}
 	return nil
 }
 
-func (f *filtererInterfaceWrapper) size() int { return 1 }
-
-func (f *filtererInterfaceWrapper) addClones(result *[]MapFilterer) {
-	*result = append(*result, f)
-}
-
+// mapperInterfaceWrapper uses a clone of value as its scratch space.
 type mapperInterfaceWrapper struct {
 	value Mapper
 }
 
-func (m *mapperInterfaceWrapper) MapFilter(ptr interface{}) interface{} {
-	return m.value.Map(ptr)
+func (m *mapperInterfaceWrapper) newScratch() interface{} {
+	return m.value.Clone()
 }
 
-func (m *mapperInterfaceWrapper) size() int { return 1 }
-
-func (m *mapperInterfaceWrapper) addClones(result *[]MapFilterer) {
-	*result = append(*result, m.clone())
-}
-
-func (m *mapperInterfaceWrapper) clone() *mapperInterfaceWrapper {
-	return &mapperInterfaceWrapper{value: m.value.Clone()}
+func (m *mapperInterfaceWrapper) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	return scratch.(Mapper).Map(ptr)
 }
 
+// filterer and mapper apply their function to the value inside an
+// Envelope when unwrap is true, that is, when their function doesn't take
+// an *Envelope itself.
 type filterer struct {
-	value reflect.Value
+	value  reflect.Value
+	unwrap bool
 }
 
-func (f *filterer) MapFilter(ptr interface{}) interface{} {
-	params := [...]reflect.Value{reflect.ValueOf(ptr)}
+func (f *filterer) newScratch() interface{} { return nil }
+
+func (f *filterer) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	arg := ptr
+	if env, ok := ptr.(*Envelope); ok && f.unwrap {
+		arg = env.Value
+	}
+	params := [...]reflect.Value{reflect.ValueOf(arg)}
 	if f.value.Call(params[:])[0].Bool() {
 		return ptr
 	}
 	return nil
 }
 
-func (f *filterer) size() int { return 1 }
-
-func (f *filterer) addClones(result *[]MapFilterer) {
-	*result = append(*result, f)
-}
-
 type mapper struct {
 	value      reflect.Value
 	resultType reflect.Type
+	unwrap     bool
+}
+
+type mapperScratch struct {
 	resultPtr  reflect.Value
 	iresultPtr interface{}
+	envelope   Envelope
 }
 
-func (m *mapper) MapFilter(ptr interface{}) interface{} {
-	params := [...]reflect.Value{reflect.ValueOf(ptr), m.resultPtr}
-	if m.value.Call(params[:])[0].Bool() {
-		return m.iresultPtr
-	}
-	return nil
+func (m *mapper) newScratch() interface{} {
+	resultPtr := reflect.New(m.resultType)
+	return &mapperScratch{
+		resultPtr: resultPtr, iresultPtr: resultPtr.Interface()}
 }
 
-func (m *mapper) size() int { return 1 }
+func (m *mapper) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	result := scratch.(*mapperScratch)
+	env, inEnvelope := ptr.(*Envelope)
+	if inEnvelope && m.unwrap {
+		ptr = env.Value
+	} else {
+		inEnvelope = false
+	}
+	params := [...]reflect.Value{reflect.ValueOf(ptr), result.resultPtr}
+	if !m.value.Call(params[:])[0].Bool() {
+		return nil
+	}
+	if inEnvelope {
+		result.envelope = Envelope{
+			Value: shallowCopy(result.iresultPtr), Meta: env.Meta}
+		return &result.envelope
+	}
+	return result.iresultPtr
+}
 
-func (m *mapper) addClones(result *[]MapFilterer) {
-	*result = append(*result, m.clone())
+type stageMapFilterer struct {
+	list       []mapFilterStage
+	once       sync.Once
+	defaultCtx *MapFilterContext
 }
 
-func (m *mapper) clone() *mapper {
-	result := *m
-	result.resultPtr = reflect.New(result.resultType)
-	result.iresultPtr = result.resultPtr.Interface()
-	return &result
+func (s *stageMapFilterer) MapFilter(ptr interface{}) interface{} {
+	s.once.Do(func() { s.defaultCtx = s.NewContext() })
+	return s.MapFilterWithContext(s.defaultCtx, ptr)
 }
 
-type sliceMapFilterer []MapFilterer
+func (s *stageMapFilterer) NewContext() *MapFilterContext {
+	scratch := make([]interface{}, len(s.list))
+	for i, stage := range s.list {
+		scratch[i] = stage.newScratch()
+	}
+	return &MapFilterContext{scratch: scratch}
+}
 
-func (s sliceMapFilterer) MapFilter(ptr interface{}) interface{} {
-	for _, mf := range s {
-		ptr = mf.MapFilter(ptr)
+func (s *stageMapFilterer) MapFilterWithContext(
+	ctx *MapFilterContext, ptr interface{}) interface{} {
+	if len(ctx.scratch) != len(s.list) {
+		panic("ctx must come from NewContext of the same MapFilterer")
+	}
+	i := 0
+	defer func() {
+		if i < len(s.list) {
+			rePanicWithStage(recover(), i, s.list[i], ptr)
+		}
+	}()
+	for ; i < len(s.list); i++ {
+		ptr = s.list[i].mapFilter(ptr, ctx.scratch[i])
 		if ptr == nil {
 			return nil
 		}
@@ -612,13 +808,9 @@ func (s sliceMapFilterer) MapFilter(ptr interface{}) interface{} {
 	return ptr
 }
 
-func (s sliceMapFilterer) size() int { return len(s) }
+func (s *stageMapFilterer) stages() []mapFilterStage { return s.list }
 
-func (s sliceMapFilterer) addClones(result *[]MapFilterer) {
-	for _, mf := range s {
-		mf.addClones(result)
-	}
-}
+func (s *stageMapFilterer) size() int { return len(s.list) }
 
 type nilMapFilterer struct{}
 
@@ -626,40 +818,69 @@ func (n nilMapFilterer) MapFilter(ptr interface{}) interface{} {
 	return ptr
 }
 
-func (n nilMapFilterer) size() int { return 0 }
+func (n nilMapFilterer) NewContext() *MapFilterContext {
+	return &MapFilterContext{}
+}
+
+func (n nilMapFilterer) MapFilterWithContext(
+	ctx *MapFilterContext, ptr interface{}) interface{} {
+	return ptr
+}
+
+func (n nilMapFilterer) stages() []mapFilterStage { return nil }
 
-func (n nilMapFilterer) addClones(result *[]MapFilterer) {}
+func (n nilMapFilterer) size() int { return 0 }
 
 type mapFilterConsumer struct {
 	Consumer
 	mapFilters MapFilterer
+	ctx        *MapFilterContext
+	bulk       *bulkFilterScratch
+	useBulk    bool
+}
+
+func (m *mapFilterConsumer) children() []interface{} {
+	return []interface{}{m.Consumer}
 }
 
 func (m *mapFilterConsumer) Consume(ptr interface{}) {
 	MustCanConsume(m)
-	ptr = m.mapFilters.MapFilter(ptr)
+	ptr = m.mapFilters.MapFilterWithContext(m.ctx, ptr)
 	if ptr == nil {
 		return
 	}
-	m.Consumer.Consume(ptr)
+	consumeValue(m.Consumer, reflect.ValueOf(ptr).Elem())
+}
+
+func (m *mapFilterConsumer) consumeValue(value reflect.Value) {
+	m.Consume(value.Addr().Interface())
 }
 
 type takeWhileConsumer struct {
 	consumer   Consumer
 	mapFilters MapFilterer
+	ctx        *MapFilterContext
 	done       bool
 }
 
+func (t *takeWhileConsumer) children() []interface{} {
+	return []interface{}{t.consumer}
+}
+
 func (t *takeWhileConsumer) CanConsume() bool {
 	return t.consumer.CanConsume() && !t.done
 }
 
 func (t *takeWhileConsumer) Consume(ptr interface{}) {
 	MustCanConsume(t)
-	ptr = t.mapFilters.MapFilter(ptr)
+	ptr = t.mapFilters.MapFilterWithContext(t.ctx, ptr)
 	if ptr == nil {
 		t.done = true
 		return
 	}
-	t.consumer.Consume(ptr)
+	consumeValue(t.consumer, reflect.ValueOf(ptr).Elem())
+}
+
+func (t *takeWhileConsumer) consumeValue(value reflect.Value) {
+	t.Consume(value.Addr().Interface())
 }
@@ -208,6 +208,25 @@ func TestSliceNegative(t *testing.T) {
 	assert.Empty(none)
 }
 
+func TestSliceStep(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	feedInts(t, consume.SliceStep(consume.AppendTo(&ints), 5, 50, 10))
+	assert.Equal([]int{5, 15, 25, 35, 45}, ints)
+	ints = nil
+	feedInts(t, consume.SliceStep(consume.AppendTo(&ints), -2, 7, 3))
+	assert.Equal([]int{0, 3, 6}, ints)
+	ints = nil
+	feedInts(t, consume.SliceStep(consume.AppendTo(&ints), 2, 5, 1))
+	assert.Equal([]int{2, 3, 4}, ints)
+	ints = nil
+	feedInts(t, consume.SliceStep(consume.AppendTo(&ints), 5, 3, 2))
+	assert.Empty(ints)
+	assert.Panics(func() {
+		consume.SliceStep(consume.AppendTo(&ints), 0, 10, 0)
+	})
+}
+
 func TestFilter(t *testing.T) {
 	assert := assert.New(t)
 	var sevensTo28 []int
@@ -583,3 +602,31 @@ func writePeopleInLoop(people []person, consumer consume.Consumer) {
 		index++
 	}
 }
+
+func TestSharedMapFiltererConcurrently(t *testing.T) {
+	assert := assert.New(t)
+	shared := consume.NewMapFilterer(func(src *int, dest *string) bool {
+		*dest = strconv.Itoa(*src)
+		return true
+	})
+	results := make([][]string, 4)
+	done := make(chan struct{})
+	for g := range results {
+		go func(g int) {
+			defer func() { done <- struct{}{} }()
+			consumer := consume.MapFilter(
+				consume.AppendTo(&results[g]), shared)
+			for i := 0; i < 100; i++ {
+				value := g*1000 + i
+				consumer.Consume(&value)
+			}
+		}(g)
+	}
+	for range results {
+		<-done
+	}
+	for g := range results {
+		assert.Len(results[g], 100)
+		assert.Equal(strconv.Itoa(g*1000+99), results[g][99])
+	}
+}
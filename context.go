@@ -0,0 +1,35 @@
+package consume
+
+import (
+	"context"
+)
+
+// WithContext returns a Consumer that works like c except that its
+// CanConsume method returns false once ctx is cancelled or its deadline
+// passes. Since ctx can be cancelled from another goroutine between
+// calls to CanConsume and Consume, the Consume method of returned
+// consumer quietly ignores values after ctx is done instead of
+// panicking.
+func WithContext(ctx context.Context, c Consumer) Consumer {
+	return &contextConsumer{Consumer: c, ctx: ctx}
+}
+
+type contextConsumer struct {
+	Consumer
+	ctx context.Context
+}
+
+func (c *contextConsumer) children() []interface{} {
+	return []interface{}{c.Consumer}
+}
+
+func (c *contextConsumer) CanConsume() bool {
+	return c.ctx.Err() == nil && c.Consumer.CanConsume()
+}
+
+func (c *contextConsumer) Consume(ptr interface{}) {
+	if c.ctx.Err() != nil {
+		return
+	}
+	c.Consumer.Consume(ptr)
+}
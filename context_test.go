@@ -0,0 +1,45 @@
+package consume_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithContext(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var result []int
+	consumer := consume.WithContext(ctx, consume.AppendTo(&result))
+	consume.FeedFunc(
+		func(i int) (interface{}, bool) {
+			if i == 3 {
+				cancel()
+			}
+			return &i, true
+		},
+		consumer)
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.False(consumer.CanConsume())
+	x := 7
+	consumer.Consume(&x)
+	assert.Equal([]int{0, 1, 2}, result)
+}
+
+func TestWithContextDeadline(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithDeadline(
+		context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	var result []int
+	consumer := consume.WithContext(ctx, consume.AppendTo(&result))
+	assert.False(consumer.CanConsume())
+	consumer = consume.WithContext(
+		context.Background(), consume.Slice(consume.AppendTo(&result), 0, 1))
+	feedInts(t, consumer)
+	assert.Equal([]int{0}, result)
+}
@@ -0,0 +1,47 @@
+package consume
+
+import (
+	"expvar"
+)
+
+// Counted returns a Consumer that passes the values it consumes onto c
+// and calls inc(1) for each one. The CanConsume method of the returned
+// consumer returns the same as c.CanConsume().
+func Counted(c Consumer, inc func(delta int)) Consumer {
+	return &countedConsumer{Consumer: c, inc: inc}
+}
+
+// ExpvarInc returns a function for Counted that adds to v.
+func ExpvarInc(v *expvar.Int) func(delta int) {
+	return func(delta int) {
+		v.Add(int64(delta))
+	}
+}
+
+// Adder is implemented by counters such as Prometheus counters.
+type Adder interface {
+	Add(delta float64)
+}
+
+// CounterInc returns a function for Counted that adds to counter. Use it
+// with Prometheus counters or anything else that implements Adder.
+func CounterInc(counter Adder) func(delta int) {
+	return func(delta int) {
+		counter.Add(float64(delta))
+	}
+}
+
+type countedConsumer struct {
+	Consumer
+	inc func(delta int)
+}
+
+func (c *countedConsumer) children() []interface{} {
+	return []interface{}{c.Consumer}
+}
+
+func (c *countedConsumer) Consume(ptr interface{}) {
+	MustCanConsume(c)
+	c.Consumer.Consume(ptr)
+	c.inc(1)
+}
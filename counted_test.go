@@ -0,0 +1,38 @@
+package consume_test
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountedExpvar(t *testing.T) {
+	assert := assert.New(t)
+	var counter expvar.Int
+	var ints []int
+	feedInts(t, consume.Counted(
+		consume.Slice(consume.AppendTo(&ints), 0, 5),
+		consume.ExpvarInc(&counter)))
+	assert.Equal(int64(5), counter.Value())
+}
+
+func TestCountedAdder(t *testing.T) {
+	assert := assert.New(t)
+	var counter floatCounter
+	feedInts(t, consume.Counted(
+		consume.Slice(consume.Nil(), 0, 3), consume.CounterInc(&counter)))
+	assert.Equal(floatCounter(0), counter)
+	var ints []int
+	feedInts(t, consume.Counted(
+		consume.Slice(consume.AppendTo(&ints), 0, 3),
+		consume.CounterInc(&counter)))
+	assert.Equal(floatCounter(3), counter)
+}
+
+type floatCounter float64
+
+func (f *floatCounter) Add(delta float64) {
+	*f += floatCounter(delta)
+}
@@ -0,0 +1,53 @@
+package consume
+
+// DeadLetterValue is a value that a sink permanently failed to consume
+// along with the error the sink reported.
+type DeadLetterValue struct {
+
+	// Value is a pointer to a copy of the value that failed.
+	Value interface{}
+
+	// Err is the error from the last attempt to consume Value.
+	Err error
+}
+
+// DeadLetter returns a Consumer that passes the values it consumes onto
+// primary. When primary fails to consume a value, the returned consumer
+// passes a *DeadLetterValue holding a copy of the value and the error
+// onto dlq instead. If dlq can't consume, the failed value is dropped.
+// Wrap primary with Retry first so that only values that fail
+// permanently go to dlq. The CanConsume method of the returned consumer
+// returns the same as primary.CanConsume().
+func DeadLetter(primary ErrConsumer, dlq Consumer) Consumer {
+	return &deadLetterConsumer{primary: primary, dlq: dlq}
+}
+
+type deadLetterConsumer struct {
+	primary ErrConsumer
+	dlq     Consumer
+}
+
+func (d *deadLetterConsumer) children() []interface{} {
+	return []interface{}{d.primary, d.dlq}
+}
+
+func (d *deadLetterConsumer) CanConsume() bool {
+	return d.primary.CanConsume()
+}
+
+func (d *deadLetterConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	if err := d.primary.Consume(ptr); err != nil {
+		deadLetter(d.dlq, ptr, err)
+	}
+}
+
+// deadLetter sends a copy of the value ptr points to along with err to
+// dlq. deadLetter returns err if dlq can't consume.
+func deadLetter(dlq Consumer, ptr interface{}, err error) error {
+	if !dlq.CanConsume() {
+		return err
+	}
+	dlq.Consume(&DeadLetterValue{Value: shallowCopy(ptr), Err: err})
+	return nil
+}
@@ -0,0 +1,201 @@
+package consume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// PipelineDescription describes one stage of a pipeline of consumers
+// along with the stages it passes values onto.
+type PipelineDescription struct {
+
+	// Name is the name of the function that created the stage such as
+	// "Slice" or "MapFilter". For consumers not from this package, Name
+	// is the Go type of the consumer.
+	Name string `json:"name"`
+
+	// Details describes how the stage is configured, e.g "start=0 end=10".
+	Details string `json:"details,omitempty"`
+
+	// Children describes the stages that this stage passes values onto.
+	Children []PipelineDescription `json:"children,omitempty"`
+}
+
+// Describe returns a description of the pipeline that c is the first
+// stage of. Describe knows about the consumers that this package
+// creates. It describes any other consumer as a single stage with no
+// children.
+func Describe(c Consumer) PipelineDescription {
+	return describeStage(c)
+}
+
+// describeStage works like Describe except that stage can also be an
+// ErrConsumer.
+func describeStage(stage interface{}) PipelineDescription {
+	name, details := stageNameAndDetails(stage)
+	result := PipelineDescription{Name: name, Details: details}
+	for _, child := range childStages(stage) {
+		result.Children = append(result.Children, describeStage(child))
+	}
+	return result
+}
+
+// stageNameAndDetails returns the name and details of stage as Describe
+// reports them.
+func stageNameAndDetails(stage interface{}) (name, details string) {
+	switch t := stage.(type) {
+	case *sliceConsumer:
+		return "Slice", fmt.Sprintf("start=%d end=%d", t.start, t.end)
+	case *stepSliceConsumer:
+		return "SliceStep",
+			fmt.Sprintf("start=%d end=%d step=%d", t.start, t.end, t.step)
+	case *sliceOverflowConsumer:
+		return "SliceWithOverflow",
+			fmt.Sprintf("start=%d end=%d", t.start, t.end)
+	case *relativeSliceConsumer:
+		return "SliceRelative", ""
+	case *sliceFromConsumer:
+		return "SliceFrom", fmt.Sprintf("skip=%d", t.skip)
+	case *pageConsumer:
+		return "Page", fmt.Sprintf("itemsPerPage=%d", t.itemsPerPage)
+	case *pageEConsumer:
+		return "PageE", fmt.Sprintf("itemsPerPage=%d", t.itemsPerPage)
+	case *pageOverflowConsumer:
+		return "PageWithOverflow", ""
+	case *mapFilterConsumer:
+		return "MapFilter", fmt.Sprintf("stages=%d", t.mapFilters.size())
+	case *takeWhileConsumer:
+		return "TakeWhile", fmt.Sprintf("stages=%d", t.mapFilters.size())
+	case *multiConsumer:
+		return "Compose", ""
+	case *appendConsumer:
+		if t.allocType != nil {
+			return "AppendPtrsTo", t.buffer.Type().String()
+		}
+		return "AppendTo", t.buffer.Type().String()
+	case *appendSaveMemoryConsumer:
+		return "AppendToSaveMemory", t.buffer.Type().String()
+	case nilConsumer:
+		return "Nil", ""
+	case ConsumerFunc:
+		return "ConsumerFunc", ""
+	case *countedConsumer:
+		return "Counted", ""
+	case *checkpointConsumer:
+		return "Checkpointed", fmt.Sprintf("every=%d", t.every)
+	case *tracedConsumer:
+		return "Traced", "name=" + t.name
+	case *stopOnPanicConsumer:
+		return "StopOnPanic", ""
+	case *pipelineStage:
+		return "Stage", ""
+	case *pipelineStageFinalizer:
+		return "StageFinalizer", ""
+	case *distinctKeyConsumer:
+		return "Distinct", ""
+	case *distinctAdjacentConsumer:
+		return "DistinctAdjacent", ""
+	case *distinctFuncConsumer:
+		return "DistinctFunc", ""
+	case *distinctHashConsumer:
+		return "DistinctHash", ""
+	case *rateLimitPerKeyConsumer:
+		return "RateLimitPerKey", fmt.Sprintf("per=%v", t.per)
+	case *throttleConsumer:
+		return "Throttle",
+			fmt.Sprintf("maxPerWindow=%d window=%v", t.maxPerWindow, t.window)
+	case *windowConsumer:
+		return "WindowedAggregate",
+			fmt.Sprintf("window=%v slide=%v", t.window, t.slide)
+	case *priorityMergeConsumer:
+		return "PriorityMerge", fmt.Sprintf("bufferSize=%d", t.bufferSize)
+	case *reorderConsumer:
+		return "Reorder", fmt.Sprintf("maxOutOfOrder=%d", t.maxOutOfOrder)
+	case *contextConsumer:
+		return "WithContext", ""
+	case *timedConsumer:
+		return "Timed", ""
+	case *profiledConsumer:
+		return "Profiled", fmt.Sprintf("sampleEvery=%d", t.sampleEvery)
+	case *dryRunSinkConsumer:
+		return "DryRunSink", ""
+	case *deadLetterConsumer:
+		return "DeadLetter", ""
+	case *parallelMapFilterConsumer:
+		return "MapFilterParallel", ""
+	case *orderedParallelMapFilterConsumer:
+		return "MapFilterParallelOrdered", ""
+	default:
+		return fmt.Sprintf("%T", stage), ""
+	}
+}
+
+// parentStage is implemented by the consumers of this package that pass
+// values onto other consumers or ErrConsumers.
+type parentStage interface {
+
+	// children returns the consumers or ErrConsumers that this stage
+	// passes values onto. Elements may be nil.
+	children() []interface{}
+}
+
+// asStages returns consumers as a slice of stages for children methods.
+func asStages(consumers []Consumer) []interface{} {
+	result := make([]interface{}, len(consumers))
+	for i, consumer := range consumers {
+		result[i] = consumer
+	}
+	return result
+}
+
+// childStages returns the non nil consumers or ErrConsumers that stage
+// passes values onto. Describe, Run, Bridge, Drain, and CheckHealth all
+// walk pipelines with childStages.
+func childStages(stage interface{}) []interface{} {
+	p, ok := stage.(parentStage)
+	if !ok {
+		return nil
+	}
+	var result []interface{}
+	for _, child := range p.children() {
+		if child != nil {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// JSON renders this description as indented JSON.
+func (d PipelineDescription) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// Graphviz renders this description as a Graphviz digraph in the DOT
+// language. Each stage is a node with an edge to each of its children.
+func (d PipelineDescription) Graphviz() string {
+	var buffer bytes.Buffer
+	buffer.WriteString("digraph pipeline {\n")
+	nextID := 0
+	d.writeGraphviz(&buffer, &nextID)
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+// writeGraphviz writes the node for d and its children to buffer and
+// returns the id of the node for d.
+func (d PipelineDescription) writeGraphviz(
+	buffer *bytes.Buffer, nextID *int) int {
+	id := *nextID
+	*nextID++
+	label := d.Name
+	if d.Details != "" {
+		label += "\n" + d.Details
+	}
+	fmt.Fprintf(buffer, "  n%d [label=%q];\n", id, label)
+	for _, child := range d.Children {
+		childID := child.writeGraphviz(buffer, nextID)
+		fmt.Fprintf(buffer, "  n%d -> n%d;\n", id, childID)
+	}
+	return id
+}
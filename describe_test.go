@@ -0,0 +1,97 @@
+package consume_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	assert := assert.New(t)
+	var evens []int
+	var all []string
+	consumer := consume.Compose(
+		consume.MapFilter(
+			consume.Slice(consume.AppendTo(&evens), 0, 5),
+			func(ptr *int) bool { return (*ptr)%2 == 0 }),
+		consume.AppendTo(&all),
+		customConsumer{})
+	description := consume.Describe(consumer)
+	assert.Equal(
+		consume.PipelineDescription{
+			Name: "Compose",
+			Children: []consume.PipelineDescription{
+				{
+					Name:    "MapFilter",
+					Details: "stages=1",
+					Children: []consume.PipelineDescription{
+						{
+							Name:    "Slice",
+							Details: "start=0 end=5",
+							Children: []consume.PipelineDescription{
+								{Name: "AppendTo", Details: "[]int"},
+							},
+						},
+					},
+				},
+				{Name: "AppendTo", Details: "[]string"},
+				{Name: "consume_test.customConsumer"},
+			},
+		},
+		description)
+	encoded, err := description.JSON()
+	assert.NoError(err)
+	var decoded consume.PipelineDescription
+	assert.NoError(json.Unmarshal(encoded, &decoded))
+	assert.Equal(description, decoded)
+}
+
+func TestDescribeWrappers(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.WithContext(
+		context.Background(),
+		consume.Timed(
+			consume.AppendTo(&ints), func(d time.Duration) {}))
+	assert.Equal(
+		consume.PipelineDescription{
+			Name: "WithContext",
+			Children: []consume.PipelineDescription{
+				{
+					Name: "Timed",
+					Children: []consume.PipelineDescription{
+						{Name: "AppendTo", Details: "[]int"},
+					},
+				},
+			},
+		},
+		consume.Describe(consumer))
+}
+
+func TestDescribeGraphviz(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	description := consume.Describe(
+		consume.Slice(consume.AppendTo(&ints), 1, 3))
+	assert.Equal(
+		"digraph pipeline {\n"+
+			"  n0 [label=\"Slice\\nstart=1 end=3\"];\n"+
+			"  n1 [label=\"AppendTo\\n[]int\"];\n"+
+			"  n0 -> n1;\n"+
+			"}\n",
+		description.Graphviz())
+}
+
+type customConsumer struct {
+}
+
+func (c customConsumer) CanConsume() bool {
+	return true
+}
+
+func (c customConsumer) Consume(ptr interface{}) {
+}
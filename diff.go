@@ -0,0 +1,99 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// DiffKind describes how a consumed value differs from the expected value.
+type DiffKind int
+
+const (
+
+	// DiffMismatch means that the consumed value does not equal the
+	// expected value.
+	DiffMismatch DiffKind = iota
+
+	// DiffMissing means that an expected value was never consumed.
+	DiffMissing
+
+	// DiffExtra means that a value was consumed after the expected values
+	// ran out.
+	DiffExtra
+)
+
+// DiffEntry describes a single difference that Diff found.
+type DiffEntry struct {
+	Kind DiffKind
+
+	// Index is the zero based position of the value in the stream.
+	Index int
+
+	// Expected points to the expected value. nil if Kind is DiffExtra.
+	Expected interface{}
+
+	// Actual points to the consumed value. nil if Kind is DiffMissing.
+	Actual interface{}
+}
+
+// Diff returns a ConsumeFinalizer that compares the values it consumes
+// element by element against the values that expectedFeeder produces.
+// Values are compared with reflect.DeepEqual. Diff calls report once for
+// each difference that it finds. Because missing values can only be
+// detected once there is nothing more to consume, Finalize reports any
+// values that expectedFeeder still has. The pointers in a DiffEntry are
+// valid only during the call to report.
+func Diff(
+	expectedFeeder Producer, report func(DiffEntry)) ConsumeFinalizer {
+	return &diffConsumer{expected: expectedFeeder, report: report}
+}
+
+type diffConsumer struct {
+	expected  Producer
+	report    func(DiffEntry)
+	index     int
+	exhausted bool
+	finalized bool
+}
+
+func (d *diffConsumer) CanConsume() bool {
+	return !d.finalized
+}
+
+func (d *diffConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	expected := d.next()
+	if expected == nil {
+		d.report(DiffEntry{Kind: DiffExtra, Index: d.index, Actual: ptr})
+	} else if !reflect.DeepEqual(expected, ptr) {
+		d.report(DiffEntry{
+			Kind:     DiffMismatch,
+			Index:    d.index,
+			Expected: expected,
+			Actual:   ptr,
+		})
+	}
+	d.index++
+}
+
+func (d *diffConsumer) Finalize() {
+	if d.finalized {
+		return
+	}
+	d.finalized = true
+	for expected := d.next(); expected != nil; expected = d.next() {
+		d.report(DiffEntry{
+			Kind: DiffMissing, Index: d.index, Expected: expected})
+		d.index++
+	}
+}
+
+func (d *diffConsumer) next() interface{} {
+	if d.exhausted {
+		return nil
+	}
+	result := d.expected.Produce()
+	if result == nil {
+		d.exhausted = true
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package consume_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+	var entries []string
+	cf := consume.Diff(
+		intProducer(0, 1, 7, 3, 4, 5),
+		func(entry consume.DiffEntry) {
+			entries = append(entries, describeDiffEntry(entry))
+		})
+	feedInts(t, consume.Slice(cf, 0, 4))
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Equal(
+		[]string{"mismatch 2 7 2", "missing 4 4 -", "missing 5 5 -"},
+		entries)
+}
+
+func TestDiffExtra(t *testing.T) {
+	assert := assert.New(t)
+	var entries []string
+	cf := consume.Diff(
+		intProducer(0, 1),
+		func(entry consume.DiffEntry) {
+			entries = append(entries, describeDiffEntry(entry))
+		})
+	feedInts(t, consume.Slice(cf, 0, 3))
+	cf.Finalize()
+	assert.Equal([]string{"extra 2 - 2"}, entries)
+}
+
+func TestDiffSame(t *testing.T) {
+	assert := assert.New(t)
+	var entries []string
+	cf := consume.Diff(
+		intProducer(0, 1, 2),
+		func(entry consume.DiffEntry) {
+			entries = append(entries, describeDiffEntry(entry))
+		})
+	feedInts(t, consume.Slice(cf, 0, 3))
+	cf.Finalize()
+	assert.Empty(entries)
+}
+
+func describeDiffEntry(entry consume.DiffEntry) string {
+	var kind string
+	switch entry.Kind {
+	case consume.DiffMismatch:
+		kind = "mismatch"
+	case consume.DiffMissing:
+		kind = "missing"
+	case consume.DiffExtra:
+		kind = "extra"
+	}
+	return fmt.Sprintf(
+		"%s %d %s %s",
+		kind,
+		entry.Index,
+		describeIntPtr(entry.Expected),
+		describeIntPtr(entry.Actual))
+}
+
+func describeIntPtr(ptr interface{}) string {
+	if ptr == nil {
+		return "-"
+	}
+	return strconv.Itoa(*ptr.(*int))
+}
+
+// intProducer returns a Producer that produces values.
+func intProducer(values ...int) consume.Producer {
+	index := 0
+	var current int
+	return consume.ProducerFunc(func() interface{} {
+		if index == len(values) {
+			return nil
+		}
+		current = values[index]
+		index++
+		return &current
+	})
+}
@@ -0,0 +1,99 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// DiffKeys returns a ConsumeFinalizer that compares the keys of the values
+// it consumes against existingKeys. existingKeys is a slice of keys or a
+// map whose keys are the existing keys. keyFunc takes a pointer to a
+// consumed value and returns its key, e.g func(ptr *Person) int64.
+// keyFunc can also be a func(ptr interface{}) interface{}. Keys that
+// keyFunc returns must be of the same type as the existing keys.
+//
+// The returned consumer passes each consumed value whose key is not an
+// existing key onto added and ignores the values with existing keys. When
+// caller calls Finalize, it passes a pointer to each existing key that no
+// consumed value had onto removed. If existingKeys is a slice, keys go
+// to removed in the order they appear in the slice; if it is a map, they
+// go in no particular order. DiffKeys panics if existingKeys is not a
+// slice or map or if keyFunc returns keys that don't support equality.
+func DiffKeys(
+	existingKeys interface{},
+	keyFunc interface{},
+	added, removed Consumer) ConsumeFinalizer {
+	keysValue := reflect.ValueOf(existingKeys)
+	var keys []reflect.Value
+	switch keysValue.Kind() {
+	case reflect.Slice:
+		length := keysValue.Len()
+		keys = make([]reflect.Value, length)
+		for i := range keys {
+			keys[i] = keysValue.Index(i)
+		}
+	case reflect.Map:
+		keys = keysValue.MapKeys()
+	default:
+		panic("existingKeys must be a slice or map")
+	}
+	seen := make(map[interface{}]bool, len(keys))
+	for _, key := range keys {
+		seen[key.Interface()] = false
+	}
+	return &diffKeysConsumer{
+		keys:    keys,
+		seen:    seen,
+		keyFunc: newMapKeyFunc(keyFunc),
+		added:   added,
+		removed: removed,
+	}
+}
+
+type diffKeysConsumer struct {
+	keys      []reflect.Value
+	seen      map[interface{}]bool
+	keyFunc   keyFunc
+	added     Consumer
+	removed   Consumer
+	finalized bool
+}
+
+func (d *diffKeysConsumer) children() []interface{} {
+	return []interface{}{d.added, d.removed}
+}
+
+func (d *diffKeysConsumer) CanConsume() bool {
+	return !d.finalized
+}
+
+func (d *diffKeysConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	key := d.keyFunc.key(ptr)
+	if _, ok := d.seen[key]; ok {
+		d.seen[key] = true
+		return
+	}
+	if d.added.CanConsume() {
+		d.added.Consume(ptr)
+	}
+}
+
+func (d *diffKeysConsumer) Finalize() {
+	if d.finalized {
+		return
+	}
+	d.finalized = true
+	for _, key := range d.keys {
+		if !d.removed.CanConsume() {
+			break
+		}
+		if d.seen[key.Interface()] {
+			continue
+		}
+		keyPtr := reflect.New(key.Type())
+		keyPtr.Elem().Set(key)
+		d.removed.Consume(keyPtr.Interface())
+	}
+	d.keys = nil
+	d.seen = nil
+}
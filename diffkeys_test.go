@@ -0,0 +1,53 @@
+package consume_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffKeys(t *testing.T) {
+	assert := assert.New(t)
+	var added []person
+	var removed []string
+	cf := consume.DiffKeys(
+		[]string{"Matt", "Nobody", "Mark", "Ghost"},
+		func(ptr *person) string { return ptr.Name },
+		consume.AppendTo(&added),
+		consume.AppendTo(&removed))
+	for i := range people {
+		cf.Consume(&people[i])
+	}
+	assert.Empty(removed)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal(
+		[]person{people[stoney], people[dillon], people[beth]}, added)
+	assert.Equal([]string{"Nobody", "Ghost"}, removed)
+	assert.False(cf.CanConsume())
+}
+
+func TestDiffKeysMap(t *testing.T) {
+	assert := assert.New(t)
+	var added, removed []int
+	cf := consume.DiffKeys(
+		map[int]bool{1: true, 2: true, 3: true, 20: true},
+		func(ptr interface{}) interface{} { return *ptr.(*int) },
+		consume.AppendTo(&added),
+		consume.AppendTo(&removed))
+	feedInts(t, consume.Slice(cf, 0, 5))
+	cf.Finalize()
+	sort.Ints(removed)
+	assert.Equal([]int{0, 4}, added)
+	assert.Equal([]int{20}, removed)
+}
+
+func TestDiffKeysPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.DiffKeys(
+			3, func(ptr *int) int { return 0 }, consume.Nil(), consume.Nil())
+	})
+}
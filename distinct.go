@@ -0,0 +1,184 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// Hasher hashes values and compares them for equality.
+type Hasher interface {
+
+	// Hash returns the hash of the value ptr points to. Equal values
+	// must have equal hashes.
+	Hash(ptr interface{}) uint64
+
+	// Equal returns true if the values that a and b point to are equal.
+	Equal(a, b interface{}) bool
+}
+
+// DistinctFunc returns a Consumer that passes only the values it has not
+// seen before onto consumer. DistinctFunc uses equal to compare values.
+// equal takes pointers to the two values being compared. DistinctFunc
+// is for values that can't be map keys such as structs containing slices.
+// The returned consumer keeps a shallow copy of each value passed onto
+// consumer and compares each consumed value against all of them, so
+// consuming N values takes O(N^2) time. When values can be hashed, use
+// DistinctHash instead. The CanConsume method of returned consumer returns
+// the same as consumer.CanConsume().
+func DistinctFunc(
+	consumer Consumer, equal func(a, b interface{}) bool) Consumer {
+	return &distinctFuncConsumer{Consumer: consumer, equal: equal}
+}
+
+// DistinctHash works like DistinctFunc except that it uses hasher to
+// compare values. Consuming N values takes O(N) time on average.
+func DistinctHash(consumer Consumer, hasher Hasher) Consumer {
+	return &distinctHashConsumer{
+		Consumer: consumer,
+		hasher:   hasher,
+		seen:     make(map[uint64][]interface{}),
+	}
+}
+
+// Distinct returns a Consumer that passes only the values whose key it
+// has not seen before onto consumer. keyFunc is of the form
+// func(ptr *T) K where K can be a map key, or it is a
+// func(ptr interface{}) interface{} returning such keys. The returned
+// consumer remembers each key it has seen. The CanConsume method of
+// returned consumer returns the same as consumer.CanConsume(). Distinct
+// panics if keyFunc is not of the right form.
+func Distinct(consumer Consumer, keyFunc interface{}) Consumer {
+	return &distinctKeyConsumer{
+		Consumer: consumer,
+		keyFunc:  newMapKeyFunc(keyFunc),
+		seen:     make(map[interface{}]struct{}),
+	}
+}
+
+// DistinctAdjacent returns a Consumer that drops each value whose key
+// equals the key of the value consumed just before it and passes the
+// rest onto consumer. For sorted input, the returned consumer passes on
+// each distinct value once while using O(1) memory. keyFunc is of the
+// same form as in Distinct. If keyFunc is nil, values are their own keys.
+// Keys are compared with reflect.DeepEqual. When keyFunc is nil, the
+// returned consumer keeps a shallow copy of the previous value. The
+// CanConsume method of returned consumer returns the same as
+// consumer.CanConsume(). DistinctAdjacent panics if keyFunc is non nil
+// and not of the right form.
+func DistinctAdjacent(consumer Consumer, keyFunc interface{}) Consumer {
+	result := &distinctAdjacentConsumer{Consumer: consumer}
+	if keyFunc != nil {
+		kf := newKeyFunc(keyFunc)
+		result.keyFunc = &kf
+	}
+	return result
+}
+
+type distinctAdjacentConsumer struct {
+	Consumer
+	keyFunc *keyFunc
+	prev    interface{}
+	started bool
+}
+
+func (d *distinctAdjacentConsumer) children() []interface{} {
+	return []interface{}{d.Consumer}
+}
+
+func (d *distinctAdjacentConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	var key interface{}
+	if d.keyFunc != nil {
+		key = d.keyFunc.key(ptr)
+	} else if env, ok := ptr.(*Envelope); ok {
+		key = reflect.ValueOf(env.Value).Elem().Interface()
+	} else {
+		key = reflect.ValueOf(ptr).Elem().Interface()
+	}
+	if d.started && reflect.DeepEqual(d.prev, key) {
+		return
+	}
+	d.prev = key
+	d.started = true
+	d.Consumer.Consume(ptr)
+}
+
+type distinctKeyConsumer struct {
+	Consumer
+	keyFunc keyFunc
+	seen    map[interface{}]struct{}
+}
+
+func (d *distinctKeyConsumer) children() []interface{} {
+	return []interface{}{d.Consumer}
+}
+
+func (d *distinctKeyConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	key := d.keyFunc.key(ptr)
+	if _, ok := d.seen[key]; ok {
+		return
+	}
+	d.seen[key] = struct{}{}
+	d.Consumer.Consume(ptr)
+}
+
+type distinctFuncConsumer struct {
+	Consumer
+	equal func(a, b interface{}) bool
+	seen  []interface{}
+}
+
+func (d *distinctFuncConsumer) children() []interface{} {
+	return []interface{}{d.Consumer}
+}
+
+func (d *distinctFuncConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	if containsEqual(d.seen, ptr, d.equal) {
+		return
+	}
+	d.seen = append(d.seen, shallowCopy(ptr))
+	d.Consumer.Consume(ptr)
+}
+
+type distinctHashConsumer struct {
+	Consumer
+	hasher Hasher
+	seen   map[uint64][]interface{}
+}
+
+func (d *distinctHashConsumer) children() []interface{} {
+	return []interface{}{d.Consumer}
+}
+
+func (d *distinctHashConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	hash := d.hasher.Hash(ptr)
+	bucket := d.seen[hash]
+	if containsEqual(bucket, ptr, d.hasher.Equal) {
+		return
+	}
+	d.seen[hash] = append(bucket, shallowCopy(ptr))
+	d.Consumer.Consume(ptr)
+}
+
+func containsEqual(
+	ptrs []interface{},
+	ptr interface{},
+	equal func(a, b interface{}) bool) bool {
+	for _, p := range ptrs {
+		if equal(p, ptr) {
+			return true
+		}
+	}
+	return false
+}
+
+// shallowCopy returns a pointer to a newly allocated copy of the value
+// ptr points to.
+func shallowCopy(ptr interface{}) interface{} {
+	value := reflect.ValueOf(ptr).Elem()
+	result := reflect.New(value.Type())
+	result.Elem().Set(value)
+	return result.Interface()
+}
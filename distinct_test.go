@@ -0,0 +1,141 @@
+package consume_test
+
+import (
+	"hash/fnv"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+type tagged struct {
+	Name string
+	Tags []string
+}
+
+var taggedValues = []tagged{
+	{Name: "a", Tags: []string{"x"}},
+	{Name: "a", Tags: []string{"x", "y"}},
+	{Name: "A", Tags: []string{"X"}},
+	{Name: "a", Tags: []string{"x", "y"}},
+	{Name: "b"},
+}
+
+func TestDistinctFunc(t *testing.T) {
+	assert := assert.New(t)
+	var result []tagged
+	consumer := consume.DistinctFunc(
+		consume.AppendTo(&result),
+		func(a, b interface{}) bool { return reflect.DeepEqual(a, b) })
+	feedTagged(consumer)
+	assert.Equal(
+		[]tagged{taggedValues[0], taggedValues[1], taggedValues[2],
+			taggedValues[4]},
+		result)
+}
+
+func TestDistinctHash(t *testing.T) {
+	assert := assert.New(t)
+	var result []tagged
+	feedTagged(consume.DistinctHash(
+		consume.Slice(consume.AppendTo(&result), 0, 2),
+		caseInsensitiveHasher{}))
+	assert.Equal([]tagged{taggedValues[0], taggedValues[1]}, result)
+}
+
+func TestDistinct(t *testing.T) {
+	assert := assert.New(t)
+	var result []tagged
+	feedTagged(consume.Distinct(
+		consume.AppendTo(&result),
+		func(ptr *tagged) string { return strings.ToLower(ptr.Name) }))
+	assert.Equal([]tagged{taggedValues[0], taggedValues[4]}, result)
+}
+
+func TestDistinctRawKeyFunc(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := consume.Distinct(
+		consume.Slice(consume.AppendTo(&result), 0, 3),
+		func(ptr interface{}) interface{} { return *ptr.(*int) % 4 })
+	consume.FeedRange(0, 20, 1, consumer)
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.False(consumer.CanConsume())
+}
+
+func TestDistinctPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.Distinct(consume.Nil(), 3)
+	})
+	assert.Panics(func() {
+		consume.Distinct(consume.Nil(), func(x int) int { return x })
+	})
+	assert.Panics(func() {
+		consume.Distinct(
+			consume.Nil(), func(ptr *tagged) []string { return ptr.Tags })
+	})
+}
+
+func TestDistinctAdjacent(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consume.FeedSlice(
+		[]int{1, 1, 2, 2, 2, 3, 1, 1},
+		consume.DistinctAdjacent(consume.AppendTo(&result), nil))
+	assert.Equal([]int{1, 2, 3, 1}, result)
+}
+
+func TestDistinctAdjacentKey(t *testing.T) {
+	assert := assert.New(t)
+	var result []tagged
+	feedTagged(consume.DistinctAdjacent(
+		consume.Slice(consume.AppendTo(&result), 0, 2),
+		func(ptr *tagged) string { return strings.ToLower(ptr.Name) }))
+	assert.Equal([]tagged{taggedValues[0], taggedValues[4]}, result)
+}
+
+func TestDistinctAdjacentNoKeyUncomparable(t *testing.T) {
+	assert := assert.New(t)
+	var result []tagged
+	feedTagged(consume.DistinctAdjacent(consume.AppendTo(&result), nil))
+	assert.Equal(
+		[]tagged{taggedValues[0], taggedValues[1], taggedValues[2],
+			taggedValues[3], taggedValues[4]},
+		result)
+}
+
+func feedTagged(consumer consume.Consumer) {
+	for i := range taggedValues {
+		if !consumer.CanConsume() {
+			return
+		}
+		// Pass a copy to ensure consumer does not hold onto ptr
+		value := taggedValues[i]
+		consumer.Consume(&value)
+		value.Name = "changed"
+	}
+}
+
+// caseInsensitiveHasher treats tagged values as equal if they differ only
+// by case.
+type caseInsensitiveHasher struct {
+}
+
+func (c caseInsensitiveHasher) Hash(ptr interface{}) uint64 {
+	h := fnv.New64()
+	io.WriteString(h, c.normalize(ptr))
+	return h.Sum64()
+}
+
+func (c caseInsensitiveHasher) Equal(a, b interface{}) bool {
+	return c.normalize(a) == c.normalize(b)
+}
+
+func (c caseInsensitiveHasher) normalize(ptr interface{}) string {
+	p := ptr.(*tagged)
+	return strings.ToLower(p.Name + "|" + strings.Join(p.Tags, ","))
+}
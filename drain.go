@@ -0,0 +1,107 @@
+package consume
+
+import (
+	"fmt"
+	"time"
+)
+
+// DrainError is the error Drain returns when finalizing takes longer than
+// the timeout.
+type DrainError struct {
+
+	// Dropped is the number of values that were dropped instead of
+	// processed or -1 if the consumer can't drop values.
+	Dropped int
+}
+
+func (d *DrainError) Error() string {
+	if d.Dropped < 0 {
+		return "consume: drain timed out"
+	}
+	return fmt.Sprintf(
+		"consume: drain timed out; %d values dropped", d.Dropped)
+}
+
+// Drain finalizes cf waiting up to timeout for cf to finish processing
+// the values it has already consumed. Drain is for consumers such as
+// MapFilterParallel that process values asynchronously. If cf finishes
+// in time, Drain returns nil. Otherwise, Drain forces every consumer
+// from MapFilterParallel or MapFilterParallelOrdered in the pipeline
+// that cf is the first stage of to drop the values it has not started
+// processing. Drain finds these consumers the same way Describe does, so
+// it looks through stages such as Compose, MapFilter, or Pipeline
+// stages but not through consumers from other packages. Drain then waits
+// for the values already in progress and returns a *DrainError
+// reporting how many values were dropped. If the pipeline has no such
+// consumers, Drain returns a *DrainError with Dropped set to -1 right
+// away. In that case, the goroutine finalizing cf keeps running in the
+// background until Finalize returns, and it leaks if Finalize never
+// returns. If Finalize panics while Drain waits for it, Drain panics
+// with the same value.
+func Drain(cf ConsumeFinalizer, timeout time.Duration) error {
+	done := make(chan struct{})
+	var finalizePanic interface{}
+	go func() {
+		defer close(done)
+		defer func() {
+			finalizePanic = recover()
+		}()
+		cf.Finalize()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		rePanic(finalizePanic)
+		return nil
+	case <-timer.C:
+	}
+	droppers := pendingDroppers(cf, nil)
+	if len(droppers) == 0 {
+		return &DrainError{Dropped: -1}
+	}
+	for _, d := range droppers {
+		d.dropPending()
+	}
+	<-done
+	rePanic(finalizePanic)
+	dropped := 0
+	for _, d := range droppers {
+		dropped += d.dropped()
+	}
+	return &DrainError{Dropped: dropped}
+}
+
+// rePanic panics with value if value is not nil.
+func rePanic(value interface{}) {
+	if value != nil {
+		panic(value)
+	}
+}
+
+// pendingDroppers appends the pendingDroppers in the pipeline that stage
+// is the first stage of to result and returns result.
+func pendingDroppers(
+	stage interface{}, result []pendingDropper) []pendingDropper {
+	if d, ok := stage.(pendingDropper); ok {
+		result = append(result, d)
+	}
+	for _, child := range childStages(stage) {
+		result = pendingDroppers(child, result)
+	}
+	return result
+}
+
+// pendingDropper is implemented by consumers that process values
+// asynchronously and can drop the values they have not started
+// processing.
+type pendingDropper interface {
+
+	// dropPending makes the consumer drop the values it has not started
+	// processing from now on. dropPending is safe to call while the
+	// consumer is finalizing.
+	dropPending()
+
+	// dropped returns how many values the consumer dropped.
+	dropped() int
+}
@@ -0,0 +1,83 @@
+package consume_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrain(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	cf := consume.MapFilterParallel(consume.AppendTo(&result), 2)
+	consume.FeedRange(0, 5, 1, cf)
+	assert.NoError(consume.Drain(cf, time.Minute))
+	assert.Len(result, 5)
+}
+
+func TestDrainTimeout(t *testing.T) {
+	assert := assert.New(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var result []int
+	cf := consume.MapFilterParallelOrdered(
+		consume.AppendTo(&result),
+		1,
+		func(ptr *int) bool {
+			if *ptr == 0 {
+				close(started)
+				<-release
+			}
+			return true
+		})
+	consume.FeedRange(0, 2, 1, cf)
+	<-started
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	err := consume.Drain(cf, time.Millisecond)
+	assert.Equal(&consume.DrainError{Dropped: 1}, err)
+	assert.EqualError(err, "consume: drain timed out; 1 values dropped")
+	assert.Equal([]int{0}, result)
+}
+
+func TestDrainNotDroppable(t *testing.T) {
+	assert := assert.New(t)
+	release := make(chan struct{})
+	defer close(release)
+	cf := &finalizer{
+		Consumer: consume.Nil(),
+		finalize: func() { <-release },
+	}
+	err := consume.Drain(cf, time.Millisecond)
+	assert.EqualError(err, "consume: drain timed out")
+}
+
+func TestDrainWrapped(t *testing.T) {
+	assert := assert.New(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var result []int
+	cf := consume.NewPipeline().StageFinalizer(consume.MapFilterParallelOrdered(
+		consume.AppendTo(&result),
+		1,
+		func(ptr *int) bool {
+			if *ptr == 0 {
+				close(started)
+				<-release
+			}
+			return true
+		}))
+	consume.FeedRange(0, 2, 1, cf)
+	<-started
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+	err := consume.Drain(cf, time.Millisecond)
+	assert.Equal(&consume.DrainError{Dropped: 1}, err)
+	assert.Equal([]int{0}, result)
+}
@@ -0,0 +1,136 @@
+package consume
+
+// DryRunSink reports on one sink of a pipeline under DryRun.
+type DryRunSink struct {
+
+	// Path locates the sink in the pipeline. It holds the index of the
+	// child taken at each stage with children, starting from the first
+	// stage. Stages with one child count that child as index 0. Path is
+	// empty if the whole pipeline is the sink.
+	Path []int
+
+	// Name is the name of the sink as Describe would report it.
+	Name string
+
+	// Count is the number of values that reached the sink.
+	Count int
+}
+
+// DryRunReport reports how many values would have reached each sink of
+// a pipeline.
+type DryRunReport struct {
+
+	// Sinks lists the sinks in depth first order.
+	Sinks []DryRunSink
+}
+
+// DryRun returns a copy of the pipeline that c is the first stage of
+// where every sink is replaced with a counter so that feeding values to
+// the copy has no side effects. The copy runs all the filters and
+// mappers of the original. The returned report tracks how many values
+// reach each sink. The counters can always consume, so the copy stops
+// consuming only when its own stages say so, e.g. a Slice is full.
+//
+// DryRun knows about the consumers that Slice, SliceStep,
+// SliceWithOverflow, MapFilter, TakeWhile, Compose, Distinct,
+// DistinctAdjacent, DistinctFunc, DistinctHash, StopOnPanic, WithContext,
+// and Pipeline.Stage return. It drops the stages from Counted, Traced, Timed,
+// Profiled, and Checkpointed since they have side effects of their own.
+// DryRun treats any other consumer as a sink. The stages in the copy
+// start fresh, so for instance a Slice in the copy starts counting from
+// zero. DryRun leaves c unchanged.
+func DryRun(c Consumer) (Consumer, *DryRunReport) {
+	report := &DryRunReport{}
+	return report.copyOf(c, nil), report
+}
+
+func (d *DryRunReport) copyOf(c Consumer, path []int) Consumer {
+	child := func(index int, c Consumer) Consumer {
+		childPath := make([]int, len(path)+1)
+		copy(childPath, path)
+		childPath[len(path)] = index
+		return d.copyOf(c, childPath)
+	}
+	switch t := c.(type) {
+	case *sliceConsumer:
+		return &sliceConsumer{
+			consumer: child(0, t.consumer), start: t.start, end: t.end}
+	case *stepSliceConsumer:
+		return &stepSliceConsumer{
+			consumer: child(0, t.consumer),
+			start:    t.start,
+			end:      t.end,
+			step:     t.step,
+		}
+	case *sliceOverflowConsumer:
+		return &sliceOverflowConsumer{
+			consumer: child(0, t.consumer),
+			start:    t.start,
+			end:      t.end,
+			overflow: child(1, t.overflow),
+		}
+	case *mapFilterConsumer:
+		return &mapFilterConsumer{
+			Consumer:   child(0, t.Consumer),
+			mapFilters: t.mapFilters,
+			ctx:        t.mapFilters.NewContext(),
+		}
+	case *takeWhileConsumer:
+		return &takeWhileConsumer{
+			consumer:   child(0, t.consumer),
+			mapFilters: t.mapFilters,
+			ctx:        t.mapFilters.NewContext(),
+		}
+	case *multiConsumer:
+		consumers := make([]Consumer, len(t.all))
+		for i, consumer := range t.all {
+			consumers[i] = child(i, consumer)
+		}
+		return Compose(consumers...)
+	case *distinctKeyConsumer:
+		return &distinctKeyConsumer{
+			Consumer: child(0, t.Consumer),
+			keyFunc:  t.keyFunc,
+			seen:     make(map[interface{}]struct{}),
+		}
+	case *distinctAdjacentConsumer:
+		return &distinctAdjacentConsumer{
+			Consumer: child(0, t.Consumer), keyFunc: t.keyFunc}
+	case *distinctFuncConsumer:
+		return DistinctFunc(child(0, t.Consumer), t.equal)
+	case *distinctHashConsumer:
+		return DistinctHash(child(0, t.Consumer), t.hasher)
+	case *stopOnPanicConsumer:
+		return StopOnPanic(child(0, t.consumer))
+	case *contextConsumer:
+		return WithContext(t.ctx, child(0, t.Consumer))
+	case *pipelineStage:
+		return t.pipeline.Stage(child(0, t.Consumer))
+	case *countedConsumer:
+		return child(0, t.Consumer)
+	case *tracedConsumer:
+		return child(0, t.Consumer)
+	case *timedConsumer:
+		return child(0, t.Consumer)
+	case *profiledConsumer:
+		return child(0, t.Consumer)
+	case *checkpointConsumer:
+		return child(0, t.Consumer)
+	}
+	d.Sinks = append(
+		d.Sinks, DryRunSink{Path: path, Name: Describe(c).Name})
+	return &dryRunSinkConsumer{report: d, index: len(d.Sinks) - 1}
+}
+
+type dryRunSinkConsumer struct {
+	report *DryRunReport
+	index  int
+}
+
+func (d *dryRunSinkConsumer) CanConsume() bool {
+	return true
+}
+
+func (d *dryRunSinkConsumer) Consume(ptr interface{}) {
+	d.report.Sinks[d.index].Count++
+}
@@ -0,0 +1,64 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	assert := assert.New(t)
+	var evens, firstThree []int
+	var counted int
+	consumer := consume.Compose(
+		consume.MapFilter(
+			consume.AppendTo(&evens),
+			func(ptr *int) bool { return *ptr%2 == 0 }),
+		consume.Counted(
+			consume.Slice(consume.AppendTo(&firstThree), 0, 3),
+			func(delta int) { counted += delta }))
+	dryRun, report := consume.DryRun(consumer)
+	consume.FeedRange(0, 10, 1, dryRun)
+	assert.Empty(evens)
+	assert.Empty(firstThree)
+	assert.Zero(counted)
+	assert.Equal(
+		[]consume.DryRunSink{
+			{Path: []int{0, 0}, Name: "AppendTo", Count: 5},
+			{Path: []int{1, 0, 0}, Name: "AppendTo", Count: 3},
+		},
+		report.Sinks)
+
+	// The original pipeline still works
+	consume.FeedRange(0, 10, 1, consumer)
+	assert.Equal([]int{0, 2, 4, 6, 8}, evens)
+	assert.Equal([]int{0, 1, 2}, firstThree)
+}
+
+func TestDryRunStagesStartFresh(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := consume.SliceWithOverflow(
+		consume.AppendTo(&result), 0, 2, consume.Nil())
+	consume.FeedRange(0, 2, 1, consumer)
+	dryRun, report := consume.DryRun(consumer)
+	consume.FeedRange(0, 5, 1, dryRun)
+	assert.Equal([]int{0, 1}, result)
+	assert.Equal(
+		[]consume.DryRunSink{
+			{Path: []int{0}, Name: "AppendTo", Count: 2},
+			{Path: []int{1}, Name: "Nil", Count: 3},
+		},
+		report.Sinks)
+}
+
+func TestDryRunSink(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	dryRun, report := consume.DryRun(consume.AppendTo(&result))
+	consume.FeedRange(0, 4, 1, dryRun)
+	assert.Empty(result)
+	assert.Equal(
+		[]consume.DryRunSink{{Name: "AppendTo", Count: 4}}, report.Sinks)
+}
@@ -0,0 +1,288 @@
+package consume
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+const (
+	kDurableWALFile      = "wal"
+	kDurableAckFile      = "wal.ack"
+	kDurableRecordHeader = 8
+)
+
+var (
+	errDurableCorrupt = errors.New("consume: write-ahead log corrupt")
+)
+
+// Codec converts values to and from bytes.
+type Codec interface {
+
+	// Encode returns the bytes for the value ptr points to.
+	Encode(ptr interface{}) ([]byte, error)
+
+	// Decode returns a pointer to the value that data encodes.
+	Decode(data []byte) (interface{}, error)
+}
+
+// JSONCodec returns a Codec that encodes values as JSON. Decode returns
+// pointers to values of the same type as prototype. prototype may be a
+// value or a pointer to a value.
+func JSONCodec(prototype interface{}) Codec {
+	valueType := reflect.TypeOf(prototype)
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	return jsonCodec{valueType: valueType}
+}
+
+// DurableBuffer returns a ConsumeFinalizer that delivers the values it
+// consumes to sink with a write-ahead log in dir so that no value is lost
+// if the process crashes or sink fails. The returned consumer encodes
+// each value with codec and appends it to the log before passing it onto
+// sink. Once sink consumes a value without error, the value is
+// acknowledged, and the log is truncated when every value in it is
+// acknowledged. When sink fails, the value stays in the log, and the
+// returned consumer tries again to deliver it along with the values that
+// follow with each later call to Consume and on Finalize. Values reach
+// sink in the order consumed. DurableBuffer replays unacknowledged values
+// from a previous run before returning. If dir can't be used or writing
+// the log fails, the returned consumer can no longer consume. The
+// returned consumer implements Health. Healthy reports such errors or,
+// failing that, the error from the last failed delivery. The CanConsume
+// method of returned consumer returns false if sink can no longer
+// consume. Caller must call Finalize() on the returned consumer when done
+// to close the log. Unacknowledged values stay in the log for the next
+// run.
+func DurableBuffer(
+	dir string, sink ErrConsumer, codec Codec) ConsumeFinalizer {
+	result := &durableConsumer{sink: sink, codec: codec}
+	result.open(dir)
+	if result.err == nil {
+		result.deliverPending()
+	}
+	return result
+}
+
+type durableConsumer struct {
+	sink        ErrConsumer
+	codec       Codec
+	wal         *os.File
+	ack         *os.File
+	size        int64
+	acked       int64
+	err         error
+	deliveryErr error
+	finalized   bool
+}
+
+func (d *durableConsumer) children() []interface{} {
+	return []interface{}{d.sink}
+}
+
+func (d *durableConsumer) CanConsume() bool {
+	return !d.finalized && d.err == nil && d.sink.CanConsume()
+}
+
+func (d *durableConsumer) Consume(ptr interface{}) {
+	MustCanConsume(d)
+	data, err := d.codec.Encode(ptr)
+	if err != nil {
+		d.err = err
+		return
+	}
+	start := d.size
+	if !d.append(data) {
+		return
+	}
+	if d.acked != start {
+		d.deliverPending()
+		return
+	}
+	if d.deliveryErr = d.sink.Consume(ptr); d.deliveryErr == nil {
+		d.acknowledge(d.size)
+	}
+}
+
+func (d *durableConsumer) Finalize() {
+	if d.finalized {
+		return
+	}
+	d.finalized = true
+	if d.wal == nil {
+		return
+	}
+	if d.err == nil {
+		d.deliverPending()
+	}
+	d.wal.Close()
+	d.ack.Close()
+}
+
+func (d *durableConsumer) Healthy() error {
+	if d.err != nil {
+		return d.err
+	}
+	return d.deliveryErr
+}
+
+func (d *durableConsumer) open(dir string) {
+	if d.err = os.MkdirAll(dir, 0755); d.err != nil {
+		return
+	}
+	d.wal, d.err = os.OpenFile(
+		filepath.Join(dir, kDurableWALFile), os.O_RDWR|os.O_CREATE, 0644)
+	if d.err != nil {
+		return
+	}
+	d.ack, d.err = os.OpenFile(
+		filepath.Join(dir, kDurableAckFile), os.O_RDWR|os.O_CREATE, 0644)
+	if d.err != nil {
+		d.wal.Close()
+		d.wal = nil
+		return
+	}
+	var ackBytes [8]byte
+	if n, _ := d.ack.ReadAt(ackBytes[:], 0); n == len(ackBytes) {
+		d.acked = int64(binary.BigEndian.Uint64(ackBytes[:]))
+	}
+	d.recoverWAL()
+}
+
+// recoverWAL drops a partially written record at the end of the log
+// which happens if the process crashed while appending. A record whose
+// checksum doesn't match counts as partially written, so it and
+// everything after it are dropped.
+func (d *durableConsumer) recoverWAL() {
+	info, err := d.wal.Stat()
+	if err != nil {
+		d.err = err
+		return
+	}
+	if d.acked > info.Size() {
+		d.acked = 0
+	}
+	d.size = d.acked
+	reader := bufio.NewReader(
+		io.NewSectionReader(d.wal, d.acked, info.Size()-d.acked))
+	for {
+		data, ok := readRecord(reader, info.Size()-d.size)
+		if !ok {
+			break
+		}
+		d.size += int64(kDurableRecordHeader + len(data))
+	}
+	if d.size != info.Size() {
+		d.err = d.wal.Truncate(d.size)
+	}
+}
+
+// append appends data to the log as a record returning false on error.
+// Each record is the length of data and the CRC-32 of data, each 4 bytes,
+// followed by data.
+func (d *durableConsumer) append(data []byte) bool {
+	record := make([]byte, kDurableRecordHeader+len(data))
+	binary.BigEndian.PutUint32(record, uint32(len(data)))
+	binary.BigEndian.PutUint32(record[4:], crc32.ChecksumIEEE(data))
+	copy(record[kDurableRecordHeader:], data)
+	if _, d.err = d.wal.WriteAt(record, d.size); d.err != nil {
+		return false
+	}
+	if d.err = d.wal.Sync(); d.err != nil {
+		return false
+	}
+	d.size += int64(len(record))
+	return true
+}
+
+// deliverPending passes the unacknowledged values in the log onto sink
+// stopping at the first failure.
+func (d *durableConsumer) deliverPending() {
+	reader := bufio.NewReader(
+		io.NewSectionReader(d.wal, d.acked, d.size-d.acked))
+	offset := d.acked
+	for offset < d.size && d.sink.CanConsume() {
+		data, ok := readRecord(reader, d.size-offset)
+		if !ok {
+			d.err = errDurableCorrupt
+			return
+		}
+		ptr, err := d.codec.Decode(data)
+		if err != nil {
+			d.err = err
+			return
+		}
+		if d.deliveryErr = d.sink.Consume(ptr); d.deliveryErr != nil {
+			return
+		}
+		offset += int64(kDurableRecordHeader + len(data))
+		if !d.acknowledge(offset) {
+			return
+		}
+	}
+}
+
+// acknowledge marks everything in the log before offset as delivered
+// truncating the log once everything is delivered. acknowledge returns
+// false on error.
+func (d *durableConsumer) acknowledge(offset int64) bool {
+	d.acked = offset
+	if d.acked == d.size {
+		if d.err = d.wal.Truncate(0); d.err != nil {
+			return false
+		}
+		d.acked = 0
+		d.size = 0
+	}
+	var ackBytes [8]byte
+	binary.BigEndian.PutUint64(ackBytes[:], uint64(d.acked))
+	if _, d.err = d.ack.WriteAt(ackBytes[:], 0); d.err != nil {
+		return false
+	}
+	d.err = d.ack.Sync()
+	return d.err == nil
+}
+
+// readRecord reads one record returning its data or false if there is
+// no complete record with a matching checksum within the next max bytes.
+func readRecord(reader *bufio.Reader, max int64) ([]byte, bool) {
+	var header [kDurableRecordHeader]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, false
+	}
+	n := int64(binary.BigEndian.Uint32(header[:]))
+	if n > max-kDurableRecordHeader {
+		return nil, false
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, false
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:]) {
+		return nil, false
+	}
+	return data, true
+}
+
+type jsonCodec struct {
+	valueType reflect.Type
+}
+
+func (j jsonCodec) Encode(ptr interface{}) ([]byte, error) {
+	return json.Marshal(ptr)
+}
+
+func (j jsonCodec) Decode(data []byte) (interface{}, error) {
+	result := reflect.New(j.valueType).Interface()
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package consume_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyPersonSink fails while down is true.
+type flakyPersonSink struct {
+	down      bool
+	delivered []person
+}
+
+func (f *flakyPersonSink) CanConsume() bool {
+	return true
+}
+
+func (f *flakyPersonSink) Consume(ptr interface{}) error {
+	if f.down {
+		return errors.New("sink down")
+	}
+	f.delivered = append(f.delivered, *ptr.(*person))
+	return nil
+}
+
+func TestDurableBuffer(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	sink := &flakyPersonSink{}
+	codec := consume.JSONCodec(person{})
+	cf := consume.DurableBuffer(dir, sink, codec)
+	consume.FeedSlice(people[:2], cf)
+	assert.Equal(people[:2], sink.delivered)
+	assertWALSize(t, dir, 0)
+
+	sink.down = true
+	consume.FeedSlice(people[2:4], cf)
+	assert.EqualError(consume.CheckHealth(cf), "sink down")
+	assert.Equal(people[:2], sink.delivered)
+
+	sink.down = false
+	consume.FeedSlice(people[4:], cf)
+	assert.NoError(consume.CheckHealth(cf))
+	assert.Equal(people, sink.delivered)
+	assertWALSize(t, dir, 0)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+}
+
+func TestDurableBufferReplay(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	sink := &flakyPersonSink{down: true}
+	codec := consume.JSONCodec(&person{})
+	cf := consume.DurableBuffer(dir, sink, codec)
+	consume.FeedSlice(people[:3], cf)
+	cf.Finalize()
+	assert.Empty(sink.delivered)
+
+	// Simulate a crash in the middle of appending a record.
+	f, err := os.OpenFile(
+		filepath.Join(dir, "wal"), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(err)
+	_, err = f.Write([]byte{0, 0, 0, 9, '{'})
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	sink.down = false
+	cf = consume.DurableBuffer(dir, sink, codec)
+	assert.Equal(people[:3], sink.delivered)
+	consume.FeedSlice(people[3:], cf)
+	cf.Finalize()
+	assert.Equal(people, sink.delivered)
+	assertWALSize(t, dir, 0)
+}
+
+func TestDurableBufferTornRecord(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	sink := &flakyPersonSink{down: true}
+	codec := consume.JSONCodec(person{})
+	cf := consume.DurableBuffer(dir, sink, codec)
+	consume.FeedSlice(people[:2], cf)
+	cf.Finalize()
+
+	// Simulate a torn write where the length of the record made it to
+	// disk but its body did not.
+	f, err := os.OpenFile(
+		filepath.Join(dir, "wal"), os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(err)
+	_, err = f.Write([]byte{0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0})
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	sink.down = false
+	cf = consume.DurableBuffer(dir, sink, codec)
+	assert.NoError(consume.CheckHealth(cf))
+	assert.Equal(people[:2], sink.delivered)
+	consume.FeedSlice(people[2:], cf)
+	cf.Finalize()
+	assert.Equal(people, sink.delivered)
+	assertWALSize(t, dir, 0)
+}
+
+func TestDurableBufferBadDir(t *testing.T) {
+	assert := assert.New(t)
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(ioutil.WriteFile(file, nil, 0644))
+	cf := consume.DurableBuffer(
+		filepath.Join(file, "dir"),
+		&flakyPersonSink{},
+		consume.JSONCodec(person{}))
+	assert.False(cf.CanConsume())
+	assert.Error(consume.CheckHealth(cf))
+	cf.Finalize()
+}
+
+func assertWALSize(t *testing.T, dir string, expected int64) {
+	t.Helper()
+	info, err := os.Stat(filepath.Join(dir, "wal"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, info.Size())
+}
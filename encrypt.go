@@ -0,0 +1,275 @@
+package consume
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	kEncryptedChunkSize   = 64 * 1024
+	kEncryptedSaltSize    = 32
+	kEncryptedNoncePrefix = 7
+	kEncryptedKeyInfo     = "github.com/keep94/consume encrypted stream"
+)
+
+var (
+	errEncryptedTruncated = errors.New("consume: encrypted stream truncated")
+	errEncryptedTrailing  = errors.New(
+		"consume: data after end of encrypted stream")
+)
+
+// Encrypted returns a ConsumeFinalizer that works like the
+// ConsumeFinalizer that newSink returns except that everything it writes
+// gets encrypted with AES-GCM before reaching w. key is the AES key and
+// must be 16, 24, or 32 bytes long. Each stream gets encrypted with its
+// own key derived from key and a random salt with HKDF-SHA256, so many
+// streams can safely share the same key. The encrypted output is
+// authenticated in chunks, and the last chunk is marked so that
+// truncation is detected.
+// Caller must call Finalize() on the returned consumer to finalize the
+// underlying sink and write the last encrypted chunk. Use Decrypt to read
+// the output back. Encrypted panics if key is not a valid AES key.
+func Encrypted(
+	newSink func(w io.Writer) ConsumeFinalizer,
+	w io.Writer,
+	key []byte) ConsumeFinalizer {
+	writer := &encryptingWriter{
+		w:     w,
+		key:   key,
+		nonce: make([]byte, newAEAD(key).NonceSize()),
+	}
+	return &encryptedConsumer{
+		ConsumeFinalizer: newSink(writer),
+		writer:           writer,
+	}
+}
+
+// Decrypt returns a Reader that reads the plain text of output that a
+// consumer from Encrypted wrote to r. key must be the same key given to
+// Encrypted. The returned Reader returns an error if the output was
+// tampered with, truncated, or followed by extra data. Decrypt panics if
+// key is not a valid AES key.
+func Decrypt(r io.Reader, key []byte) io.Reader {
+	return &decryptingReader{
+		r:     r,
+		key:   key,
+		nonce: make([]byte, newAEAD(key).NonceSize()),
+	}
+}
+
+func newAEAD(key []byte) cipher.AEAD {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// streamAEAD returns the AEAD for the stream with the given salt. Its key
+// is derived from key with HKDF-SHA256 and is as long as key.
+func streamAEAD(key, salt []byte) cipher.AEAD {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(key)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(kEncryptedKeyInfo))
+	expand.Write([]byte{1})
+	return newAEAD(expand.Sum(nil)[:len(key)])
+}
+
+type encryptedConsumer struct {
+	ConsumeFinalizer
+	writer    *encryptingWriter
+	finalized bool
+}
+
+func (e *encryptedConsumer) children() []interface{} {
+	return []interface{}{e.ConsumeFinalizer}
+}
+
+func (e *encryptedConsumer) Finalize() {
+	if e.finalized {
+		return
+	}
+	e.finalized = true
+	e.ConsumeFinalizer.Finalize()
+	e.writer.Close()
+}
+
+func (e *encryptedConsumer) Healthy() error {
+	if err := CheckHealth(e.ConsumeFinalizer); err != nil {
+		return err
+	}
+	return e.writer.err
+}
+
+// encryptingWriter encrypts each chunk of kEncryptedChunkSize bytes
+// separately. Output starts with a random salt from which the key of the
+// stream is derived. Each chunk gets written as a 4 byte length followed
+// by the sealed chunk. Since each stream has its own key, the nonce of
+// each chunk is kEncryptedNoncePrefix zero bytes followed by the chunk
+// number followed by a byte that is 1 for the last chunk and 0
+// otherwise.
+type encryptingWriter struct {
+	w             io.Writer
+	key           []byte
+	aead          cipher.AEAD
+	nonce         []byte
+	buffer        []byte
+	sealed        []byte
+	chunkNo       uint32
+	headerWritten bool
+	err           error
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for e.err == nil && len(p) > 0 {
+		n := kEncryptedChunkSize - len(e.buffer)
+		if n > len(p) {
+			n = len(p)
+		}
+		e.buffer = append(e.buffer, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(e.buffer) == kEncryptedChunkSize {
+			e.writeChunk(false)
+		}
+	}
+	return written, e.err
+}
+
+func (e *encryptingWriter) Close() error {
+	e.writeChunk(true)
+	return e.err
+}
+
+func (e *encryptingWriter) writeChunk(last bool) {
+	if e.err != nil {
+		return
+	}
+	if !e.headerWritten {
+		e.headerWritten = true
+		salt := make([]byte, kEncryptedSaltSize)
+		if _, e.err = io.ReadFull(rand.Reader, salt); e.err != nil {
+			return
+		}
+		if _, e.err = e.w.Write(salt); e.err != nil {
+			return
+		}
+		e.aead = streamAEAD(e.key, salt)
+	}
+	setChunkNonce(e.nonce, e.chunkNo, last)
+	e.chunkNo++
+	e.sealed = e.aead.Seal(
+		append(e.sealed[:0], 0, 0, 0, 0), e.nonce, e.buffer, nil)
+	binary.BigEndian.PutUint32(e.sealed, uint32(len(e.sealed)-4))
+	e.buffer = e.buffer[:0]
+	_, e.err = e.w.Write(e.sealed)
+}
+
+type decryptingReader struct {
+	r             io.Reader
+	key           []byte
+	aead          cipher.AEAD
+	nonce         []byte
+	sealed        []byte
+	plain         []byte
+	chunkNo       uint32
+	headerRead    bool
+	lastChunkRead bool
+	err           error
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 && d.err == nil {
+		if d.lastChunkRead {
+			d.err = d.readEnd()
+		} else {
+			d.readChunk()
+		}
+	}
+	if len(d.plain) > 0 {
+		n := copy(p, d.plain)
+		d.plain = d.plain[n:]
+		return n, nil
+	}
+	return 0, d.err
+}
+
+func (d *decryptingReader) readChunk() {
+	if !d.headerRead {
+		d.headerRead = true
+		salt := make([]byte, kEncryptedSaltSize)
+		if _, d.err = io.ReadFull(d.r, salt); d.err != nil {
+			d.err = truncatedIfEOF(d.err)
+			return
+		}
+		d.aead = streamAEAD(d.key, salt)
+	}
+	var length [4]byte
+	if _, d.err = io.ReadFull(d.r, length[:]); d.err != nil {
+		d.err = truncatedIfEOF(d.err)
+		return
+	}
+	size := int(binary.BigEndian.Uint32(length[:]))
+	if size > kEncryptedChunkSize+d.aead.Overhead() {
+		d.err = errors.New("consume: encrypted chunk too large")
+		return
+	}
+	if cap(d.sealed) < size {
+		d.sealed = make([]byte, size)
+	}
+	d.sealed = d.sealed[:size]
+	if _, d.err = io.ReadFull(d.r, d.sealed); d.err != nil {
+		d.err = truncatedIfEOF(d.err)
+		return
+	}
+	chunkNo := d.chunkNo
+	d.chunkNo++
+	setChunkNonce(d.nonce, chunkNo, false)
+	if d.plain, d.err = d.aead.Open(
+		d.plain[:0], d.nonce, d.sealed, nil); d.err == nil {
+		return
+	}
+	setChunkNonce(d.nonce, chunkNo, true)
+	if d.plain, d.err = d.aead.Open(
+		d.plain[:0], d.nonce, d.sealed, nil); d.err == nil {
+		d.lastChunkRead = true
+	}
+}
+
+// readEnd returns io.EOF if nothing follows the last chunk.
+func (d *decryptingReader) readEnd() error {
+	var extra [1]byte
+	n, err := io.ReadFull(d.r, extra[:])
+	if n > 0 {
+		return errEncryptedTrailing
+	}
+	return err
+}
+
+func setChunkNonce(nonce []byte, chunkNo uint32, last bool) {
+	binary.BigEndian.PutUint32(nonce[kEncryptedNoncePrefix:], chunkNo)
+	if last {
+		nonce[len(nonce)-1] = 1
+	} else {
+		nonce[len(nonce)-1] = 0
+	}
+}
+
+func truncatedIfEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errEncryptedTruncated
+	}
+	return err
+}
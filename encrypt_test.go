@@ -0,0 +1,116 @@
+package consume_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+var encryptionKey = []byte("0123456789abcdef")
+
+func TestEncrypted(t *testing.T) {
+	assert := assert.New(t)
+	var buffer bytes.Buffer
+	cf := consume.Encrypted(consume.ToYAML, &buffer, encryptionKey)
+	writePeopleInLoop(people[:], consume.Slice(cf, 0, 2))
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.NotContains(buffer.String(), "Mark")
+	plain, err := ioutil.ReadAll(
+		consume.Decrypt(bytes.NewReader(buffer.Bytes()), encryptionKey))
+	assert.NoError(err)
+	assert.Equal(
+		"name: Mark\nage: 50\n---\nname: Stoney\nage: 49\n", string(plain))
+}
+
+func TestEncryptedLarge(t *testing.T) {
+	assert := assert.New(t)
+	var buffer bytes.Buffer
+	cf := consume.Encrypted(
+		func(w io.Writer) consume.ConsumeFinalizer {
+			return &writerConsumer{w: w}
+		},
+		&buffer,
+		encryptionKey)
+	line := strings.Repeat("x", 1000) + "\n"
+	for i := 0; i < 200; i++ {
+		cf.Consume(&line)
+	}
+	cf.Finalize()
+	plain, err := ioutil.ReadAll(
+		consume.Decrypt(bytes.NewReader(buffer.Bytes()), encryptionKey))
+	assert.NoError(err)
+	assert.Equal(strings.Repeat(line, 200), string(plain))
+
+	// Truncation must be detected
+	truncated := buffer.Bytes()[:firstChunkEnd(buffer.Bytes())]
+	_, err = ioutil.ReadAll(
+		consume.Decrypt(bytes.NewReader(truncated), encryptionKey))
+	assert.Error(err)
+
+	// Tampering must be detected
+	tampered := append([]byte(nil), buffer.Bytes()...)
+	tampered[40] ^= 1
+	_, err = ioutil.ReadAll(
+		consume.Decrypt(bytes.NewReader(tampered), encryptionKey))
+	assert.Error(err)
+
+	// Data after the last chunk must be detected
+	trailing := append(append([]byte(nil), buffer.Bytes()...), 0)
+	_, err = ioutil.ReadAll(
+		consume.Decrypt(bytes.NewReader(trailing), encryptionKey))
+	assert.Error(err)
+}
+
+func TestEncryptedKeyPerStream(t *testing.T) {
+	assert := assert.New(t)
+	var first, second bytes.Buffer
+	for _, buffer := range []*bytes.Buffer{&first, &second} {
+		cf := consume.Encrypted(consume.ToYAML, buffer, encryptionKey)
+		consume.FeedSlice(people, cf)
+		cf.Finalize()
+	}
+
+	// Same plain text and key, but the salts and so the keys differ.
+	assert.NotEqual(first.Bytes()[:32], second.Bytes()[:32])
+	assert.NotEqual(first.Bytes()[32:], second.Bytes()[32:])
+}
+
+func TestEncryptedPanics(t *testing.T) {
+	assert := assert.New(t)
+	var buffer bytes.Buffer
+	assert.Panics(func() {
+		consume.Encrypted(consume.ToYAML, &buffer, []byte("short"))
+	})
+}
+
+// firstChunkEnd returns the offset just past the first encrypted chunk.
+func firstChunkEnd(encrypted []byte) int {
+	length := int(encrypted[32])<<24 | int(encrypted[33])<<16 |
+		int(encrypted[34])<<8 | int(encrypted[35])
+	return 36 + length
+}
+
+// writerConsumer writes the strings it consumes to w.
+type writerConsumer struct {
+	w         io.Writer
+	finalized bool
+}
+
+func (c *writerConsumer) CanConsume() bool {
+	return !c.finalized
+}
+
+func (c *writerConsumer) Consume(ptr interface{}) {
+	io.WriteString(c.w, *ptr.(*string))
+}
+
+func (c *writerConsumer) Finalize() {
+	c.finalized = true
+}
@@ -0,0 +1,153 @@
+package consume
+
+import (
+	"reflect"
+)
+
+var (
+	envelopePtrType = reflect.TypeOf((*Envelope)(nil))
+)
+
+// Envelope carries a value through a pipeline along with metadata such
+// as a correlation ID. Envelopes let metadata flow through a pipeline
+// without adding fields to the values themselves. Use Wrap to put values
+// in envelopes, WithMeta to add metadata, InEnvelope to run ordinary
+// stages on the values inside envelopes, and Unwrap to take values back
+// out of their envelopes. Stages that never look at the values they
+// consume such as Slice, Compose, or Page pass envelopes through
+// unchanged. Functions passed to MapFilter, TakeWhile, or as key
+// functions to stages such as Distinct or RateLimitPerKey get the value
+// inside each envelope unless they take an *Envelope themselves. A value
+// that a function passed to MapFilter maps goes out in an envelope with
+// the original metadata. Filterer and Mapper implementations, key
+// functions of type func(ptr interface{}) interface{}, and the functions
+// passed to DistinctFunc or DistinctHash get the *Envelope. To run those
+// on the value inside, use InEnvelope.
+type Envelope struct {
+
+	// Value points to the value in this envelope. Wrap and InEnvelope give
+	// each envelope its own shallow copy of the value so that Value stays
+	// valid after Consume returns. This way, envelopes can be saved with
+	// AppendTo.
+	Value interface{}
+
+	// Meta holds the metadata. Stages treat Meta as read only.
+	Meta map[string]interface{}
+}
+
+// Get returns the metadata under key or nil if there is none.
+func (e *Envelope) Get(key string) interface{} {
+	return e.Meta[key]
+}
+
+// Wrap returns a Mapper that puts each value in an Envelope with no
+// metadata. That is, it maps *T values to *Envelope values.
+func Wrap() Mapper {
+	return &wrapMapper{}
+}
+
+// WithMeta returns a Mapper of *Envelope values that adds metadata under
+// key. valueFunc takes the pointer in the Value field of the envelope
+// and returns the metadata to add. The mapped envelope gets its own copy
+// of the metadata so that the original envelope is unchanged.
+func WithMeta(
+	key string, valueFunc func(ptr interface{}) interface{}) Mapper {
+	return &withMetaMapper{key: key, valueFunc: valueFunc}
+}
+
+// Unwrap returns a Mapper that takes values back out of their envelopes.
+// That is, it maps *Envelope values to *T values.
+func Unwrap() Mapper {
+	return &unwrapMapper{}
+}
+
+// InEnvelope returns a MapFilterer of *Envelope values that applies funcs
+// to the value inside each envelope. The mapped envelope holds the
+// mapped value and the same metadata as the original envelope. If funcs
+// filter out a value, its envelope gets filtered out too. funcs can be
+// anything that MapFilter accepts.
+func InEnvelope(funcs ...interface{}) MapFilterer {
+	return &stageMapFilterer{list: []mapFilterStage{
+		&envelopeStage{mapFilters: NewMapFilterer(funcs...)}}}
+}
+
+type wrapMapper struct {
+	result Envelope
+}
+
+func (w *wrapMapper) Map(ptr interface{}) interface{} {
+	w.result = Envelope{Value: shallowCopy(ptr)}
+	return &w.result
+}
+
+func (w *wrapMapper) Clone() Mapper {
+	return &wrapMapper{}
+}
+
+type withMetaMapper struct {
+	key       string
+	valueFunc func(ptr interface{}) interface{}
+	result    Envelope
+}
+
+func (w *withMetaMapper) Map(ptr interface{}) interface{} {
+	src := ptr.(*Envelope)
+	meta := make(map[string]interface{}, len(src.Meta)+1)
+	for k, v := range src.Meta {
+		meta[k] = v
+	}
+	meta[w.key] = w.valueFunc(src.Value)
+	w.result = Envelope{Value: src.Value, Meta: meta}
+	return &w.result
+}
+
+func (w *withMetaMapper) Clone() Mapper {
+	return &withMetaMapper{key: w.key, valueFunc: w.valueFunc}
+}
+
+// unwrapMapper copies the value out of the envelope so that it can
+// return the same pointer each time.
+type unwrapMapper struct {
+	result reflect.Value
+}
+
+func (u *unwrapMapper) Map(ptr interface{}) interface{} {
+	value := reflect.ValueOf(ptr.(*Envelope).Value).Elem()
+	if !u.result.IsValid() || u.result.Elem().Type() != value.Type() {
+		u.result = reflect.New(value.Type())
+	}
+	u.result.Elem().Set(value)
+	return u.result.Interface()
+}
+
+func (u *unwrapMapper) Clone() Mapper {
+	return &unwrapMapper{}
+}
+
+type envelopeStage struct {
+	mapFilters MapFilterer
+}
+
+type envelopeScratch struct {
+	ctx    *MapFilterContext
+	result Envelope
+}
+
+func (e *envelopeStage) newScratch() interface{} {
+	return &envelopeScratch{ctx: e.mapFilters.NewContext()}
+}
+
+func (e *envelopeStage) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	src := ptr.(*Envelope)
+	s := scratch.(*envelopeScratch)
+	value := e.mapFilters.MapFilterWithContext(s.ctx, src.Value)
+	if value == nil {
+		return nil
+	}
+	if value != src.Value {
+		value = shallowCopy(value)
+	}
+	s.result = Envelope{Value: value, Meta: src.Meta}
+	return &s.result
+}
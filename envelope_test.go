@@ -0,0 +1,112 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	assert := assert.New(t)
+	var names []string
+	var ids []interface{}
+	consumer := consume.MapFilter(
+		consume.Compose(
+			consume.MapFilter(
+				consume.AppendTo(&names),
+				consume.Unwrap()),
+			consume.ConsumerFunc(func(ptr interface{}) {
+				ids = append(ids, ptr.(*consume.Envelope).Get("id"))
+			})),
+		consume.Wrap(),
+		consume.WithMeta("id", func(ptr interface{}) interface{} {
+			return ptr.(*person).Age
+		}),
+		consume.InEnvelope(
+			func(ptr *person) bool { return ptr.Age >= 40 },
+			func(src *person, dest *string) bool {
+				*dest = src.Name
+				return true
+			}))
+	var expectedNames []string
+	var expectedIDs []interface{}
+	for i := range people {
+		consumer.Consume(&people[i])
+		if people[i].Age >= 40 {
+			expectedNames = append(expectedNames, people[i].Name)
+			expectedIDs = append(expectedIDs, people[i].Age)
+		}
+	}
+	assert.NotEmpty(expectedNames)
+	assert.Equal(expectedNames, names)
+	assert.Equal(expectedIDs, ids)
+}
+
+func TestWithMetaCopies(t *testing.T) {
+	assert := assert.New(t)
+	original := &consume.Envelope{
+		Value: new(int), Meta: map[string]interface{}{"a": 1}}
+	mapper := consume.WithMeta("b", func(ptr interface{}) interface{} {
+		return 2
+	})
+	mapped := mapper.Map(original).(*consume.Envelope)
+	assert.Equal(1, mapped.Get("a"))
+	assert.Equal(2, mapped.Get("b"))
+	assert.Nil(original.Get("b"))
+}
+
+func TestEnvelopeSaved(t *testing.T) {
+	assert := assert.New(t)
+	var envelopes []consume.Envelope
+	consumer := consume.MapFilter(
+		consume.AppendTo(&envelopes),
+		consume.Wrap(),
+		consume.InEnvelope(func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		}))
+	consume.FeedRange(0, 3, 1, consumer)
+	if assert.Len(envelopes, 3) {
+		assert.Equal("0", *envelopes[0].Value.(*string))
+		assert.Equal("1", *envelopes[1].Value.(*string))
+		assert.Equal("2", *envelopes[2].Value.(*string))
+	}
+	envelopes = nil
+	consume.FeedRange(
+		0, 2, 1, consume.MapFilter(consume.AppendTo(&envelopes), consume.Wrap()))
+	if assert.Len(envelopes, 2) {
+		assert.Equal(0, *envelopes[0].Value.(*int))
+		assert.Equal(1, *envelopes[1].Value.(*int))
+	}
+}
+
+func TestEnvelopeTransparent(t *testing.T) {
+	assert := assert.New(t)
+	var envelopes []consume.Envelope
+	consumer := consume.MapFilter(
+		consume.Distinct(
+			consume.AppendTo(&envelopes),
+			func(ptr *string) string { return (*ptr)[:1] }),
+		consume.Wrap(),
+		consume.WithMeta("age", func(ptr interface{}) interface{} {
+			return ptr.(*person).Age
+		}),
+		func(ptr *person) bool { return ptr.Age >= 40 },
+		func(src *person, dest *string) bool {
+			*dest = src.Name
+			return true
+		})
+	for i := range people {
+		consumer.Consume(&people[i])
+	}
+	if assert.Len(envelopes, 3) {
+		assert.Equal("Mark", *envelopes[0].Value.(*string))
+		assert.Equal(50, envelopes[0].Get("age"))
+		assert.Equal("Stoney", *envelopes[1].Value.(*string))
+		assert.Equal(49, envelopes[1].Get("age"))
+		assert.Equal("Beth", *envelopes[2].Value.(*string))
+		assert.Equal(54, envelopes[2].Get("age"))
+	}
+}
@@ -0,0 +1,92 @@
+package consume
+
+// ErrConsumer works like Consumer except that its Consume method reports
+// whether consuming a value failed. Sinks that do I/O such as ones that
+// write to a network service implement ErrConsumer.
+type ErrConsumer interface {
+
+	// CanConsume returns true if this instance can consume a value.
+	// Once CanConsume returns false, it should always return false.
+	CanConsume() bool
+
+	// Consume consumes the value that ptr points to and returns an error
+	// if it could not. Consume panics if CanConsume returns false.
+	Consume(ptr interface{}) error
+}
+
+// mustCanConsumeE works like MustCanConsume for ErrConsumers.
+func mustCanConsumeE(c ErrConsumer) {
+	if !c.CanConsume() {
+		panic(kCantConsume)
+	}
+}
+
+// The ErrConsumerFunc type is an adapter to allow the use of an ordinary
+// function as an ErrConsumer. ErrConsumerFunc can always consume.
+type ErrConsumerFunc func(ptr interface{}) error
+
+// Consume invokes e, this function.
+func (e ErrConsumerFunc) Consume(ptr interface{}) error {
+	return e(ptr)
+}
+
+// CanConsume always returns true.
+func (e ErrConsumerFunc) CanConsume() bool {
+	return true
+}
+
+// ToErrConsumer returns c as an ErrConsumer whose Consume method never
+// fails. Use it to pass a plain Consumer where an ErrConsumer is
+// expected.
+func ToErrConsumer(c Consumer) ErrConsumer {
+	return errConsumerAdapter{c}
+}
+
+// FromErrConsumer returns c as a plain Consumer so that c can be used
+// with Compose, MapFilter, and the like. The returned consumer stops at
+// the first error: it stores that error at errPtr, and its CanConsume
+// method returns false from then on. FromErrConsumer leaves errPtr
+// unchanged until then. To keep consuming after errors, use DeadLetter
+// instead.
+func FromErrConsumer(c ErrConsumer, errPtr *error) Consumer {
+	return &consumerAdapter{errConsumer: c, errPtr: errPtr}
+}
+
+type errConsumerAdapter struct {
+	consumer Consumer
+}
+
+func (e errConsumerAdapter) children() []interface{} {
+	return []interface{}{e.consumer}
+}
+
+func (e errConsumerAdapter) CanConsume() bool {
+	return e.consumer.CanConsume()
+}
+
+func (e errConsumerAdapter) Consume(ptr interface{}) error {
+	e.consumer.Consume(ptr)
+	return nil
+}
+
+type consumerAdapter struct {
+	errConsumer ErrConsumer
+	errPtr      *error
+	failed      bool
+}
+
+func (c *consumerAdapter) children() []interface{} {
+	return []interface{}{c.errConsumer}
+}
+
+func (c *consumerAdapter) CanConsume() bool {
+	return !c.failed && c.errConsumer.CanConsume()
+}
+
+func (c *consumerAdapter) Consume(ptr interface{}) {
+	MustCanConsume(c)
+	if err := c.errConsumer.Consume(ptr); err != nil {
+		*c.errPtr = err
+		c.failed = true
+	}
+}
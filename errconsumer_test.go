@@ -0,0 +1,48 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromErrConsumer(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	sink := consume.ErrConsumerFunc(func(ptr interface{}) error {
+		if *ptr.(*int) == 3 {
+			return errors.New("bad value")
+		}
+		result = append(result, *ptr.(*int))
+		return nil
+	})
+	var err error
+	var all []int
+	consume.FeedRange(
+		0,
+		10,
+		1,
+		consume.Compose(
+			consume.AppendTo(&all), consume.FromErrConsumer(sink, &err)))
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.Len(all, 10)
+	assert.EqualError(err, "bad value")
+}
+
+func TestToErrConsumer(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	c := consume.ToErrConsumer(consume.Slice(consume.AppendTo(&result), 0, 2))
+	x := 5
+	assert.True(c.CanConsume())
+	assert.NoError(c.Consume(&x))
+	assert.NoError(c.Consume(&x))
+	assert.False(c.CanConsume())
+	assert.Equal([]int{5, 5}, result)
+	var err error
+	consumer := consume.FromErrConsumer(c, &err)
+	assert.False(consumer.CanConsume())
+	assert.NoError(err)
+}
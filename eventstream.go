@@ -0,0 +1,88 @@
+package consume
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// ToEventStream returns a ConsumeFinalizer that writes each consumed value
+// to w as a Server-Sent Event. format converts the pointer passed to
+// Consume into the event payload. Each line of the payload becomes a
+// separate "data:" line of the event. ToEventStream sets the headers that
+// Server-Sent Events require on w. If w implements http.Flusher, the
+// returned consumer flushes w after every flushEvery values and on
+// Finalize. If a write to w fails, CanConsume() returns false from then on.
+// ToEventStream panics if flushEvery <= 0.
+func ToEventStream(
+	w http.ResponseWriter,
+	format func(ptr interface{}) []byte,
+	flushEvery int) ConsumeFinalizer {
+	if flushEvery <= 0 {
+		panic("flushEvery must be positive")
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	flusher, _ := w.(http.Flusher)
+	return &eventStreamConsumer{
+		w:          w,
+		flusher:    flusher,
+		format:     format,
+		flushEvery: flushEvery,
+	}
+}
+
+type eventStreamConsumer struct {
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	format     func(ptr interface{}) []byte
+	flushEvery int
+	unflushed  int
+	buffer     bytes.Buffer
+	err        error
+	finalized  bool
+}
+
+func (e *eventStreamConsumer) CanConsume() bool {
+	return e.err == nil && !e.finalized
+}
+
+func (e *eventStreamConsumer) Consume(ptr interface{}) {
+	MustCanConsume(e)
+	e.buffer.Reset()
+	for _, line := range bytes.Split(e.format(ptr), []byte{'\n'}) {
+		e.buffer.WriteString("data: ")
+		e.buffer.Write(line)
+		e.buffer.WriteByte('\n')
+	}
+	e.buffer.WriteByte('\n')
+	if _, e.err = e.w.Write(e.buffer.Bytes()); e.err != nil {
+		return
+	}
+	e.unflushed++
+	if e.unflushed == e.flushEvery {
+		e.flush()
+	}
+}
+
+func (e *eventStreamConsumer) Finalize() {
+	if e.finalized {
+		return
+	}
+	e.finalized = true
+	if e.err == nil {
+		e.flush()
+	}
+}
+
+func (e *eventStreamConsumer) Healthy() error {
+	return e.err
+}
+
+func (e *eventStreamConsumer) flush() {
+	e.unflushed = 0
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+}
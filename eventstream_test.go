@@ -0,0 +1,74 @@
+package consume_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToEventStream(t *testing.T) {
+	assert := assert.New(t)
+	recorder := httptest.NewRecorder()
+	cf := consume.ToEventStream(recorder, formatInt, 2)
+	feedInts(t, consume.Slice(cf, 0, 3))
+	assert.True(recorder.Flushed)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Panics(func() { cf.Consume(new(int)) })
+	assert.Equal("text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(
+		"data: 0\n\ndata: 1\n\ndata: 2\n\n", recorder.Body.String())
+}
+
+func TestToEventStreamMultiLine(t *testing.T) {
+	assert := assert.New(t)
+	recorder := httptest.NewRecorder()
+	cf := consume.ToEventStream(
+		recorder,
+		func(ptr interface{}) []byte {
+			p := ptr.(*person)
+			return []byte(p.Name + "\n" + strconv.Itoa(p.Age))
+		},
+		10)
+	cf.Consume(&people[beth])
+	cf.Finalize()
+	assert.Equal("data: Beth\ndata: 54\n\n", recorder.Body.String())
+}
+
+func TestToEventStreamWriteError(t *testing.T) {
+	assert := assert.New(t)
+	cf := consume.ToEventStream(failingResponseWriter{}, formatInt, 1)
+	assert.True(cf.CanConsume())
+	cf.Consume(new(int))
+	assert.False(cf.CanConsume())
+}
+
+func TestToEventStreamPanics(t *testing.T) {
+	assert := assert.New(t)
+	recorder := httptest.NewRecorder()
+	assert.Panics(func() { consume.ToEventStream(recorder, formatInt, 0) })
+}
+
+func formatInt(ptr interface{}) []byte {
+	return []byte(strconv.Itoa(*ptr.(*int)))
+}
+
+type failingResponseWriter struct {
+}
+
+func (f failingResponseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+func (f failingResponseWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func (f failingResponseWriter) WriteHeader(statusCode int) {
+}
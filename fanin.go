@@ -0,0 +1,57 @@
+package consume
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// FanIn collects values from multiple goroutines into one sorted slice.
+// Each call to newConsumer returns a new consumer for one goroutine to
+// use. Each consumer appends to its own private slice so goroutines never
+// contend. newConsumer is safe to call from multiple goroutines. After
+// all goroutines are done, caller calls finalize to append all the
+// collected values to the slice that slicePtr points to sorted by
+// orderFunc. orderFunc takes pointers to two values and returns true if
+// the first value comes before the second, e.g func(a, b *Event) bool.
+// Sorting is stable, and values from consumers created earlier come
+// before equal values from consumers created later. FanIn panics if
+// slicePtr is not a pointer to a slice or if orderFunc has the wrong type.
+func FanIn(slicePtr interface{}, orderFunc interface{}) (
+	newConsumer func() Consumer, finalize func()) {
+	aSliceValue := sliceValueFromP(slicePtr, false)
+	elemPtrType := reflect.PtrTo(aSliceValue.Type().Elem())
+	orderType := reflect.TypeOf(orderFunc)
+	if orderType == nil || orderType.Kind() != reflect.Func ||
+		orderType.NumIn() != 2 || orderType.NumOut() != 1 ||
+		orderType.In(0) != elemPtrType || orderType.In(1) != elemPtrType ||
+		orderType.Out(0).Kind() != reflect.Bool {
+		panic("orderFunc must be like func(a, b *T) bool")
+	}
+	less := reflect.ValueOf(orderFunc)
+	var mu sync.Mutex
+	var shardPtrs []reflect.Value
+	newConsumer = func() Consumer {
+		shardPtr := reflect.New(aSliceValue.Type())
+		mu.Lock()
+		shardPtrs = append(shardPtrs, shardPtr)
+		mu.Unlock()
+		return AppendTo(shardPtr.Interface())
+	}
+	finalize = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		merged := reflect.New(aSliceValue.Type()).Elem()
+		for _, shardPtr := range shardPtrs {
+			merged = reflect.AppendSlice(merged, shardPtr.Elem())
+		}
+		shardPtrs = nil
+		sort.SliceStable(merged.Interface(), func(i, j int) bool {
+			params := [...]reflect.Value{
+				merged.Index(i).Addr(), merged.Index(j).Addr()}
+			return less.Call(params[:])[0].Bool()
+		})
+		aSliceValue.Set(reflect.AppendSlice(aSliceValue, merged))
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package consume_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanIn(t *testing.T) {
+	assert := assert.New(t)
+	var result []person
+	newConsumer, finalize := consume.FanIn(
+		&result, func(a, b *person) bool { return a.Age < b.Age })
+	consumers := []consume.Consumer{newConsumer(), newConsumer()}
+	var wg sync.WaitGroup
+	for i, consumer := range consumers {
+		wg.Add(1)
+		go func(start int, consumer consume.Consumer) {
+			defer wg.Done()
+			for j := start; j < len(people); j += 2 {
+				consumer.Consume(&people[j])
+			}
+		}(i, consumer)
+	}
+	wg.Wait()
+	finalize()
+	assert.Len(result, len(people))
+	for i := 1; i < len(result); i++ {
+		assert.LessOrEqual(result[i-1].Age, result[i].Age)
+	}
+}
+
+func TestFanInStable(t *testing.T) {
+	assert := assert.New(t)
+	ints := []int{100}
+	newConsumer, finalize := consume.FanIn(
+		&ints, func(a, b *int) bool { return *a/10 < *b/10 })
+	first, second := newConsumer(), newConsumer()
+	for _, x := range []int{25, 11, 3} {
+		second.Consume(&x)
+	}
+	for _, x := range []int{22, 14, 7} {
+		first.Consume(&x)
+	}
+	finalize()
+	assert.Equal([]int{100, 7, 3, 14, 11, 22, 25}, ints)
+}
+
+func TestFanInPanics(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	assert.Panics(func() {
+		consume.FanIn(&ints, func(a, b *string) bool { return true })
+	})
+	assert.Panics(func() {
+		consume.FanIn(ints, func(a, b *int) bool { return true })
+	})
+}
@@ -0,0 +1,180 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// FeedSlice has c consume each value in aSlice in order stopping early if
+// c can no longer consume. aSlice is a slice of values, not a pointer to
+// a slice. If c is a BulkConsumer, FeedSlice passes all of aSlice to its
+// ConsumeSlice method. FeedSlice panics if aSlice is not a slice.
+func FeedSlice(aSlice interface{}, c Consumer) {
+	if !c.CanConsume() {
+		checkSliceValue(reflect.ValueOf(aSlice), false)
+		return
+	}
+	if bulk, ok := c.(BulkConsumer); ok {
+		switch s := aSlice.(type) {
+		case []int:
+			bulk.ConsumeSlice(&s)
+		case []string:
+			bulk.ConsumeSlice(&s)
+		case []float64:
+			bulk.ConsumeSlice(&s)
+		default:
+			aSliceValue := checkSliceValue(reflect.ValueOf(aSlice), false)
+			aSlicePtr := reflect.New(aSliceValue.Type())
+			aSlicePtr.Elem().Set(aSliceValue)
+			bulk.ConsumeSlice(aSlicePtr.Interface())
+		}
+		return
+	}
+	switch s := aSlice.(type) {
+	case []int:
+		for i := 0; i < len(s) && c.CanConsume(); i++ {
+			c.Consume(&s[i])
+		}
+	case []string:
+		for i := 0; i < len(s) && c.CanConsume(); i++ {
+			c.Consume(&s[i])
+		}
+	case []float64:
+		for i := 0; i < len(s) && c.CanConsume(); i++ {
+			c.Consume(&s[i])
+		}
+	default:
+		consumeSliceValue(c, checkSliceValue(reflect.ValueOf(aSlice), false))
+	}
+}
+
+// FeedMap has c consume each value in aMap stopping early if c can no
+// longer consume. Since values in a map can't be addressed, FeedMap
+// passes c a pointer to a copy of each value. That copy changes with
+// each value passed, so c must not hold onto the pointer. Like any
+// iteration over a map, the order of the values is not specified.
+// FeedMap panics if aMap is not a map.
+func FeedMap(aMap interface{}, c Consumer) {
+	aMapValue := reflect.ValueOf(aMap)
+	if aMapValue.Kind() != reflect.Map {
+		panic("a map is expected.")
+	}
+	if !c.CanConsume() {
+		return
+	}
+	valuePtr := reflect.New(aMapValue.Type().Elem())
+	ivaluePtr := valuePtr.Interface()
+	iter := aMapValue.MapRange()
+	for c.CanConsume() && iter.Next() {
+		valuePtr.Elem().Set(iter.Value())
+		c.Consume(ivaluePtr)
+	}
+}
+
+// ConsumeFromChannel has consumer consume each value received from ch
+// until ch is closed or consumer can no longer consume. ch is a channel
+// of values, not a pointer to one, and may be receive only.
+// ConsumeFromChannel checks that consumer can consume before receiving
+// each value so that it never receives a value that consumer can't
+// consume. Like FeedMap, ConsumeFromChannel passes consumer a pointer to
+// a copy of each value that changes with each value passed.
+// ConsumeFromChannel panics if ch is not a channel that can receive.
+func ConsumeFromChannel(ch interface{}, consumer Consumer) {
+	chValue := reflect.ValueOf(ch)
+	if chValue.Kind() != reflect.Chan ||
+		chValue.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("a receivable channel is expected.")
+	}
+	valuePtr := reflect.New(chValue.Type().Elem())
+	ivaluePtr := valuePtr.Interface()
+	for consumer.CanConsume() {
+		value, ok := chValue.Recv()
+		if !ok {
+			return
+		}
+		valuePtr.Elem().Set(value)
+		consumer.Consume(ivaluePtr)
+	}
+}
+
+// FeedFunc has c consume the values that gen generates. FeedFunc calls gen
+// with 0, 1, 2, ... and passes each pointer gen returns onto c. gen
+// returns a pointer to the generated value and true or nil and false if
+// there are no more values. FeedFunc stops when gen returns false or when
+// c can no longer consume.
+func FeedFunc(gen func(i int) (interface{}, bool), c Consumer) {
+	for i := 0; c.CanConsume(); i++ {
+		ptr, ok := gen(i)
+		if !ok {
+			return
+		}
+		c.Consume(ptr)
+	}
+}
+
+// FeedRange has c consume the ints start, start+step, start+2*step, ...
+// up to but not including end. If step is negative, the ints count down
+// to end. FeedRange stops early if c can no longer consume. FeedRange
+// panics if step is 0.
+func FeedRange(start, end, step int, c Consumer) {
+	if step == 0 {
+		panic("step must be non-zero")
+	}
+	i := start
+	for (step > 0 && i < end) || (step < 0 && i > end) {
+		if !c.CanConsume() {
+			return
+		}
+		value := i
+		c.Consume(&value)
+
+		// Stop before i += step can overflow. Distances are compared as
+		// unsigned ints since end - i may not fit in an int.
+		if step > 0 && uint(end-i) <= uint(step) ||
+			step < 0 && uint(i-end) <= uint(-step) {
+			return
+		}
+		i += step
+	}
+}
+
+// Interleave has c consume the values from producers round robin. That
+// is, it passes the first value of each producer onto c, then the second
+// value of each producer, and so on. Producers that run out of values
+// drop out of the rotation. Interleave stops when all producers run out
+// of values or when c can no longer consume.
+func Interleave(c Consumer, producers ...Producer) {
+	active := make([]Producer, len(producers))
+	copy(active, producers)
+	for len(active) > 0 {
+		idx := 0
+		for _, p := range active {
+			if !c.CanConsume() {
+				return
+			}
+			ptr := p.Produce()
+			if ptr == nil {
+				continue
+			}
+			c.Consume(ptr)
+			active[idx] = p
+			idx++
+		}
+		active = active[:idx]
+	}
+}
+
+// Concat has c consume all the values from each producer in turn. It
+// passes all the values of the first producer onto c, then all the values
+// of the second producer, and so on. Concat stops early if c can no
+// longer consume.
+func Concat(c Consumer, producers ...Producer) {
+	for _, p := range producers {
+		for c.CanConsume() {
+			ptr := p.Produce()
+			if ptr == nil {
+				break
+			}
+			c.Consume(ptr)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package consume_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedSlice(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consume.FeedSlice([]int{1, 2, 3, 4}, consume.AppendTo(&ints))
+	assert.Equal([]int{1, 2, 3, 4}, ints)
+	var strs []string
+	consume.FeedSlice(
+		[]string{"a", "b", "c"},
+		consume.Slice(consume.AppendTo(&strs), 0, 2))
+	assert.Equal([]string{"a", "b"}, strs)
+	var result []person
+	consume.FeedSlice(
+		people,
+		consume.TakeWhile(
+			consume.AppendTo(&result),
+			func(p *person) bool { return p.Age > 40 }))
+	assert.Equal(people[:dillon], result)
+}
+
+func TestFeedSliceNotBulk(t *testing.T) {
+	assert := assert.New(t)
+	var total float64
+	var names []string
+	consume.FeedSlice(
+		[]float64{1.5, 2.5},
+		consume.ConsumerFunc(func(ptr interface{}) {
+			total += *ptr.(*float64)
+		}))
+	consume.FeedSlice(
+		people,
+		consume.ConsumerFunc(func(ptr interface{}) {
+			names = append(names, ptr.(*person).Name)
+		}))
+	assert.Equal(4.0, total)
+	assert.Equal([]string{"Mark", "Stoney", "Matt", "Dillon", "Beth"}, names)
+}
+
+func TestFeedSlicePanics(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	assert.Panics(func() { consume.FeedSlice(3, consume.AppendTo(&ints)) })
+	assert.Panics(func() { consume.FeedSlice(&ints, consume.Nil()) })
+}
+
+func TestFeedMap(t *testing.T) {
+	assert := assert.New(t)
+	var ages []int
+	consume.FeedMap(
+		map[string]int{"Mark": 50, "Beth": 54, "Matt": 46},
+		consume.AppendTo(&ages))
+	sort.Ints(ages)
+	assert.Equal([]int{46, 50, 54}, ages)
+	ages = nil
+	consume.FeedMap(
+		map[string]int{"Mark": 50, "Beth": 54, "Matt": 46},
+		consume.Slice(consume.AppendTo(&ages), 0, 2))
+	assert.Len(ages, 2)
+	assert.Panics(func() { consume.FeedMap(ages, consume.AppendTo(&ages)) })
+}
+
+func TestConsumeFromChannel(t *testing.T) {
+	assert := assert.New(t)
+	ch := make(chan person, len(people))
+	for _, p := range people {
+		ch <- p
+	}
+	var result []person
+	consume.ConsumeFromChannel(
+		ch, consume.Slice(consume.AppendTo(&result), 0, 2))
+	assert.Equal(people[:2], result)
+	close(ch)
+	result = nil
+	var recvOnly <-chan person = ch
+	consume.ConsumeFromChannel(recvOnly, consume.AppendTo(&result))
+	assert.Equal(people[2:], result)
+	assert.Panics(func() {
+		consume.ConsumeFromChannel(people, consume.AppendTo(&result))
+	})
+	assert.Panics(func() {
+		consume.ConsumeFromChannel(
+			make(chan<- person), consume.AppendTo(&result))
+	})
+}
+
+func TestFeedFunc(t *testing.T) {
+	assert := assert.New(t)
+	var squares []int
+	var square int
+	consume.FeedFunc(
+		func(i int) (interface{}, bool) {
+			if i == 5 {
+				return nil, false
+			}
+			square = i * i
+			return &square, true
+		},
+		consume.AppendTo(&squares))
+	assert.Equal([]int{0, 1, 4, 9, 16}, squares)
+	var ones []int
+	one := 1
+	consume.FeedFunc(
+		func(i int) (interface{}, bool) { return &one, true },
+		consume.Slice(consume.AppendTo(&ones), 0, 3))
+	assert.Equal([]int{1, 1, 1}, ones)
+}
+
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+func TestFeedRange(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consume.FeedRange(2, 11, 3, consume.AppendTo(&ints))
+	assert.Equal([]int{2, 5, 8}, ints)
+	ints = nil
+	consume.FeedRange(10, 0, -4, consume.AppendTo(&ints))
+	assert.Equal([]int{10, 6, 2}, ints)
+	ints = nil
+	consume.FeedRange(0, 100, 1, consume.Slice(consume.AppendTo(&ints), 0, 2))
+	assert.Equal([]int{0, 1}, ints)
+	ints = nil
+	consume.FeedRange(5, 5, 1, consume.AppendTo(&ints))
+	assert.Empty(ints)
+
+	// Ranges near the limits of int must not wrap around.
+	ints = nil
+	consume.FeedRange(
+		maxInt-1, maxInt, 5, consume.Slice(consume.AppendTo(&ints), 0, 10))
+	assert.Equal([]int{maxInt - 1}, ints)
+	ints = nil
+	consume.FeedRange(
+		minInt+1, minInt, -5, consume.Slice(consume.AppendTo(&ints), 0, 10))
+	assert.Equal([]int{minInt + 1}, ints)
+	ints = nil
+	consume.FeedRange(
+		minInt, maxInt, maxInt, consume.Slice(consume.AppendTo(&ints), 0, 10))
+	assert.Equal([]int{minInt, -1, maxInt - 1}, ints)
+	assert.Panics(func() {
+		consume.FeedRange(0, 5, 0, consume.AppendTo(&ints))
+	})
+}
+
+func TestInterleave(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consume.Interleave(
+		consume.AppendTo(&ints),
+		intProducer(1, 2, 3),
+		intProducer(),
+		intProducer(10),
+		intProducer(100, 200))
+	assert.Equal([]int{1, 10, 100, 2, 200, 3}, ints)
+	ints = nil
+	consume.Interleave(
+		consume.Slice(consume.AppendTo(&ints), 0, 3),
+		intProducer(1, 2, 3),
+		intProducer(10, 20))
+	assert.Equal([]int{1, 10, 2}, ints)
+	consume.Interleave(consume.AppendTo(&ints))
+}
+
+func TestConcat(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consume.Concat(
+		consume.AppendTo(&ints),
+		intProducer(1, 2),
+		intProducer(),
+		intProducer(10, 20))
+	assert.Equal([]int{1, 2, 10, 20}, ints)
+	ints = nil
+	third := intProducer(100)
+	consume.Concat(
+		consume.Slice(consume.AppendTo(&ints), 0, 3),
+		intProducer(1, 2),
+		intProducer(10, 20),
+		third)
+	assert.Equal([]int{1, 2, 10}, ints)
+	assert.Equal(100, *third.Produce().(*int))
+}
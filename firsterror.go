@@ -0,0 +1,30 @@
+package consume
+
+// FirstError returns an ErrConsumer of *error values for fail fast
+// pipelines. The returned consumer ignores nil errors. When it consumes
+// its first non-nil error, it stores that error at errPtr, returns it
+// from Consume, and its CanConsume method returns false from then on.
+// FirstError leaves errPtr unchanged until then.
+func FirstError(errPtr *error) ErrConsumer {
+	return &firstErrorConsumer{errPtr: errPtr}
+}
+
+type firstErrorConsumer struct {
+	errPtr *error
+	done   bool
+}
+
+func (f *firstErrorConsumer) CanConsume() bool {
+	return !f.done
+}
+
+func (f *firstErrorConsumer) Consume(ptr interface{}) error {
+	mustCanConsumeE(f)
+	err := *ptr.(*error)
+	if err == nil {
+		return nil
+	}
+	*f.errPtr = err
+	f.done = true
+	return err
+}
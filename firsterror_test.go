@@ -0,0 +1,26 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirstError(t *testing.T) {
+	assert := assert.New(t)
+	var err error
+	c := consume.FirstError(&err)
+	var noErr error
+	assert.NoError(c.Consume(&noErr))
+	assert.True(c.CanConsume())
+	assert.NoError(err)
+	first := errors.New("first")
+	assert.Equal(first, c.Consume(&first))
+	assert.False(c.CanConsume())
+	assert.Equal(first, err)
+	second := errors.New("second")
+	assert.Panics(func() { c.Consume(&second) })
+	assert.Equal(first, err)
+}
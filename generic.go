@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package consume
+
+// Filter returns a Filterer that calls f to filter values. f works like
+// the filter functions passed to MapFilter, but since it is typed,
+// calling it involves no reflection or memory allocation.
+func Filter[T any](f func(ptr *T) bool) Filterer {
+	return typedFilterer[T](f)
+}
+
+// Map returns a Mapper that calls f to map values. f works like the
+// map functions passed to MapFilter: it stores the mapped value in dest
+// and returns false if the value should be filtered out instead. The Map
+// method of returned Mapper returns nil for values that f filters out.
+// Since f is typed, calling it involves no reflection or memory
+// allocation.
+func Map[T, U any](f func(src *T, dest *U) bool) Mapper {
+	return &typedMapper[T, U]{f: f}
+}
+
+type typedFilterer[T any] func(ptr *T) bool
+
+func (t typedFilterer[T]) Filter(ptr interface{}) bool {
+	return t(ptr.(*T))
+}
+
+type typedMapper[T, U any] struct {
+	f      func(src *T, dest *U) bool
+	result U
+}
+
+func (t *typedMapper[T, U]) Map(ptr interface{}) interface{} {
+	if !t.f(ptr.(*T), &t.result) {
+		return nil
+	}
+	return &t.result
+}
+
+func (t *typedMapper[T, U]) Clone() Mapper {
+	return &typedMapper[T, U]{f: t.f}
+}
@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedFilterAndMap(t *testing.T) {
+	assert := assert.New(t)
+	var names []string
+	consume.FeedSlice(
+		people,
+		consume.MapFilter(
+			consume.AppendTo(&names),
+			consume.Filter(func(ptr *person) bool { return ptr.Age > 40 }),
+			consume.Map(func(src *person, dest *string) bool {
+				*dest = src.Name
+				return src.Name != "Matt"
+			})))
+	assert.Equal([]string{"Mark", "Stoney", "Beth"}, names)
+}
+
+func TestTypedMapNoAllocs(t *testing.T) {
+	assert := assert.New(t)
+	mf := consume.NewMapFilterer(
+		consume.Filter(func(ptr *int) bool { return *ptr%2 == 0 }),
+		consume.Map(func(src *int, dest *int64) bool {
+			*dest = int64(*src) * 3
+			return true
+		}))
+	ctx := mf.NewContext()
+	x := 4
+	assert.Equal(int64(12), *mf.MapFilterWithContext(ctx, &x).(*int64))
+	allocs := testing.AllocsPerRun(100, func() {
+		mf.MapFilterWithContext(ctx, &x)
+	})
+	assert.Zero(allocs)
+}
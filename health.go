@@ -0,0 +1,45 @@
+package consume
+
+// Health reports whether a consumer is healthy. Sinks that write to I/O
+// such as ToYAML, ToCSV, ToAvro, ToEventStream, and Encrypted implement
+// Health so that services can include the health of their pipelines in
+// readiness checks. Compose implements Health by checking the consumers
+// passed to it. Use CheckHealth to check the health of a whole pipeline.
+type Health interface {
+
+	// Healthy returns nil if the consumer is healthy or the error that
+	// made it unhealthy otherwise.
+	Healthy() error
+}
+
+// CheckHealth returns c.Healthy() if c implements Health. Otherwise, if
+// c is a stage from this package such as MapFilter or Slice, CheckHealth
+// returns the first error from checking the consumers that c passes
+// values onto. CheckHealth returns nil if c is healthy or can't report
+// its health.
+func CheckHealth(c Consumer) error {
+	return checkHealth(c)
+}
+
+// checkHealth works like CheckHealth except that stage can also be an
+// ErrConsumer.
+func checkHealth(stage interface{}) error {
+	if h, ok := stage.(Health); ok {
+		return h.Healthy()
+	}
+	for _, child := range childStages(stage) {
+		if err := checkHealth(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *multiConsumer) Healthy() error {
+	for _, consumer := range m.all {
+		if err := CheckHealth(consumer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
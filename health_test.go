@@ -0,0 +1,81 @@
+package consume_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	assert := assert.New(t)
+	var buf bytes.Buffer
+	var ints []int
+	avro := consume.ToAvro(&buf, `"string"`)
+	consumer := consume.Compose(consume.AppendTo(&ints), avro)
+	assert.NoError(consume.CheckHealth(consumer))
+	assert.NoError(consume.CheckHealth(consume.AppendTo(&ints)))
+	x := 3
+	consumer.Consume(&x)
+	assert.Equal([]int{3}, ints)
+	assert.False(avro.CanConsume())
+	assert.EqualError(
+		consume.CheckHealth(consumer),
+		"consume: cannot encode int as avro string")
+}
+
+func TestHealthWrapped(t *testing.T) {
+	assert := assert.New(t)
+	yaml := consume.ToYAML(errorWriter{})
+	var ints []int
+	p := consume.NewPipeline()
+	consumer := consume.Compose(
+		consume.AppendTo(&ints),
+		p.Stage(consume.Counted(
+			consume.Slice(
+				consume.MapFilter(
+					yaml, func(ptr *int) bool { return *ptr%2 == 0 }),
+				0,
+				10),
+			func(delta int) {})))
+	assert.NoError(consume.CheckHealth(consumer))
+	consume.FeedRange(0, 5, 1, consumer)
+	yaml.Finalize()
+	assert.Error(consume.CheckHealth(yaml))
+	assert.Error(consume.CheckHealth(consumer))
+	assert.Error(consume.CheckHealth(
+		consume.MapFilter(yaml, func(ptr *int) bool { return true })))
+}
+
+func TestHealthThroughWrappers(t *testing.T) {
+	assert := assert.New(t)
+	yaml := consume.ToYAML(errorWriter{})
+	var err error
+	consumer := consume.ScatterWeighted(
+		[]int{1}, consume.FromErrConsumer(consume.ToErrConsumer(yaml), &err))
+	consume.FeedRange(0, 5, 1, consumer)
+	yaml.Finalize()
+	assert.Error(consume.CheckHealth(consumer))
+}
+
+func TestHealthWriteErrors(t *testing.T) {
+	assert := assert.New(t)
+	assert.Error(consume.CheckHealth(
+		consume.ToAvro(errorWriter{}, `"string"`)))
+	csv := consume.ToCSV(errorWriter{}, nil)
+	assert.NoError(consume.CheckHealth(csv))
+	consume.FeedSlice(people[:1], csv)
+	csv.Finalize()
+	assert.Error(consume.CheckHealth(csv))
+	encrypted := consume.Encrypted(
+		func(w io.Writer) consume.ConsumeFinalizer {
+			return consume.ToYAML(w)
+		},
+		errorWriter{},
+		make([]byte, 16))
+	assert.NoError(consume.CheckHealth(encrypted))
+	encrypted.Finalize()
+	assert.Error(consume.CheckHealth(encrypted))
+}
@@ -0,0 +1,89 @@
+package consume
+
+import (
+	"sync"
+)
+
+// KeyStore stores the idempotency keys of values that were successfully
+// delivered. Implementations typically persist keys in a database so
+// that they survive restarts.
+type KeyStore interface {
+
+	// Contains returns true if key was added to this store.
+	Contains(key interface{}) (bool, error)
+
+	// Add adds key to this store.
+	Add(key interface{}) error
+}
+
+// NewMemoryKeyStore returns a KeyStore that keeps keys in memory. Keys
+// must support equality. The returned KeyStore is safe to use with
+// multiple goroutines.
+func NewMemoryKeyStore() KeyStore {
+	return &memoryKeyStore{keys: make(map[interface{}]struct{})}
+}
+
+// IdempotentSink returns an ErrConsumer that passes the values it
+// consumes onto sink skipping values whose idempotency key is already in
+// store. keyFunc extracts the key from a value. It takes a pointer to the
+// value and returns the key. keyFunc may also be a
+// func(ptr interface{}) interface{}. After sink consumes a value without
+// error, the returned consumer adds its key to store. If sink fails, the
+// key is not added so that a later retry of the same value goes through.
+// Consume returns errors from sink and from store. When store reports an
+// error looking up a key, the value is not passed onto sink. The
+// CanConsume method of returned consumer returns the same as
+// sink.CanConsume().
+func IdempotentSink(
+	sink ErrConsumer, keyFunc interface{}, store KeyStore) ErrConsumer {
+	return &idempotentConsumer{
+		ErrConsumer: sink,
+		keyFunc:     newKeyFunc(keyFunc),
+		store:       store,
+	}
+}
+
+type idempotentConsumer struct {
+	ErrConsumer
+	keyFunc keyFunc
+	store   KeyStore
+}
+
+func (i *idempotentConsumer) children() []interface{} {
+	return []interface{}{i.ErrConsumer}
+}
+
+func (i *idempotentConsumer) Consume(ptr interface{}) error {
+	mustCanConsumeE(i)
+	key := i.keyFunc.key(ptr)
+	delivered, err := i.store.Contains(key)
+	if err != nil {
+		return err
+	}
+	if delivered {
+		return nil
+	}
+	if err := i.ErrConsumer.Consume(ptr); err != nil {
+		return err
+	}
+	return i.store.Add(key)
+}
+
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys map[interface{}]struct{}
+}
+
+func (m *memoryKeyStore) Contains(key interface{}) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.keys[key]
+	return ok, nil
+}
+
+func (m *memoryKeyStore) Add(key interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[key] = struct{}{}
+	return nil
+}
@@ -0,0 +1,60 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotentSink(t *testing.T) {
+	assert := assert.New(t)
+	var delivered []string
+	failNext := true
+	sink := consume.ErrConsumerFunc(func(ptr interface{}) error {
+		p := ptr.(*person)
+		if p.Name == "Matt" && failNext {
+			failNext = false
+			return errors.New("unavailable")
+		}
+		delivered = append(delivered, p.Name)
+		return nil
+	})
+	store := consume.NewMemoryKeyStore()
+	c := consume.IdempotentSink(
+		sink, func(ptr *person) string { return ptr.Name }, store)
+	var errs []error
+	for _, i := range []int{mark, stoney, mark, matt, stoney, matt, matt} {
+		errs = append(errs, c.Consume(&people[i]))
+	}
+	assert.Equal(
+		[]error{nil, nil, nil, errors.New("unavailable"), nil, nil, nil},
+		errs)
+	assert.Equal([]string{"Mark", "Stoney", "Matt"}, delivered)
+	ok, err := store.Contains("Matt")
+	assert.True(ok)
+	assert.NoError(err)
+}
+
+func TestIdempotentSinkStoreError(t *testing.T) {
+	assert := assert.New(t)
+	var delivered []int
+	c := consume.IdempotentSink(
+		consume.ToErrConsumer(consume.AppendTo(&delivered)),
+		func(ptr interface{}) interface{} { return *ptr.(*int) },
+		failingKeyStore{})
+	x := 1
+	assert.EqualError(c.Consume(&x), "store down")
+	assert.Empty(delivered)
+}
+
+type failingKeyStore struct{}
+
+func (failingKeyStore) Contains(key interface{}) (bool, error) {
+	return false, errors.New("store down")
+}
+
+func (failingKeyStore) Add(key interface{}) error {
+	return errors.New("store down")
+}
@@ -0,0 +1,47 @@
+package consume
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveFilters(t *testing.T) {
+	assert := assert.New(t)
+	var calls [3]int
+	mf := AdaptiveFilters(
+		func(ptr *int) bool {
+			calls[0]++
+			return true
+		},
+		func(ptr *int) bool {
+			calls[1]++
+			return *ptr%2 == 0
+		},
+		func(ptr *int) bool {
+			calls[2]++
+			return *ptr%10 == 0
+		})
+	var result []int
+	consumer := MapFilter(AppendTo(&result), mf)
+	for i := 0; i < 10*kAdaptiveReorderEvery; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Len(result, kAdaptiveReorderEvery)
+	for i, value := range result {
+		assert.Equal(10*i, value)
+	}
+
+	// Once reordered, the most selective filter runs first
+	assert.Less(calls[0], 2*kAdaptiveReorderEvery+1)
+	assert.Less(calls[1], 3*kAdaptiveReorderEvery)
+	assert.Greater(calls[2], 9*kAdaptiveReorderEvery)
+}
+
+func TestAdaptiveFiltersPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		AdaptiveFilters(func(src *int, dest *string) bool { return true })
+	})
+	assert.Equal(nilMapFilterer{}, AdaptiveFilters())
+}
@@ -0,0 +1,91 @@
+package consume
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileMapFilter(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	compiled := Compile(MapFilter(
+		MapFilter(
+			AppendTo(&result),
+			func(src *int, dest *string) bool {
+				*dest = strconv.Itoa(*src)
+				return true
+			}),
+		func(ptr *int) bool { return *ptr%2 == 0 }))
+	mapFilter := compiled.(*mapFilterConsumer)
+	assert.Equal(2, mapFilter.mapFilters.size())
+	assert.IsType(&appendConsumer{}, mapFilter.Consumer)
+	for i := 0; i < 6; i++ {
+		compiled.Consume(&i)
+	}
+	assert.Equal([]string{"0", "2", "4"}, result)
+}
+
+func TestCompileSlice(t *testing.T) {
+	assert := assert.New(t)
+	bounds := [][4]int{
+		{2, 10, 3, 5},
+		{2, 6, 3, 10},
+		{-1, 10, -2, 4},
+		{5, 2, 0, 10},
+		{0, 10, 5, 2},
+		{1, 10, 0, int(^uint(0) >> 1)},
+		{int(^uint(0) >> 1), int(^uint(0) >> 1), 1, 10},
+	}
+	for _, b := range bounds {
+		var expected, actual []int
+		feedAll(Slice(Slice(AppendTo(&expected), b[2], b[3]), b[0], b[1]))
+		compiled := Compile(
+			Slice(Slice(AppendTo(&actual), b[2], b[3]), b[0], b[1]))
+		assert.IsType(&appendConsumer{}, compiled.(*sliceConsumer).consumer)
+		feedAll(compiled)
+		assert.Equal(expected, actual, "%v", b)
+	}
+}
+
+func TestCompileSliceEmptyInner(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	compiled := Compile(Slice(Slice(AppendTo(&ints), 0, 0), 2, 10))
+	assert.False(compiled.CanConsume())
+	compiled = Compile(Slice(Slice(AppendTo(&ints), 3, -1), 2, 10))
+	assert.False(compiled.CanConsume())
+}
+
+func TestCompileCompose(t *testing.T) {
+	assert := assert.New(t)
+	var a, b, c []int
+	compiled := Compile(Compose(
+		Compose(AppendTo(&a), Slice(AppendTo(&b), 0, 2)),
+		MapFilter(
+			Compose(Slice(Slice(AppendTo(&c), 1, 10), 1, 10)),
+			func(ptr *int) bool { return true })))
+	assert.Len(compiled.(*multiConsumer).consumers, 3)
+	for i := 0; i < 5; i++ {
+		compiled.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2, 3, 4}, a)
+	assert.Equal([]int{0, 1}, b)
+	assert.Equal([]int{2, 3, 4}, c)
+}
+
+func TestCompileUnknown(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	c := AppendTo(&ints)
+	assert.Same(c, Compile(c))
+}
+
+// feedAll feeds 0, 1, 2, ... to consumer until it can't consume. It gives
+// up after 1000 values.
+func feedAll(consumer Consumer) {
+	for i := 0; i < 1000 && consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+	}
+}
@@ -104,3 +104,62 @@ func TestMapFiltererIndependence(t *testing.T) {
 	assert.Equal("43", *fortyThreeStrPtr)
 	assert.Equal("101", *oneHundredOneStrPtr)
 }
+
+func TestAppendToAllocs(t *testing.T) {
+	assert := assert.New(t)
+	ints := make([]int, 0, 1000)
+	consumer := MapFilter(
+		Compose(
+			Slice(AppendTo(&ints), 0, 1000),
+			SliceStep(AppendTo(&ints), 0, 1000, 2)),
+		evenFilterer{})
+	i := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		consumer.Consume(&i)
+	})
+	assert.Zero(allocs)
+	assert.Len(ints, 152)
+	for _, c := range []Consumer{
+		consumer,
+		Slice(nilConsumer{}, 0, 1),
+		SliceStep(nilConsumer{}, 0, 1, 2),
+		Compose(nilConsumer{}, nilConsumer{}),
+		TakeWhile(nilConsumer{}, evenFilterer{}),
+	} {
+		_, ok := c.(valueConsumer)
+		assert.True(ok)
+	}
+}
+
+// evenFilterer filters ints without reflection.
+type evenFilterer struct{}
+
+func (e evenFilterer) Filter(ptr interface{}) bool {
+	return *ptr.(*int)%2 == 0
+}
+
+func TestMapFilterContext(t *testing.T) {
+	assert := assert.New(t)
+	toString := NewMapFilterer(
+		func(ptr *int) bool {
+			return *ptr%2 == 0
+		},
+		func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		})
+	ctx1 := toString.NewContext()
+	ctx2 := toString.NewContext()
+	i := 4
+	fourPtr := toString.MapFilterWithContext(ctx1, &i).(*string)
+	i = 6
+	sixPtr := toString.MapFilterWithContext(ctx2, &i).(*string)
+	i = 7
+	assert.Nil(toString.MapFilterWithContext(ctx2, &i))
+	assert.Equal("4", *fourPtr)
+	assert.Equal("6", *sixPtr)
+	assert.Panics(func() {
+		toString.MapFilterWithContext(NewMapFilterer().NewContext(), &i)
+	})
+	assert.Same(&i, NewMapFilterer().MapFilterWithContext(ctx1, &i))
+}
@@ -0,0 +1,43 @@
+package consume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiled(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+	var ints []int
+	consumer := Profiled(
+		MapFilter(
+			AppendTo(&ints),
+			func(ptr *int) bool {
+				now = now.Add(time.Duration(*ptr) * time.Second)
+				return true
+			}),
+		2)
+	consumer.(*profiledConsumer).clock = clockFunc(func() time.Time {
+		return now
+	})
+	FeedRange(1, 6, 1, consumer)
+	assert.Equal([]int{1, 2, 3, 4, 5}, ints)
+	report := consumer.(ProfileReporter).ProfileReport()
+	assert.Equal(
+		ProfileReport{
+			Stage:    "MapFilter",
+			Consumed: 5,
+			Sampled:  3,
+			Total:    9 * time.Second,
+			Min:      time.Second,
+			Max:      5 * time.Second,
+		},
+		report)
+	assert.Equal(3*time.Second, report.Avg())
+	assert.Equal(15*time.Second, report.Estimated())
+	assert.Panics(func() { Profiled(Nil(), 0) })
+	empty := ProfileReport{}
+	assert.Zero(empty.Avg())
+}
@@ -0,0 +1,116 @@
+package consume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitPerKey(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+	var result []int
+	consumer := RateLimitPerKey(
+		AppendTo(&result),
+		func(ptr *int) bool { return *ptr%2 == 0 },
+		time.Minute,
+		2)
+	consumer.(*rateLimitPerKeyConsumer).clock = clockFunc(func() time.Time {
+		return now
+	})
+	for i := 0; i < 6; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2, 3}, result)
+	now = now.Add(time.Minute)
+	for i := 6; i < 10; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2, 3, 6, 7}, result)
+	now = now.Add(time.Hour)
+	for i := 10; i < 20; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2, 3, 6, 7, 10, 11, 12, 13}, result)
+}
+
+func TestRateLimitPerKeyForgetsIdleKeys(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+	var result []int
+	consumer := RateLimitPerKey(
+		AppendTo(&result), func(ptr *int) int { return *ptr }, time.Minute, 2)
+	r := consumer.(*rateLimitPerKeyConsumer)
+	r.clock = clockFunc(func() time.Time { return now })
+	for i := 0; i < 100; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Len(r.arrivals, 100)
+	now = now.Add(2 * time.Minute)
+	x := 100
+	consumer.Consume(&x)
+	assert.Len(r.arrivals, 1)
+	assert.Len(result, 101)
+}
+
+func TestRateLimitPerKeyPanics(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	key := func(ptr *int) int { return *ptr }
+	assert.Panics(func() { RateLimitPerKey(AppendTo(&result), key, 0, 1) })
+	assert.Panics(func() {
+		RateLimitPerKey(AppendTo(&result), key, time.Second, 0)
+	})
+	assert.Panics(func() {
+		RateLimitPerKey(AppendTo(&result), 3, time.Second, 1)
+	})
+	assert.Panics(func() {
+		RateLimitPerKey(
+			AppendTo(&result), func(x int) int { return x }, time.Second, 1)
+	})
+}
+
+func TestThrottle(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+	var result, overflow []int
+	consumer := Throttle(
+		AppendTo(&result),
+		2,
+		time.Minute,
+		Slice(AppendTo(&overflow), 0, 1))
+	consumer.(*throttleConsumer).clock = clockFunc(func() time.Time {
+		return now
+	})
+	for i := 0; i < 4; i++ {
+		consumer.Consume(&i)
+	}
+	now = now.Add(59 * time.Second)
+	i := 4
+	consumer.Consume(&i)
+	now = now.Add(time.Second)
+	for i := 5; i < 8; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 5, 6}, result)
+	assert.Equal([]int{2}, overflow)
+}
+
+func TestThrottleDrop(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := Throttle(AppendTo(&result), 3, time.Hour, nil)
+	for i := 0; i < 5; i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.True(consumer.CanConsume())
+}
+
+func TestThrottlePanics(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	assert.Panics(func() { Throttle(AppendTo(&result), 0, time.Hour, nil) })
+	assert.Panics(func() { Throttle(AppendTo(&result), 1, 0, nil) })
+}
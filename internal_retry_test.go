@@ -0,0 +1,137 @@
+package consume
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errPermanent = errors.New("permanent")
+)
+
+func TestRetry(t *testing.T) {
+	assert := assert.New(t)
+	sink := &flakySink{failures: map[int][]error{
+		1: {errTransient, errTransient},
+		2: {errTransient, errTransient, errTransient, errTransient},
+	}}
+	c := Retry(sink, RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+	})
+	sleeps := fakeSleep(c)
+	for i := 0; i < 3; i++ {
+		err := c.Consume(&i)
+		if i == 2 {
+			assert.Equal(errTransient, err)
+		} else {
+			assert.NoError(err)
+		}
+	}
+	assert.Equal([]int{0, 1}, sink.consumed)
+	assert.Equal(
+		[]time.Duration{
+			time.Second, 2 * time.Second,
+			time.Second, 2 * time.Second, 3 * time.Second,
+		},
+		*sleeps)
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	assert := assert.New(t)
+	sink := &flakySink{failures: map[int][]error{
+		0: {errPermanent, errTransient},
+	}}
+	c := Retry(sink, RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return err == errTransient },
+	})
+	sleeps := fakeSleep(c)
+	assert.Equal(errPermanent, c.Consume(new(int)))
+	assert.Empty(*sleeps)
+}
+
+func TestRetryDeadLetter(t *testing.T) {
+	assert := assert.New(t)
+	var dead []DeadLetterValue
+	sink := &flakySink{failures: map[int][]error{
+		1: {errTransient, errPermanent},
+	}}
+	c := Retry(sink, RetryPolicy{
+		MaxAttempts: 2,
+		Jitter:      0.5,
+		DeadLetter:  AppendTo(&dead),
+	})
+	fakeSleep(c)
+	for i := 0; i < 3; i++ {
+		assert.NoError(c.Consume(&i))
+	}
+	assert.Equal([]int{0, 2}, sink.consumed)
+	assert.Len(dead, 1)
+	assert.Equal(1, *dead[0].Value.(*int))
+	assert.Equal(errPermanent, dead[0].Err)
+}
+
+// fakeSleep makes c, which comes from Retry, record how long it was
+// asked to sleep instead of sleeping.
+func fakeSleep(c ErrConsumer) *[]time.Duration {
+	var sleeps []time.Duration
+	c.(*retryConsumer).sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+	}
+	return &sleeps
+}
+
+// flakySink fails consuming an int with the errors in failures for that
+// int, one error per attempt, before succeeding.
+type flakySink struct {
+	failures map[int][]error
+	consumed []int
+}
+
+func (f *flakySink) CanConsume() bool {
+	return true
+}
+
+func (f *flakySink) Consume(ptr interface{}) error {
+	x := *ptr.(*int)
+	if errs := f.failures[x]; len(errs) > 0 {
+		f.failures[x] = errs[1:]
+		return errs[0]
+	}
+	f.consumed = append(f.consumed, x)
+	return nil
+}
+
+func TestDeadLetter(t *testing.T) {
+	assert := assert.New(t)
+	var dead []DeadLetterValue
+	sink := &flakySink{failures: map[int][]error{
+		1: {errTransient},
+		3: {errTransient, errTransient, errTransient},
+	}}
+	retry := Retry(sink, RetryPolicy{MaxAttempts: 2})
+	fakeSleep(retry)
+	c := DeadLetter(retry, AppendTo(&dead))
+	for i := 0; i < 5; i++ {
+		c.Consume(&i)
+	}
+	assert.Equal([]int{0, 1, 2, 4}, sink.consumed)
+	assert.Len(dead, 1)
+	assert.Equal(3, *dead[0].Value.(*int))
+	assert.Equal(errTransient, dead[0].Err)
+}
+
+func TestDeadLetterDropped(t *testing.T) {
+	assert := assert.New(t)
+	sink := &flakySink{failures: map[int][]error{0: {errPermanent}}}
+	c := DeadLetter(sink, Nil())
+	c.Consume(new(int))
+	assert.True(c.CanConsume())
+	assert.Empty(sink.consumed)
+}
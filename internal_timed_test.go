@@ -0,0 +1,38 @@
+package consume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clockFunc adapts a function to a Clock so that tests can control time.
+type clockFunc func() time.Time
+
+func (c clockFunc) Now() time.Time {
+	return c()
+}
+
+func TestTimed(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+	var durations []time.Duration
+	consumer := Timed(
+		Slice(
+			ConsumerFunc(func(ptr interface{}) {
+				now = now.Add(time.Duration(*ptr.(*int)) * time.Second)
+			}),
+			0,
+			3),
+		func(d time.Duration) { durations = append(durations, d) })
+	consumer.(*timedConsumer).clock = clockFunc(func() time.Time {
+		return now
+	})
+	for i := 1; consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal(
+		[]time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		durations)
+}
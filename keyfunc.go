@@ -0,0 +1,55 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// keyFunc wraps a function that extracts a key from a value. The
+// function takes a pointer to the value and returns the key. It may
+// also be a func(ptr interface{}) interface{} which avoids reflection.
+// When the function takes a pointer to something other than an Envelope,
+// it gets the value inside each Envelope.
+type keyFunc struct {
+	raw    func(ptr interface{}) interface{}
+	value  reflect.Value
+	unwrap bool
+}
+
+func newKeyFunc(f interface{}) keyFunc {
+	if raw, ok := f.(func(ptr interface{}) interface{}); ok {
+		return keyFunc{raw: raw}
+	}
+	ftype := reflect.TypeOf(f)
+	if ftype == nil || ftype.Kind() != reflect.Func {
+		panic("Parameter must be a function")
+	}
+	if ftype.NumIn() != 1 || ftype.In(0).Kind() != reflect.Ptr {
+		panic("Function parameter must take one pointer argument")
+	}
+	if ftype.NumOut() != 1 {
+		panic("Function parameter must return one value")
+	}
+	return keyFunc{
+		value: reflect.ValueOf(f), unwrap: ftype.In(0) != envelopePtrType}
+}
+
+// newMapKeyFunc works like newKeyFunc except that it panics if f returns
+// keys that can't be map keys.
+func newMapKeyFunc(f interface{}) keyFunc {
+	result := newKeyFunc(f)
+	if result.raw == nil && !result.value.Type().Out(0).Comparable() {
+		panic("Function parameter must return keys that support equality")
+	}
+	return result
+}
+
+func (k keyFunc) key(ptr interface{}) interface{} {
+	if k.raw != nil {
+		return k.raw(ptr)
+	}
+	if env, ok := ptr.(*Envelope); ok && k.unwrap {
+		ptr = env.Value
+	}
+	params := [...]reflect.Value{reflect.ValueOf(ptr)}
+	return k.value.Call(params[:])[0].Interface()
+}
@@ -0,0 +1,223 @@
+package consume
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"sync"
+)
+
+// ManifestEntry describes one artifact that a sink produced.
+type ManifestEntry struct {
+
+	// Name identifies the artifact such as a file name or URL.
+	Name string `json:"name"`
+
+	// Rows is the number of values the sink consumed.
+	Rows int64 `json:"rows"`
+
+	// Bytes is the number of bytes the sink wrote.
+	Bytes int64 `json:"bytes"`
+
+	// SHA256 is the SHA-256 checksum of what the sink wrote in hex.
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest records the artifacts that sinks produce during a run so that
+// a machine-readable summary of the run can be written when it is done.
+// Manifest instances are safe to use with multiple goroutines.
+type Manifest struct {
+	mu      sync.Mutex
+	entries []ManifestEntry
+}
+
+// NewManifest returns a new, empty Manifest.
+func NewManifest() *Manifest {
+	return &Manifest{}
+}
+
+// Track returns a function that works like newSink except that the
+// ConsumeFinalizer it returns records a ManifestEntry named name in m
+// once its output is delivered. Pass the returned function where a
+// newSink function is expected such as to ToFileAtomic, Encrypted, or
+// Transactional. Under ToFileAtomic or Transactional, the output is
+// delivered when committed, so nothing is recorded if the output is
+// rolled back or the commit fails. Otherwise, the output is delivered
+// when the sink is finalized without reporting an error through Health.
+// For example:
+//
+//	cf := consume.ToFileAtomic(
+//		"people.csv", manifest.Track("people.csv", newCSVSink))
+func (m *Manifest) Track(
+	name string,
+	newSink func(w io.Writer) ConsumeFinalizer,
+) func(w io.Writer) ConsumeFinalizer {
+	return func(w io.Writer) ConsumeFinalizer {
+		result := &manifestSink{
+			manifest: m,
+			name:     name,
+			hash:     sha256.New(),
+		}
+		result.counter = &countingWriter{w: io.MultiWriter(w, result.hash)}
+		result.ConsumeFinalizer = newSink(result.counter)
+		return result
+	}
+}
+
+// Entries returns the entries recorded so far in the order that their
+// output was delivered.
+func (m *Manifest) Entries() []ManifestEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]ManifestEntry, len(m.entries))
+	copy(result, m.entries)
+	return result
+}
+
+// WriteOnFinalize returns a ConsumeFinalizer that works like cf except
+// that after finalizing cf, Finalize writes m to w as JSON. The JSON is
+// an object with an "artifacts" field holding the entries of m. If
+// writing the JSON fails, the Healthy method of the returned consumer
+// reports the error.
+func (m *Manifest) WriteOnFinalize(
+	cf ConsumeFinalizer, w io.Writer) ConsumeFinalizer {
+	return &manifestWriter{ConsumeFinalizer: cf, manifest: m, w: w}
+}
+
+func (m *Manifest) add(entry ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+}
+
+// commitObserver is implemented by sinks that must know whether their
+// output was delivered. ToFileAtomic and Transactional call awaitCommit
+// on the sink that newSink returns and call committed on it once the
+// output is committed.
+type commitObserver interface {
+
+	// awaitCommit tells the sink that finalizing it does not deliver its
+	// output.
+	awaitCommit()
+
+	// committed tells the sink that its output was delivered.
+	committed()
+}
+
+// awaitCommit calls awaitCommit on sink if it is a commitObserver.
+func awaitCommit(sink ConsumeFinalizer) {
+	if o, ok := sink.(commitObserver); ok {
+		o.awaitCommit()
+	}
+}
+
+// notifyCommitted calls committed on sink if it is a commitObserver.
+func notifyCommitted(sink ConsumeFinalizer) {
+	if o, ok := sink.(commitObserver); ok {
+		o.committed()
+	}
+}
+
+type manifestSink struct {
+	ConsumeFinalizer
+	manifest  *Manifest
+	name      string
+	counter   *countingWriter
+	hash      hash.Hash
+	rows      int64
+	finalized bool
+	awaiting  bool
+	recorded  bool
+}
+
+func (m *manifestSink) children() []interface{} {
+	return []interface{}{m.ConsumeFinalizer}
+}
+
+func (m *manifestSink) Consume(ptr interface{}) {
+	MustCanConsume(m)
+	m.ConsumeFinalizer.Consume(ptr)
+	m.rows++
+}
+
+func (m *manifestSink) Finalize() {
+	if m.finalized {
+		return
+	}
+	m.finalized = true
+	m.ConsumeFinalizer.Finalize()
+	if !m.awaiting && CheckHealth(m.ConsumeFinalizer) == nil {
+		m.record()
+	}
+}
+
+func (m *manifestSink) awaitCommit() {
+	m.awaiting = true
+}
+
+func (m *manifestSink) committed() {
+	m.record()
+}
+
+func (m *manifestSink) record() {
+	if m.recorded {
+		return
+	}
+	m.recorded = true
+	m.manifest.add(ManifestEntry{
+		Name:   m.name,
+		Rows:   m.rows,
+		Bytes:  m.counter.n,
+		SHA256: hex.EncodeToString(m.hash.Sum(nil)),
+	})
+}
+
+func (m *manifestSink) Healthy() error {
+	return CheckHealth(m.ConsumeFinalizer)
+}
+
+type manifestWriter struct {
+	ConsumeFinalizer
+	manifest  *Manifest
+	w         io.Writer
+	err       error
+	finalized bool
+}
+
+func (m *manifestWriter) children() []interface{} {
+	return []interface{}{m.ConsumeFinalizer}
+}
+
+func (m *manifestWriter) Finalize() {
+	if m.finalized {
+		return
+	}
+	m.finalized = true
+	m.ConsumeFinalizer.Finalize()
+	encoder := json.NewEncoder(m.w)
+	encoder.SetIndent("", "  ")
+	m.err = encoder.Encode(struct {
+		Artifacts []ManifestEntry `json:"artifacts"`
+	}{Artifacts: m.manifest.Entries()})
+}
+
+func (m *manifestWriter) Healthy() error {
+	if m.err != nil {
+		return m.err
+	}
+	return CheckHealth(m.ConsumeFinalizer)
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,105 @@
+package consume_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	manifest := consume.NewManifest()
+	var yamlOut strings.Builder
+	var manifestOut strings.Builder
+	csvSink := consume.ToFileAtomic(
+		filepath.Join(dir, "people.csv"),
+		manifest.Track("people.csv", newCSVSink))
+	yamlSink := manifest.Track("people.yaml", consume.ToYAML)(&yamlOut)
+	cf := manifest.WriteOnFinalize(
+		&finalizer{
+			Consumer: consume.Compose(csvSink, yamlSink),
+			finalize: func() {
+				csvSink.Finalize()
+				yamlSink.Finalize()
+			},
+		},
+		&manifestOut)
+	consume.FeedSlice(people[:2], cf)
+	assert.Empty(manifest.Entries())
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.NoError(consume.CheckHealth(cf))
+	csv := "Name,Age\nMark,50\nStoney,49\n"
+	assertFileContents(t, filepath.Join(dir, "people.csv"), csv)
+	assert.Equal(
+		[]consume.ManifestEntry{
+			{
+				Name:   "people.csv",
+				Rows:   2,
+				Bytes:  int64(len(csv)),
+				SHA256: sha256Hex(csv),
+			},
+			{
+				Name:   "people.yaml",
+				Rows:   2,
+				Bytes:  int64(yamlOut.Len()),
+				SHA256: sha256Hex(yamlOut.String()),
+			},
+		},
+		manifest.Entries())
+	assert.Contains(manifestOut.String(), `"name": "people.yaml"`)
+	assert.Contains(manifestOut.String(), `"rows": 2`)
+}
+
+func TestManifestNotDelivered(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	manifest := consume.NewManifest()
+	var out strings.Builder
+	tx := consume.Transactional(manifest.Track("a.csv", newCSVSink), &out)
+	consume.FeedSlice(people[:1], tx)
+	assert.NoError(tx.Rollback())
+	assert.Empty(out.String())
+	atomic := consume.ToFileAtomic(
+		filepath.Join(dir, "b.yaml"),
+		manifest.Track("b.yaml", func(w io.Writer) consume.ConsumeFinalizer {
+			return consume.ToYAML(errorWriter{})
+		}))
+	consume.FeedSlice(people[:1], atomic)
+	atomic.Finalize()
+	assert.Error(consume.CheckHealth(atomic))
+	failing := manifest.Track("c.yaml", consume.ToYAML)(errorWriter{})
+	consume.FeedSlice(people[:1], failing)
+	failing.Finalize()
+	assert.Empty(manifest.Entries())
+
+	tx = consume.Transactional(manifest.Track("d.csv", newCSVSink), &out)
+	consume.FeedSlice(people[:1], tx)
+	assert.NoError(tx.Commit())
+	entries := manifest.Entries()
+	if assert.Len(entries, 1) {
+		assert.Equal("d.csv", entries[0].Name)
+		assert.Equal(int64(1), entries[0].Rows)
+	}
+}
+
+func TestManifestWriteError(t *testing.T) {
+	assert := assert.New(t)
+	cf := consume.NewManifest().WriteOnFinalize(
+		&finalizer{Consumer: consume.Nil(), finalize: func() {}},
+		errorWriter{})
+	cf.Finalize()
+	assert.Error(consume.CheckHealth(cf))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package mappers
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/keep94/consume"
+)
+
+// ConvertField returns a Mapper that maps a pointer to a struct to a
+// pointer to a copy of that struct with the field named field replaced by
+// convert(value of field). If convert returns an error, the value is
+// filtered out. What convert returns must be assignable or convertible to
+// the type of the field; otherwise the Map method of the returned Mapper
+// panics. Map also panics if the value is not a struct or has no field
+// named field. The lookup of field is done once for each struct type and
+// then cached.
+func ConvertField(
+	field string,
+	convert func(value interface{}) (interface{}, error)) consume.Mapper {
+	return &convertFieldMapper{
+		fields:  newFieldCache(field),
+		convert: convert,
+	}
+}
+
+type convertFieldMapper struct {
+	fields  *fieldCache
+	convert func(value interface{}) (interface{}, error)
+	result  structCopy
+}
+
+func (c *convertFieldMapper) Map(ptr interface{}) interface{} {
+	value := c.result.copyOf(reflect.ValueOf(ptr).Elem())
+	field := value.FieldByIndex(c.fields.fieldIndexes(value.Type(), nil)[0])
+	converted, err := c.convert(field.Interface())
+	if err != nil {
+		return nil
+	}
+	setField(field, converted)
+	return c.result.ptr()
+}
+
+func (c *convertFieldMapper) Clone() consume.Mapper {
+	return &convertFieldMapper{fields: c.fields, convert: c.convert}
+}
+
+// setField sets field to x converting x to the type of field if needed.
+// setField panics if x can't be assigned or converted to the type of
+// field.
+func setField(field reflect.Value, x interface{}) {
+	if x == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return
+	}
+	value := reflect.ValueOf(x)
+	if value.Type().AssignableTo(field.Type()) {
+		field.Set(value)
+		return
+	}
+	if !value.Type().ConvertibleTo(field.Type()) {
+		panic(fmt.Sprintf(
+			"%v can't be stored in a field of type %v",
+			value.Type(), field.Type()))
+	}
+	field.Set(value.Convert(field.Type()))
+}
@@ -0,0 +1,65 @@
+package mappers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type cents int64
+
+type price struct {
+	Item     string
+	Amount   cents
+	Currency string
+}
+
+func TestConvertField(t *testing.T) {
+	assert := assert.New(t)
+	rates := map[string]float64{"USD": 1.0, "EUR": 1.1}
+	prices := []price{
+		{Item: "a", Amount: 100, Currency: "EUR"},
+		{Item: "b", Amount: 200, Currency: "XYZ"},
+		{Item: "c", Amount: 300, Currency: "USD"},
+	}
+	var result []price
+	consume.FeedSlice(
+		prices,
+		consume.MapFilter(
+			consume.AppendTo(&result),
+			func(src *price, dest *price) bool {
+				*dest = *src
+				dest.Amount = cents(
+					float64(src.Amount) * rates[src.Currency])
+				return rates[src.Currency] != 0
+			},
+			mappers.ConvertField(
+				"Currency",
+				func(value interface{}) (interface{}, error) {
+					return "USD", nil
+				}),
+			mappers.ConvertField(
+				"Amount",
+				func(value interface{}) (interface{}, error) {
+					amount := value.(cents)
+					if amount > 250 {
+						return nil, errors.New("too large")
+					}
+					return int64(amount) * 2, nil
+				})))
+	assert.Equal(
+		[]price{{Item: "a", Amount: 220, Currency: "USD"}}, result)
+}
+
+func TestConvertFieldPanics(t *testing.T) {
+	assert := assert.New(t)
+	mapper := mappers.ConvertField(
+		"Item",
+		func(value interface{}) (interface{}, error) {
+			return []int{1}, nil
+		}).Clone()
+	assert.Panics(func() { mapper.Map(&price{}) })
+}
@@ -0,0 +1,71 @@
+package mappers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// fieldCache looks up named struct fields once for each struct type.
+// Clones of a Mapper share the same fieldCache.
+type fieldCache struct {
+	names []string
+
+	// indexes maps a struct type to the indexes of the named fields.
+	indexes sync.Map
+}
+
+func newFieldCache(names ...string) *fieldCache {
+	namesCopy := make([]string, len(names))
+	copy(namesCopy, names)
+	return &fieldCache{names: namesCopy}
+}
+
+// fieldIndexes returns the index of each named field in structType.
+// fieldIndexes panics if structType is not a struct or lacks a named
+// field or if check returns an error for a named field.
+func (f *fieldCache) fieldIndexes(
+	structType reflect.Type,
+	check func(field reflect.StructField) error) [][]int {
+	if indexes, ok := f.indexes.Load(structType); ok {
+		return indexes.([][]int)
+	}
+	if structType.Kind() != reflect.Struct {
+		panic("a struct is expected.")
+	}
+	result := make([][]int, len(f.names))
+	for i, name := range f.names {
+		field, ok := structType.FieldByName(name)
+		if !ok {
+			panic(fmt.Sprintf("%v has no field %s", structType, name))
+		}
+		if check != nil {
+			if err := check(field); err != nil {
+				panic(fmt.Sprintf(
+					"field %s of %v: %v", name, structType, err))
+			}
+		}
+		result[i] = field.Index
+	}
+	f.indexes.Store(structType, result)
+	return result
+}
+
+// structCopy holds a copy of the last struct value it was given.
+type structCopy struct {
+	result reflect.Value
+}
+
+// copyOf stores a copy of value and returns the copy which can be set.
+func (s *structCopy) copyOf(value reflect.Value) reflect.Value {
+	if !s.result.IsValid() || s.result.Elem().Type() != value.Type() {
+		s.result = reflect.New(value.Type())
+	}
+	s.result.Elem().Set(value)
+	return s.result.Elem()
+}
+
+// ptr returns a pointer to the copy.
+func (s *structCopy) ptr() interface{} {
+	return s.result.Interface()
+}
@@ -0,0 +1,29 @@
+package mappers
+
+import (
+	"path"
+
+	"github.com/keep94/consume"
+)
+
+// MatchGlob returns a Filterer that accepts only the values for which
+// the string that extract returns matches the shell pattern, pattern.
+// Matching follows the rules of path.Match. MatchGlob panics if pattern
+// is malformed.
+func MatchGlob(
+	pattern string, extract func(ptr interface{}) string) consume.Filterer {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(err)
+	}
+	return globFilterer{pattern: pattern, extract: extract}
+}
+
+type globFilterer struct {
+	pattern string
+	extract func(ptr interface{}) string
+}
+
+func (g globFilterer) Filter(ptr interface{}) bool {
+	matched, _ := path.Match(g.pattern, g.extract(ptr))
+	return matched
+}
@@ -0,0 +1,29 @@
+package mappers_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	feedStrings(
+		consume.MapFilter(
+			consume.AppendTo(&result),
+			mappers.MatchGlob("src/*.go", func(ptr interface{}) string {
+				return *ptr.(*string)
+			})),
+		"src/consume.go", "src/sub/mappers.go", "README.md", "src/x.go")
+	assert.Equal([]string{"src/consume.go", "src/x.go"}, result)
+}
+
+func TestMatchGlobPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		mappers.MatchGlob("[", func(ptr interface{}) string { return "" })
+	})
+}
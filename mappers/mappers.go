@@ -0,0 +1,74 @@
+// Package mappers provides ready-made Mappers and Filterers for use with
+// consume.MapFilter and consume.NewMapFilterer. Unlike raw functions,
+// these do not use reflection and do not allocate memory for each value.
+package mappers
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/keep94/consume"
+)
+
+// TrimSpace returns a Mapper that maps a *string to a *string with
+// all leading and trailing white space removed.
+func TrimSpace() consume.Mapper {
+	return &stringMapper{f: strings.TrimSpace}
+}
+
+// ToLower returns a Mapper that maps a *string to a *string with all
+// unicode letters mapped to their lower case.
+func ToLower() consume.Mapper {
+	return &stringMapper{f: strings.ToLower}
+}
+
+// TruncateTo returns a Mapper that maps a *string to a *string containing
+// at most the first n runes of the original. TruncateTo panics if n is
+// negative.
+func TruncateTo(n int) consume.Mapper {
+	if n < 0 {
+		panic("n must be non-negative")
+	}
+	return &stringMapper{f: func(s string) string {
+		count := 0
+		for i := range s {
+			if count == n {
+				return s[:i]
+			}
+			count++
+		}
+		return s
+	}}
+}
+
+// NonEmpty returns a Filterer that accepts only *string values that
+// point to a non-empty string.
+func NonEmpty() consume.Filterer {
+	return stringFilterer(func(s string) bool { return s != "" })
+}
+
+// MatchRegexp returns a Filterer that accepts only *string values that
+// point to a string containing a match of re.
+func MatchRegexp(re *regexp.Regexp) consume.Filterer {
+	return stringFilterer(re.MatchString)
+}
+
+type stringMapper struct {
+	f      func(s string) string
+	result string
+}
+
+func (s *stringMapper) Map(ptr interface{}) interface{} {
+	s.result = s.f(*ptr.(*string))
+	return &s.result
+}
+
+func (s *stringMapper) Clone() consume.Mapper {
+	return &stringMapper{f: s.f}
+}
+
+type stringFilterer func(s string) bool
+
+func (s stringFilterer) Filter(ptr interface{}) bool {
+	return s(*ptr.(*string))
+}
@@ -0,0 +1,65 @@
+package mappers_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringMappers(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result),
+		mappers.TrimSpace(),
+		mappers.NonEmpty(),
+		mappers.ToLower(),
+		mappers.TruncateTo(3))
+	feedStrings(consumer, "  Hello ", "   ", "WORLD", "Aé", "")
+	assert.Equal([]string{"hel", "wor", "aé"}, result)
+}
+
+func TestTruncateTo(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	feedStrings(
+		consume.MapFilter(consume.AppendTo(&result), mappers.TruncateTo(2)),
+		"日本語", "a", "")
+	assert.Equal([]string{"日本", "a", ""}, result)
+	assert.Panics(func() { mappers.TruncateTo(-1) })
+}
+
+func TestMatchRegexp(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	feedStrings(
+		consume.MapFilter(
+			consume.AppendTo(&result),
+			mappers.MatchRegexp(regexp.MustCompile(`^\d+$`))),
+		"123", "12a", "7")
+	assert.Equal([]string{"123", "7"}, result)
+}
+
+func TestStringMapperClone(t *testing.T) {
+	assert := assert.New(t)
+	mapper := mappers.ToLower()
+	clone := mapper.Clone()
+	hello := "HELLO"
+	world := "WORLD"
+	helloPtr := mapper.Map(&hello).(*string)
+	worldPtr := clone.Map(&world).(*string)
+	assert.Equal("hello", *helloPtr)
+	assert.Equal("world", *worldPtr)
+}
+
+func feedStrings(consumer consume.Consumer, values ...string) {
+	for i := range values {
+		if !consumer.CanConsume() {
+			return
+		}
+		consumer.Consume(&values[i])
+	}
+}
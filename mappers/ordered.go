@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package mappers
+
+import (
+	"cmp"
+
+	"github.com/keep94/consume"
+)
+
+// InRange returns a Filterer that accepts only the values whose key is
+// between lo and hi inclusive. key takes the pointer passed to Filter.
+// For input sorted by key, use StopAfter with consume.TakeWhile to stop
+// consuming once keys exceed hi.
+func InRange[T cmp.Ordered](
+	lo, hi T, key func(ptr interface{}) T) consume.Filterer {
+	return inRangeFilterer[T]{lo: lo, hi: hi, key: key}
+}
+
+// StopAfter returns a Filterer that accepts only the values whose key is
+// at most hi. StopAfter is meant to be passed to consume.TakeWhile when
+// input is sorted by key so that the returned consumer stops consuming
+// as soon as a key exceeds hi.
+func StopAfter[T cmp.Ordered](
+	hi T, key func(ptr interface{}) T) consume.Filterer {
+	return stopAfterFilterer[T]{hi: hi, key: key}
+}
+
+type inRangeFilterer[T cmp.Ordered] struct {
+	lo  T
+	hi  T
+	key func(ptr interface{}) T
+}
+
+func (f inRangeFilterer[T]) Filter(ptr interface{}) bool {
+	k := f.key(ptr)
+	return cmp.Compare(f.lo, k) <= 0 && cmp.Compare(k, f.hi) <= 0
+}
+
+type stopAfterFilterer[T cmp.Ordered] struct {
+	hi  T
+	key func(ptr interface{}) T
+}
+
+func (f stopAfterFilterer[T]) Filter(ptr interface{}) bool {
+	return cmp.Compare(f.key(ptr), f.hi) <= 0
+}
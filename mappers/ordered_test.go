@@ -0,0 +1,43 @@
+//go:build go1.21
+// +build go1.21
+
+package mappers_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func intKey(ptr interface{}) int {
+	return *ptr.(*int)
+}
+
+func TestInRange(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result), mappers.InRange(3, 6, intKey))
+	for _, i := range []int{7, 3, 1, 6, 4, 9} {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{3, 6, 4}, result)
+}
+
+func TestStopAfter(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := consume.TakeWhile(
+		consume.MapFilter(
+			consume.AppendTo(&result), mappers.InRange(3, 6, intKey)),
+		mappers.StopAfter(6, intKey))
+	count := 0
+	for i := 0; consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+		count++
+	}
+	assert.Equal([]int{3, 4, 5, 6}, result)
+	assert.Equal(8, count)
+}
@@ -0,0 +1,80 @@
+package mappers
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+
+	"github.com/keep94/consume"
+)
+
+// Redact returns a Mapper that maps a pointer to a struct to a pointer to
+// a copy of that struct with the fields named in fields set to their zero
+// values. The Map method of the returned Mapper panics if the value is
+// not a struct or lacks one of the named fields. The lookup of the named
+// fields is done once for each struct type and then cached.
+func Redact(fields ...string) consume.Mapper {
+	return &redactMapper{fields: newFieldCache(fields...)}
+}
+
+// HashFields works like Redact except that it replaces each named field
+// with its hash instead of its zero value. Named fields must be strings
+// or byte slices. h computes the hash. A []byte field becomes the hash
+// itself, and a string field becomes the hash in hexadecimal.
+func HashFields(h func(b []byte) []byte, fields ...string) consume.Mapper {
+	return &hashFieldsMapper{h: h, fields: newFieldCache(fields...)}
+}
+
+type redactMapper struct {
+	fields *fieldCache
+	result structCopy
+}
+
+func (r *redactMapper) Map(ptr interface{}) interface{} {
+	value := r.result.copyOf(reflect.ValueOf(ptr).Elem())
+	for _, index := range r.fields.fieldIndexes(value.Type(), nil) {
+		field := value.FieldByIndex(index)
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return r.result.ptr()
+}
+
+func (r *redactMapper) Clone() consume.Mapper {
+	return &redactMapper{fields: r.fields}
+}
+
+type hashFieldsMapper struct {
+	h      func(b []byte) []byte
+	fields *fieldCache
+	result structCopy
+}
+
+func (m *hashFieldsMapper) Map(ptr interface{}) interface{} {
+	value := m.result.copyOf(reflect.ValueOf(ptr).Elem())
+	indexes := m.fields.fieldIndexes(value.Type(), checkHashable)
+	for _, index := range indexes {
+		field := value.FieldByIndex(index)
+		if field.Kind() == reflect.String {
+			field.SetString(hex.EncodeToString(m.h([]byte(field.String()))))
+		} else {
+			field.SetBytes(m.h(field.Bytes()))
+		}
+	}
+	return m.result.ptr()
+}
+
+func (m *hashFieldsMapper) Clone() consume.Mapper {
+	return &hashFieldsMapper{h: m.h, fields: m.fields}
+}
+
+func checkHashable(field reflect.StructField) error {
+	switch field.Type.Kind() {
+	case reflect.String:
+		return nil
+	case reflect.Slice:
+		if field.Type.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+	}
+	return errors.New("must be a string or []byte")
+}
@@ -0,0 +1,61 @@
+package mappers_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type customer struct {
+	Name  string
+	Email string
+	Token []byte
+	Age   int
+}
+
+func sha256Sum(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+	customers := []customer{
+		{Name: "Mark", Email: "m@x.com", Token: []byte("t"), Age: 50},
+	}
+	var result []customer
+	consume.FeedSlice(
+		customers,
+		consume.MapFilter(
+			consume.AppendTo(&result), mappers.Redact("Email", "Token")))
+	assert.Equal([]customer{{Name: "Mark", Age: 50}}, result)
+	assert.Equal("m@x.com", customers[0].Email)
+	assert.Panics(func() {
+		mappers.Redact("Phone").Clone().Map(&customers[0])
+	})
+}
+
+func TestHashFields(t *testing.T) {
+	assert := assert.New(t)
+	customers := []customer{
+		{Name: "Mark", Email: "abc", Token: []byte("abc"), Age: 50},
+	}
+	var result []customer
+	consume.FeedSlice(
+		customers,
+		consume.MapFilter(
+			consume.AppendTo(&result),
+			mappers.HashFields(sha256Sum, "Email", "Token")))
+	assert.Equal(
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		result[0].Email)
+	assert.Equal(sha256Sum([]byte("abc")), result[0].Token)
+	assert.Equal("Mark", result[0].Name)
+	assert.Equal([]byte("abc"), customers[0].Token)
+	assert.Panics(func() {
+		mappers.HashFields(sha256Sum, "Age").Clone().Map(&customers[0])
+	})
+}
@@ -0,0 +1,52 @@
+package mappers
+
+import (
+	"regexp"
+
+	"github.com/keep94/consume"
+)
+
+// MatchString returns a Filterer that accepts only the values for which
+// the string that extract returns contains a match of re. extract takes
+// the pointer passed to Filter. To filter *string values directly, use
+// MatchRegexp.
+func MatchString(
+	re *regexp.Regexp, extract func(ptr interface{}) string) consume.Filterer {
+	return matchStringFilterer{re: re, extract: extract}
+}
+
+// Submatches returns a Mapper that maps a value to a *[]string holding
+// the leftmost match of re within the string that extract returns
+// followed by the text of each capture group. See
+// regexp.FindStringSubmatch. Values with no match are filtered out.
+func Submatches(
+	re *regexp.Regexp, extract func(ptr interface{}) string) consume.Mapper {
+	return &submatchesMapper{re: re, extract: extract}
+}
+
+type matchStringFilterer struct {
+	re      *regexp.Regexp
+	extract func(ptr interface{}) string
+}
+
+func (m matchStringFilterer) Filter(ptr interface{}) bool {
+	return m.re.MatchString(m.extract(ptr))
+}
+
+type submatchesMapper struct {
+	re      *regexp.Regexp
+	extract func(ptr interface{}) string
+	result  []string
+}
+
+func (s *submatchesMapper) Map(ptr interface{}) interface{} {
+	s.result = s.re.FindStringSubmatch(s.extract(ptr))
+	if s.result == nil {
+		return nil
+	}
+	return &s.result
+}
+
+func (s *submatchesMapper) Clone() consume.Mapper {
+	return &submatchesMapper{re: s.re, extract: s.extract}
+}
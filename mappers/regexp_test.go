@@ -0,0 +1,55 @@
+package mappers_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type logLine struct {
+	Level string
+	Text  string
+}
+
+func logLineText(ptr interface{}) string {
+	return ptr.(*logLine).Text
+}
+
+var logLines = []logLine{
+	{Level: "INFO", Text: "user=bob action=login"},
+	{Level: "WARN", Text: "disk almost full"},
+	{Level: "INFO", Text: "user=alice action=logout"},
+}
+
+func TestMatchString(t *testing.T) {
+	assert := assert.New(t)
+	var result []logLine
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result),
+		mappers.MatchString(regexp.MustCompile(`user=`), logLineText))
+	for i := range logLines {
+		consumer.Consume(&logLines[i])
+	}
+	assert.Equal([]logLine{logLines[0], logLines[2]}, result)
+}
+
+func TestSubmatches(t *testing.T) {
+	assert := assert.New(t)
+	var result [][]string
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result),
+		mappers.Submatches(
+			regexp.MustCompile(`user=(\w+) action=(\w+)`), logLineText))
+	for i := range logLines {
+		consumer.Consume(&logLines[i])
+	}
+	assert.Equal(
+		[][]string{
+			{"user=bob action=login", "bob", "login"},
+			{"user=alice action=logout", "alice", "logout"},
+		},
+		result)
+}
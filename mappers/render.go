@@ -0,0 +1,85 @@
+package mappers
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"text/template"
+
+	"github.com/keep94/consume"
+)
+
+// RenderField returns a Mapper that executes tmpl with the value as its
+// data and stores the output in the string field named destField. The
+// Mapper maps a pointer to a struct to a pointer to a copy of that
+// struct with destField set. If destField is empty, the Mapper instead
+// maps a pointer to any value to a *string holding the output. Values for
+// which tmpl fails to execute are filtered out. The Map method of the
+// returned Mapper panics if the value is not a struct or if destField is
+// not a string field. The lookup of destField is done once for each
+// struct type and then cached.
+func RenderField(destField string, tmpl *template.Template) consume.Mapper {
+	if destField == "" {
+		return &renderMapper{tmpl: tmpl}
+	}
+	return &renderFieldMapper{
+		renderMapper: renderMapper{tmpl: tmpl},
+		fields:       newFieldCache(destField),
+	}
+}
+
+type renderMapper struct {
+	tmpl   *template.Template
+	buffer bytes.Buffer
+	result string
+}
+
+func (r *renderMapper) render(ptr interface{}) bool {
+	r.buffer.Reset()
+	if r.tmpl.Execute(&r.buffer, ptr) != nil {
+		return false
+	}
+	r.result = r.buffer.String()
+	return true
+}
+
+func (r *renderMapper) Map(ptr interface{}) interface{} {
+	if !r.render(ptr) {
+		return nil
+	}
+	return &r.result
+}
+
+func (r *renderMapper) Clone() consume.Mapper {
+	return &renderMapper{tmpl: r.tmpl}
+}
+
+type renderFieldMapper struct {
+	renderMapper
+	fields *fieldCache
+	copied structCopy
+}
+
+func (r *renderFieldMapper) Map(ptr interface{}) interface{} {
+	if !r.render(ptr) {
+		return nil
+	}
+	value := r.copied.copyOf(reflect.ValueOf(ptr).Elem())
+	index := r.fields.fieldIndexes(value.Type(), checkString)[0]
+	value.FieldByIndex(index).SetString(r.result)
+	return r.copied.ptr()
+}
+
+func (r *renderFieldMapper) Clone() consume.Mapper {
+	return &renderFieldMapper{
+		renderMapper: renderMapper{tmpl: r.tmpl},
+		fields:       r.fields,
+	}
+}
+
+func checkString(field reflect.StructField) error {
+	if field.Type.Kind() != reflect.String {
+		return errors.New("must be a string")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package mappers_test
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type labeled struct {
+	First string
+	Last  string
+	Label string
+}
+
+func TestRenderField(t *testing.T) {
+	assert := assert.New(t)
+	tmpl := template.Must(template.New("label").Parse(
+		`{{.Last}}, {{.First}}`))
+	values := []labeled{
+		{First: "Mark", Last: "Smith"},
+		{First: "Beth", Last: "Jones", Label: "old"},
+	}
+	var result []labeled
+	consume.FeedSlice(
+		values,
+		consume.MapFilter(
+			consume.AppendTo(&result), mappers.RenderField("Label", tmpl)))
+	assert.Equal(
+		[]labeled{
+			{First: "Mark", Last: "Smith", Label: "Smith, Mark"},
+			{First: "Beth", Last: "Jones", Label: "Jones, Beth"},
+		},
+		result)
+	assert.Equal("old", values[1].Label)
+
+	var labels []string
+	consume.FeedSlice(
+		values,
+		consume.MapFilter(
+			consume.AppendTo(&labels), mappers.RenderField("", tmpl)))
+	assert.Equal([]string{"Smith, Mark", "Jones, Beth"}, labels)
+}
+
+func TestRenderFieldErrors(t *testing.T) {
+	assert := assert.New(t)
+	tmpl := template.Must(template.New("bad").Parse(`{{.Missing}}`))
+	var labels []string
+	consume.FeedSlice(
+		[]labeled{{First: "Mark"}},
+		consume.MapFilter(
+			consume.AppendTo(&labels), mappers.RenderField("", tmpl)))
+	assert.Empty(labels)
+
+	tmpl = template.Must(template.New("ok").Parse(`x`))
+	mapper := mappers.RenderField("Age", tmpl).Clone()
+	assert.Panics(func() { mapper.Map(&customer{}) })
+}
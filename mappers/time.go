@@ -0,0 +1,88 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/keep94/consume"
+)
+
+// ParseTimeMapper returns a Mapper that maps a *string to a *time.Time by
+// parsing the string according to layout in loc. See time.ParseInLocation.
+// Strings that fail to parse are filtered out.
+func ParseTimeMapper(layout string, loc *time.Location) consume.Mapper {
+	return &parseTimeMapper{layout: layout, loc: loc}
+}
+
+// FormatTimeMapper returns a Mapper that maps a *time.Time to a *string
+// by formatting the time according to layout.
+func FormatTimeMapper(layout string) consume.Mapper {
+	return &formatTimeMapper{layout: layout}
+}
+
+// TruncateToDay returns a Mapper that maps a *time.Time to a *time.Time
+// at midnight of the same day in the same location. Use it to bucket
+// values by day.
+func TruncateToDay() consume.Mapper {
+	return &timeMapper{f: func(t time.Time) time.Time {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}}
+}
+
+// TruncateToHour returns a Mapper that maps a *time.Time to a *time.Time
+// at the start of the same hour in the same location. Use it to bucket
+// values by hour.
+func TruncateToHour() consume.Mapper {
+	return &timeMapper{f: func(t time.Time) time.Time {
+		year, month, day := t.Date()
+		return time.Date(
+			year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	}}
+}
+
+type parseTimeMapper struct {
+	layout string
+	loc    *time.Location
+	result time.Time
+}
+
+func (p *parseTimeMapper) Map(ptr interface{}) interface{} {
+	t, err := time.ParseInLocation(p.layout, *ptr.(*string), p.loc)
+	if err != nil {
+		return nil
+	}
+	p.result = t
+	return &p.result
+}
+
+func (p *parseTimeMapper) Clone() consume.Mapper {
+	return &parseTimeMapper{layout: p.layout, loc: p.loc}
+}
+
+type formatTimeMapper struct {
+	layout string
+	result string
+}
+
+func (f *formatTimeMapper) Map(ptr interface{}) interface{} {
+	f.result = ptr.(*time.Time).Format(f.layout)
+	return &f.result
+}
+
+func (f *formatTimeMapper) Clone() consume.Mapper {
+	return &formatTimeMapper{layout: f.layout}
+}
+
+type timeMapper struct {
+	f      func(t time.Time) time.Time
+	result time.Time
+}
+
+func (m *timeMapper) Map(ptr interface{}) interface{} {
+	m.result = m.f(*ptr.(*time.Time))
+	return &m.result
+}
+
+func (m *timeMapper) Clone() consume.Mapper {
+	return &timeMapper{f: m.f}
+}
@@ -0,0 +1,34 @@
+package mappers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAndFormatTime(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	feedStrings(
+		consume.MapFilter(
+			consume.AppendTo(&result),
+			mappers.ParseTimeMapper("2006-01-02 15:04", time.UTC),
+			mappers.FormatTimeMapper("Jan 2 2006 3PM")),
+		"2021-11-25 14:30", "not a time", "2020-02-29 09:05")
+	assert.Equal([]string{"Nov 25 2021 2PM", "Feb 29 2020 9AM"}, result)
+}
+
+func TestTruncateTime(t *testing.T) {
+	assert := assert.New(t)
+	loc := time.FixedZone("India", 5*3600+1800)
+	tm := time.Date(2021, 11, 25, 14, 30, 15, 7, loc)
+	assert.Equal(
+		time.Date(2021, 11, 25, 0, 0, 0, 0, loc),
+		*mappers.TruncateToDay().Map(&tm).(*time.Time))
+	assert.Equal(
+		time.Date(2021, 11, 25, 14, 0, 0, 0, loc),
+		*mappers.TruncateToHour().Map(&tm).(*time.Time))
+}
@@ -0,0 +1,64 @@
+package mappers
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/keep94/consume"
+)
+
+// NonZero returns a Filterer that accepts only the values that are not
+// the zero value of their type.
+func NonZero() consume.Filterer {
+	return nonZeroFilterer{}
+}
+
+// NonNilField returns a Filterer that accepts only struct values whose
+// field named fieldName is not nil. The Filter method of the returned
+// Filterer panics if the value is not a struct, has no field named
+// fieldName, or if that field is of a type that can't be nil. The lookup
+// of fieldName is done once for each struct type and then cached.
+func NonNilField(fieldName string) consume.Filterer {
+	return &nonNilFieldFilterer{fieldName: fieldName}
+}
+
+type nonZeroFilterer struct{}
+
+func (n nonZeroFilterer) Filter(ptr interface{}) bool {
+	return !reflect.ValueOf(ptr).Elem().IsZero()
+}
+
+type nonNilFieldFilterer struct {
+	fieldName string
+
+	// indexes maps a struct type to the index of the fieldName field.
+	indexes sync.Map
+}
+
+func (n *nonNilFieldFilterer) Filter(ptr interface{}) bool {
+	value := reflect.ValueOf(ptr).Elem()
+	return !value.FieldByIndex(n.fieldIndex(value.Type())).IsNil()
+}
+
+func (n *nonNilFieldFilterer) fieldIndex(structType reflect.Type) []int {
+	if index, ok := n.indexes.Load(structType); ok {
+		return index.([]int)
+	}
+	if structType.Kind() != reflect.Struct {
+		panic("a struct is expected.")
+	}
+	field, ok := structType.FieldByName(n.fieldName)
+	if !ok {
+		panic(fmt.Sprintf("%v has no field %s", structType, n.fieldName))
+	}
+	switch field.Type.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface,
+		reflect.Chan, reflect.Func:
+	default:
+		panic(fmt.Sprintf(
+			"field %s of %v can't be nil", n.fieldName, structType))
+	}
+	n.indexes.Store(structType, field.Index)
+	return field.Index
+}
@@ -0,0 +1,50 @@
+package mappers_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/mappers"
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	Id      int
+	Owner   *string
+	Balance int
+	Tags    []string
+}
+
+func TestNonZero(t *testing.T) {
+	assert := assert.New(t)
+	var result []account
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result), mappers.NonZero())
+	accounts := []account{{}, {Id: 1}, {}, {Tags: []string{}}}
+	for i := range accounts {
+		consumer.Consume(&accounts[i])
+	}
+	assert.Equal([]account{{Id: 1}, {Tags: []string{}}}, result)
+}
+
+func TestNonNilField(t *testing.T) {
+	assert := assert.New(t)
+	owner := "bob"
+	var result []account
+	consumer := consume.MapFilter(
+		consume.AppendTo(&result), mappers.NonNilField("Owner"))
+	accounts := []account{{Id: 1}, {Id: 2, Owner: &owner}, {Id: 3}}
+	for i := range accounts {
+		consumer.Consume(&accounts[i])
+	}
+	assert.Equal([]account{{Id: 2, Owner: &owner}}, result)
+}
+
+func TestNonNilFieldPanics(t *testing.T) {
+	assert := assert.New(t)
+	var a account
+	assert.Panics(func() { mappers.NonNilField("Missing").Filter(&a) })
+	assert.Panics(func() { mappers.NonNilField("Balance").Filter(&a) })
+	var i int
+	assert.Panics(func() { mappers.NonNilField("Owner").Filter(&i) })
+}
@@ -0,0 +1,71 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// OnEachPage returns a ConsumeFinalizer that groups the values it consumes
+// into pages of itemsPerPage values and calls handle with each full page
+// as soon as it accumulates. Caller must call Finalize to have handle
+// called once more with the last, partial page if there is one. pageNo
+// is zero based. If the returned consumer consumes *T values, items is a
+// *[]T. The slice that items points to is reused from page to page, so
+// handle must copy the values it wants to keep. If handle returns an
+// error, CanConsume() returns false from then on, handle is not called
+// again, and the returned consumer's Healthy method reports the error.
+// OnEachPage panics if itemsPerPage <= 0.
+func OnEachPage(
+	itemsPerPage int,
+	handle func(pageNo int, items interface{}) error) ConsumeFinalizer {
+	if itemsPerPage <= 0 {
+		panic("itemsPerPage must be positive")
+	}
+	return &onEachPageConsumer{itemsPerPage: itemsPerPage, handle: handle}
+}
+
+type onEachPageConsumer struct {
+	itemsPerPage int
+	handle       func(pageNo int, items interface{}) error
+	page         reflect.Value
+	pageNo       int
+	err          error
+	finalized    bool
+}
+
+func (o *onEachPageConsumer) CanConsume() bool {
+	return o.err == nil && !o.finalized
+}
+
+func (o *onEachPageConsumer) Consume(ptr interface{}) {
+	MustCanConsume(o)
+	value := reflect.ValueOf(ptr).Elem()
+	if !o.page.IsValid() {
+		sliceType := reflect.SliceOf(value.Type())
+		o.page = reflect.New(sliceType)
+		o.page.Elem().Set(reflect.MakeSlice(sliceType, 0, o.itemsPerPage))
+	}
+	appendValue(o.page.Elem(), value)
+	if o.page.Elem().Len() == o.itemsPerPage {
+		o.flush()
+	}
+}
+
+func (o *onEachPageConsumer) Finalize() {
+	if o.finalized {
+		return
+	}
+	o.finalized = true
+	if o.err == nil && o.page.IsValid() && o.page.Elem().Len() > 0 {
+		o.flush()
+	}
+}
+
+func (o *onEachPageConsumer) flush() {
+	o.err = o.handle(o.pageNo, o.page.Interface())
+	o.pageNo++
+	o.page.Elem().SetLen(0)
+}
+
+func (o *onEachPageConsumer) Healthy() error {
+	return o.err
+}
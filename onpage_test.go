@@ -0,0 +1,63 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnEachPage(t *testing.T) {
+	assert := assert.New(t)
+	var pages [][]int
+	var pageNos []int
+	cf := consume.OnEachPage(3, func(pageNo int, items interface{}) error {
+		pageNos = append(pageNos, pageNo)
+		pages = append(pages, append([]int(nil), *items.(*[]int)...))
+		return nil
+	})
+	feedInts(t, consume.Slice(cf, 0, 7))
+	assert.Equal([][]int{{0, 1, 2}, {3, 4, 5}}, pages)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal([][]int{{0, 1, 2}, {3, 4, 5}, {6}}, pages)
+	assert.Equal([]int{0, 1, 2}, pageNos)
+	assert.False(cf.CanConsume())
+}
+
+func TestOnEachPageExactPages(t *testing.T) {
+	assert := assert.New(t)
+	calls := 0
+	cf := consume.OnEachPage(2, func(pageNo int, items interface{}) error {
+		calls++
+		return nil
+	})
+	feedInts(t, consume.Slice(cf, 0, 4))
+	cf.Finalize()
+	assert.Equal(2, calls)
+}
+
+func TestOnEachPageError(t *testing.T) {
+	assert := assert.New(t)
+	calls := 0
+	handleErr := errors.New("handle failed")
+	cf := consume.OnEachPage(2, func(pageNo int, items interface{}) error {
+		calls++
+		return handleErr
+	})
+	assert.NoError(consume.CheckHealth(cf))
+	feedInts(t, cf)
+	cf.Finalize()
+	assert.Equal(1, calls)
+	assert.Equal(handleErr, consume.CheckHealth(cf))
+}
+
+func TestOnEachPagePanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.OnEachPage(0, func(pageNo int, items interface{}) error {
+			return nil
+		})
+	})
+}
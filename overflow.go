@@ -0,0 +1,129 @@
+package consume
+
+// SliceWithOverflow works like Slice except that instead of dropping the
+// values outside the slice, it passes them onto overflow. That is, values
+// before the start th value and values from the end th value on go to
+// overflow. A value in the slice also goes to overflow if consumer can no
+// longer consume. The returned consumer can consume as long as consumer
+// still wants values in the slice or overflow can consume.
+func SliceWithOverflow(
+	consumer Consumer, start, end int, overflow Consumer) Consumer {
+	return &sliceOverflowConsumer{
+		consumer: consumer, start: start, end: end, overflow: overflow}
+}
+
+// PageWithOverflow works like Page except that instead of dropping the
+// values that are not on the fetched page, it passes them onto overflow.
+// PageWithOverflow panics under the same conditions as Page.
+func PageWithOverflow(
+	zeroBasedPageNo int,
+	itemsPerPage int,
+	aValueSlicePointer interface{},
+	morePages *bool,
+	overflow Consumer) ConsumeFinalizer {
+	if zeroBasedPageNo < 0 {
+		panic("zeroBasedPageNo must be non-negative")
+	}
+	if itemsPerPage <= 0 {
+		panic("itemsPerPage must be positive")
+	}
+	aSliceValue := sliceValueFromP(aValueSlicePointer, false)
+	ensureEmptyWithCapacity(aSliceValue, itemsPerPage)
+	cf := AppendToSaveMemory(aValueSlicePointer)
+	end := (zeroBasedPageNo + 1) * itemsPerPage
+	return &pageOverflowConsumer{
+		consumer: SliceWithOverflow(
+			cf, zeroBasedPageNo*itemsPerPage, end, overflow),
+		cf:        cf,
+		end:       end,
+		morePages: morePages,
+	}
+}
+
+// LimitCounting returns a Consumer that passes at most max values onto c
+// and counts the values it could not pass on in overflowCount. A value
+// counts toward overflowCount if max values were already passed onto c
+// or if c can no longer consume. LimitCounting sets overflowCount to 0.
+// Since the returned consumer keeps counting, its CanConsume method
+// always returns true. LimitCounting panics if max is negative.
+func LimitCounting(c Consumer, max int, overflowCount *int) Consumer {
+	if max < 0 {
+		panic("max must be non-negative")
+	}
+	*overflowCount = 0
+	return SliceWithOverflow(c, 0, max, ConsumerFunc(func(ptr interface{}) {
+		*overflowCount++
+	}))
+}
+
+type sliceOverflowConsumer struct {
+	consumer Consumer
+	overflow Consumer
+	start    int
+	end      int
+	idx      int
+}
+
+func (s *sliceOverflowConsumer) children() []interface{} {
+	return []interface{}{s.consumer, s.overflow}
+}
+
+func (s *sliceOverflowConsumer) CanConsume() bool {
+	return s.wantsMore() || s.overflow.CanConsume()
+}
+
+func (s *sliceOverflowConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	inSlice := s.idx >= s.start && s.idx < s.end
+	s.idx++
+	if inSlice && s.consumer.CanConsume() {
+		s.consumer.Consume(ptr)
+	} else if s.overflow.CanConsume() {
+		s.overflow.Consume(ptr)
+	}
+}
+
+// wantsMore returns true if the underlying consumer still wants values.
+func (s *sliceOverflowConsumer) wantsMore() bool {
+	return s.idx < s.end && s.consumer.CanConsume()
+}
+
+// pageOverflowConsumer keeps consuming one value past the page even when
+// overflow can't consume so that it can tell if there are more pages.
+type pageOverflowConsumer struct {
+	consumer  Consumer
+	cf        ConsumeFinalizer
+	end       int
+	idx       int
+	morePages *bool
+	more      bool
+	finalized bool
+}
+
+func (p *pageOverflowConsumer) children() []interface{} {
+	return []interface{}{p.consumer}
+}
+
+func (p *pageOverflowConsumer) CanConsume() bool {
+	return !p.finalized && (!p.more || p.consumer.CanConsume())
+}
+
+func (p *pageOverflowConsumer) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	if p.idx >= p.end {
+		p.more = true
+	}
+	p.idx++
+	if p.consumer.CanConsume() {
+		p.consumer.Consume(ptr)
+	}
+}
+
+func (p *pageOverflowConsumer) Finalize() {
+	if p.finalized {
+		return
+	}
+	p.finalized = true
+	p.cf.Finalize()
+	*p.morePages = p.more
+}
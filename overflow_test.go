@@ -0,0 +1,94 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceWithOverflow(t *testing.T) {
+	assert := assert.New(t)
+	var inside, outside []int
+	feedInts(t, consume.SliceWithOverflow(
+		consume.AppendTo(&inside),
+		2,
+		5,
+		consume.Slice(consume.AppendTo(&outside), 0, 6)))
+	assert.Equal([]int{2, 3, 4}, inside)
+	assert.Equal([]int{0, 1, 5, 6, 7, 8}, outside)
+}
+
+func TestSliceWithOverflowConsumerFull(t *testing.T) {
+	assert := assert.New(t)
+	var inside, outside []int
+	feedInts(t, consume.SliceWithOverflow(
+		consume.Slice(consume.AppendTo(&inside), 0, 1),
+		0,
+		3,
+		consume.Slice(consume.AppendTo(&outside), 0, 3)))
+	assert.Equal([]int{0}, inside)
+	assert.Equal([]int{1, 2, 3}, outside)
+}
+
+func TestSliceWithOverflowNil(t *testing.T) {
+	assert := assert.New(t)
+	var inside []int
+	feedInts(t, consume.SliceWithOverflow(
+		consume.AppendTo(&inside), 1, 3, consume.Nil()))
+	assert.Equal([]int{1, 2}, inside)
+}
+
+func TestPageWithOverflow(t *testing.T) {
+	assert := assert.New(t)
+	var page []int
+	var morePages bool
+	count := 0
+	cf := consume.PageWithOverflow(
+		1,
+		3,
+		&page,
+		&morePages,
+		consume.Slice(
+			consume.ConsumerFunc(func(ptr interface{}) { count++ }), 0, 10))
+	feedInts(t, cf)
+	cf.Finalize()
+	assert.Equal([]int{3, 4, 5}, page)
+	assert.True(morePages)
+	assert.Equal(10, count)
+}
+
+func TestPageWithOverflowNil(t *testing.T) {
+	assert := assert.New(t)
+	var page []int
+	var morePages bool
+	cf := consume.PageWithOverflow(0, 3, &page, &morePages, consume.Nil())
+	feedInts(t, cf)
+	cf.Finalize()
+	assert.Equal([]int{0, 1, 2}, page)
+	assert.True(morePages)
+
+	cf = consume.PageWithOverflow(1, 3, &page, &morePages, consume.Nil())
+	for i := 0; i < 6; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Equal([]int{3, 4, 5}, page)
+	assert.False(morePages)
+}
+
+func TestLimitCounting(t *testing.T) {
+	assert := assert.New(t)
+	var shown []int
+	overflowCount := 7
+	consumer := consume.LimitCounting(
+		consume.AppendTo(&shown), 3, &overflowCount)
+	assert.Equal(0, overflowCount)
+	consume.FeedRange(0, 10, 1, consumer)
+	assert.True(consumer.CanConsume())
+	assert.Equal([]int{0, 1, 2}, shown)
+	assert.Equal(7, overflowCount)
+	assert.Panics(func() {
+		consume.LimitCounting(consume.Nil(), -1, &overflowCount)
+	})
+}
@@ -0,0 +1,84 @@
+package consume
+
+// PageStatus tells how fetching a page ended.
+type PageStatus int
+
+const (
+
+	// PageMore means the page is full and more pages follow.
+	PageMore PageStatus = iota + 1
+
+	// PageLast means the values ran out so this is the last page.
+	PageLast
+
+	// PageTruncated means that feeding values stopped early because of
+	// an error before the page could be filled. The page may be missing
+	// values, and there may be more pages.
+	PageTruncated
+)
+
+func (s PageStatus) String() string {
+	switch s {
+	case PageMore:
+		return "PageMore"
+	case PageLast:
+		return "PageLast"
+	case PageTruncated:
+		return "PageTruncated"
+	default:
+		return "PageStatus(?)"
+	}
+}
+
+// PageFinalizer is what PageE returns.
+type PageFinalizer interface {
+	ConsumeFinalizer
+
+	// FinalizeWith works like Finalize except that caller passes the
+	// error that stopped the loop feeding values or nil if the values
+	// simply ran out. FinalizeWith returns the status of the page. When
+	// the page is full, FinalizeWith returns PageMore no matter what
+	// feedErr is since the loop stopped because the page was full.
+	// Finalize is the same as FinalizeWith(nil). Calling FinalizeWith
+	// again returns the same status as the first call.
+	FinalizeWith(feedErr error) PageStatus
+}
+
+// PageE works like Page except that the returned consumer can tell
+// whether the values ran out or whether the loop feeding values broke
+// before the page was filled. Caller calls FinalizeWith on the returned
+// consumer with the error that stopped the feed loop. If FinalizeWith
+// returns PageTruncated, morePages is false, but there may be more pages.
+func PageE(
+	zeroBasedPageNo int,
+	itemsPerPage int,
+	aValueSlicePointer interface{},
+	morePages *bool) PageFinalizer {
+	return &pageEConsumer{pageConsumer: newPageConsumer(
+		zeroBasedPageNo, itemsPerPage, aValueSlicePointer, morePages)}
+}
+
+type pageEConsumer struct {
+	*pageConsumer
+	status PageStatus
+}
+
+func (p *pageEConsumer) Finalize() {
+	p.FinalizeWith(nil)
+}
+
+func (p *pageEConsumer) FinalizeWith(feedErr error) PageStatus {
+	if p.status != 0 {
+		return p.status
+	}
+	p.pageConsumer.Finalize()
+	switch {
+	case *p.morePages:
+		p.status = PageMore
+	case feedErr != nil:
+		p.status = PageTruncated
+	default:
+		p.status = PageLast
+	}
+	return p.status
+}
@@ -0,0 +1,52 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageE(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var morePages bool
+	pager := consume.PageE(1, 3, &ints, &morePages)
+	feedInts(t, pager)
+	feedErr := errors.New("feed failed")
+	assert.Equal(consume.PageMore, pager.FinalizeWith(feedErr))
+	assert.Equal(consume.PageMore, pager.FinalizeWith(nil))
+	assert.True(morePages)
+	assert.Equal([]int{3, 4, 5}, ints)
+}
+
+func TestPageELast(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var morePages bool
+	pager := consume.PageE(1, 3, &ints, &morePages)
+	for i := 0; i < 5; i++ {
+		pager.Consume(&i)
+	}
+	assert.Equal(consume.PageLast, pager.FinalizeWith(nil))
+	assert.False(morePages)
+	assert.Equal([]int{3, 4}, ints)
+}
+
+func TestPageETruncated(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var morePages bool
+	pager := consume.PageE(0, 3, &ints, &morePages)
+	for i := 0; i < 3; i++ {
+		pager.Consume(&i)
+	}
+	status := pager.FinalizeWith(errors.New("connection reset"))
+	assert.Equal(consume.PageTruncated, status)
+	assert.Equal("PageTruncated", status.String())
+	assert.False(morePages)
+	assert.Equal([]int{0, 1, 2}, ints)
+	pager.Finalize()
+	assert.Equal(consume.PageTruncated, pager.FinalizeWith(nil))
+}
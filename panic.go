@@ -0,0 +1,44 @@
+package consume
+
+// StopOnPanic returns a Consumer that works like c except that if c
+// panics while consuming a value, the returned consumer recovers from the
+// panic and its CanConsume method returns false from then on. Use
+// Recovered to see the value recovered from the panic.
+func StopOnPanic(c Consumer) Consumer {
+	return &stopOnPanicConsumer{consumer: c}
+}
+
+// Recovered returns the value that c recovered from a panic. c must come
+// from StopOnPanic. Recovered returns nil if c has not recovered from a
+// panic or if c did not come from StopOnPanic.
+func Recovered(c Consumer) interface{} {
+	if s, ok := c.(*stopOnPanicConsumer); ok {
+		return s.recovered
+	}
+	return nil
+}
+
+type stopOnPanicConsumer struct {
+	consumer  Consumer
+	panicked  bool
+	recovered interface{}
+}
+
+func (s *stopOnPanicConsumer) children() []interface{} {
+	return []interface{}{s.consumer}
+}
+
+func (s *stopOnPanicConsumer) CanConsume() bool {
+	return !s.panicked && s.consumer.CanConsume()
+}
+
+func (s *stopOnPanicConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	defer func() {
+		if r := recover(); r != nil {
+			s.panicked = true
+			s.recovered = r
+		}
+	}()
+	s.consumer.Consume(ptr)
+}
@@ -0,0 +1,35 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopOnPanic(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.StopOnPanic(consume.Compose(
+		consume.AppendTo(&ints),
+		consume.ConsumerFunc(func(ptr interface{}) {
+			if *ptr.(*int) == 3 {
+				panic("three")
+			}
+		})))
+	assert.Nil(consume.Recovered(consumer))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1, 2, 3}, ints)
+	assert.Equal("three", consume.Recovered(consumer))
+}
+
+func TestStopOnPanicInnerDone(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.StopOnPanic(
+		consume.Slice(consume.AppendTo(&ints), 0, 2))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1}, ints)
+	assert.Nil(consume.Recovered(consumer))
+	assert.Nil(consume.Recovered(consume.Nil()))
+}
@@ -0,0 +1,293 @@
+package consume
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// MapFilterParallel works like MapFilter except that it runs the
+// functions in funcs on workers separate goroutines. The returned
+// consumer passes a shallow copy of each consumed value to the next free
+// worker, and each worker passes the values that make it through funcs
+// onto c. Each worker gets its own MapFilterContext, so mapped values are
+// never shared, but the functions in funcs must still be safe to call
+// from multiple goroutines at once. Values reach c in no
+// particular order, but c is never called from more than one goroutine
+// at once. Caller must call Finalize() on the returned consumer to wait
+// for the workers to finish. Until then, c may still be consuming values.
+// If a function in funcs or c panics on a worker, the returned consumer
+// stops processing values and can no longer consume. Then Consume and
+// Finalize panic with the same value on the caller's goroutine.
+// MapFilterParallel panics if workers is not positive.
+func MapFilterParallel(
+	c Consumer, workers int, funcs ...interface{}) ConsumeFinalizer {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	mapFilters := NewMapFilterer(funcs...)
+	result := &parallelMapFilterConsumer{
+		consumer: c,
+		input:    make(chan interface{}, workers),
+	}
+	result.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go result.work(mapFilters, mapFilters.NewContext())
+	}
+	return result
+}
+
+type parallelMapFilterConsumer struct {
+	valueDropper
+	workerPanic
+	consumer  Consumer
+	mu        sync.Mutex
+	input     chan interface{}
+	wg        sync.WaitGroup
+	finalized bool
+}
+
+func (p *parallelMapFilterConsumer) children() []interface{} {
+	return []interface{}{p.consumer}
+}
+
+func (p *parallelMapFilterConsumer) CanConsume() bool {
+	if p.finalized || p.failed() {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.consumer.CanConsume()
+}
+
+func (p *parallelMapFilterConsumer) Consume(ptr interface{}) {
+	p.rePanic()
+	MustCanConsume(p)
+	p.input <- shallowCopy(ptr)
+}
+
+func (p *parallelMapFilterConsumer) Finalize() {
+	if p.finalized {
+		return
+	}
+	p.finalized = true
+	close(p.input)
+	p.wg.Wait()
+	p.rePanic()
+}
+
+func (p *parallelMapFilterConsumer) work(
+	mapFilters MapFilterer, ctx *MapFilterContext) {
+	defer p.wg.Done()
+	for ptr := range p.input {
+		if p.failed() || p.drop() {
+			continue
+		}
+		p.process(mapFilters, ctx, ptr)
+	}
+}
+
+func (p *parallelMapFilterConsumer) process(
+	mapFilters MapFilterer, ctx *MapFilterContext, ptr interface{}) {
+	defer p.recoverPanic()
+	ptr = mapFilters.MapFilterWithContext(ctx, ptr)
+	if ptr == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumer.CanConsume() {
+		p.consumer.Consume(ptr)
+	}
+}
+
+// MapFilterParallelOrdered works like MapFilterParallel except that
+// values reach c in the same order that the returned consumer consumed
+// them. Since workers may finish out of order, the returned consumer
+// holds onto shallow copies of finished values until the values before
+// them are done. To bound memory, at most 2*workers values can be in
+// progress at once. Consume blocks when that limit is reached. Panics on
+// workers reach the caller the same way as with MapFilterParallel.
+func MapFilterParallelOrdered(
+	c Consumer, workers int, funcs ...interface{}) ConsumeFinalizer {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	mapFilters := NewMapFilterer(funcs...)
+	result := &orderedParallelMapFilterConsumer{
+		consumer: c,
+		input:    make(chan sequencedValue, workers),
+		inFlight: make(chan struct{}, 2*workers),
+		done:     make(map[int]interface{}),
+	}
+	result.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go result.work(mapFilters, mapFilters.NewContext())
+	}
+	return result
+}
+
+type sequencedValue struct {
+	seq int
+	ptr interface{}
+}
+
+type orderedParallelMapFilterConsumer struct {
+	valueDropper
+	workerPanic
+	consumer  Consumer
+	mu        sync.Mutex
+	input     chan sequencedValue
+	inFlight  chan struct{}
+	wg        sync.WaitGroup
+	nextIn    int
+	nextOut   int
+	done      map[int]interface{}
+	finalized bool
+}
+
+func (o *orderedParallelMapFilterConsumer) children() []interface{} {
+	return []interface{}{o.consumer}
+}
+
+func (o *orderedParallelMapFilterConsumer) CanConsume() bool {
+	if o.finalized || o.failed() {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.consumer.CanConsume()
+}
+
+func (o *orderedParallelMapFilterConsumer) Consume(ptr interface{}) {
+	o.rePanic()
+	MustCanConsume(o)
+	o.inFlight <- struct{}{}
+	o.input <- sequencedValue{seq: o.nextIn, ptr: shallowCopy(ptr)}
+	o.nextIn++
+}
+
+func (o *orderedParallelMapFilterConsumer) Finalize() {
+	if o.finalized {
+		return
+	}
+	o.finalized = true
+	close(o.input)
+	o.wg.Wait()
+	o.rePanic()
+}
+
+func (o *orderedParallelMapFilterConsumer) work(
+	mapFilters MapFilterer, ctx *MapFilterContext) {
+	defer o.wg.Done()
+	for value := range o.input {
+		if o.failed() || o.drop() {
+			o.finish(value.seq, nil)
+			continue
+		}
+		o.finish(value.seq, o.mapFilter(mapFilters, ctx, value.ptr))
+	}
+}
+
+// mapFilter returns a shallow copy of what funcs map ptr to or nil if
+// funcs filter out ptr or panic.
+func (o *orderedParallelMapFilterConsumer) mapFilter(
+	mapFilters MapFilterer,
+	ctx *MapFilterContext,
+	ptr interface{}) (result interface{}) {
+	defer o.recoverPanic()
+	result = mapFilters.MapFilterWithContext(ctx, ptr)
+	if result != nil {
+		result = shallowCopy(result)
+	}
+	return
+}
+
+// finish records the result of the value with sequence number seq and
+// passes all the values now ready onto the underlying consumer. ptr is
+// nil if the value was filtered out.
+func (o *orderedParallelMapFilterConsumer) finish(seq int, ptr interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done[seq] = ptr
+	for {
+		ptr, ok := o.done[o.nextOut]
+		if !ok {
+			return
+		}
+		delete(o.done, o.nextOut)
+		o.nextOut++
+		<-o.inFlight
+		if ptr != nil && !o.failed() && o.consumer.CanConsume() {
+			o.consume(ptr)
+		}
+	}
+}
+
+func (o *orderedParallelMapFilterConsumer) consume(ptr interface{}) {
+	defer o.recoverPanic()
+	o.consumer.Consume(ptr)
+}
+
+// valueDropper implements pendingDropper for the parallel consumers.
+type valueDropper struct {
+	dropping     int32
+	droppedCount int64
+}
+
+func (v *valueDropper) dropPending() {
+	atomic.StoreInt32(&v.dropping, 1)
+}
+
+func (v *valueDropper) dropped() int {
+	return int(atomic.LoadInt64(&v.droppedCount))
+}
+
+// drop returns true and counts the value if the next value should be
+// dropped.
+func (v *valueDropper) drop() bool {
+	if atomic.LoadInt32(&v.dropping) == 0 {
+		return false
+	}
+	atomic.AddInt64(&v.droppedCount, 1)
+	return true
+}
+
+// workerPanic records the first panic on the worker goroutines of the
+// parallel consumers so that it can be raised again on the caller's
+// goroutine.
+type workerPanic struct {
+	mu       sync.Mutex
+	panicked int32
+	value    interface{}
+}
+
+// recoverPanic records the panic in progress if there is one. Workers
+// call recoverPanic with defer.
+func (w *workerPanic) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.panicked == 0 {
+		w.value = r
+		atomic.StoreInt32(&w.panicked, 1)
+	}
+}
+
+// failed returns true if a worker panicked.
+func (w *workerPanic) failed() bool {
+	return atomic.LoadInt32(&w.panicked) != 0
+}
+
+// rePanic panics with the value of the first panic on a worker if there
+// was one.
+func (w *workerPanic) rePanic() {
+	if !w.failed() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	panic(w.value)
+}
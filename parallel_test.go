@@ -0,0 +1,146 @@
+package consume_test
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapFilterParallel(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	cf := consume.MapFilterParallel(
+		consume.AppendTo(&result),
+		4,
+		func(ptr *int) bool { return (*ptr)%3 == 0 },
+		func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		})
+	for i := 0; i < 100; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Panics(func() { cf.Consume(new(int)) })
+	var expected []string
+	for i := 0; i < 100; i += 3 {
+		expected = append(expected, strconv.Itoa(i))
+	}
+	sort.Strings(expected)
+	sort.Strings(result)
+	assert.Equal(expected, result)
+}
+
+func TestMapFilterParallelStops(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	cf := consume.MapFilterParallel(
+		consume.Slice(consume.AppendTo(&result), 0, 5), 3)
+	for i := 0; cf.CanConsume(); i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Len(result, 5)
+}
+
+func TestMapFilterParallelPanics(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	assert.Panics(func() {
+		consume.MapFilterParallel(consume.AppendTo(&result), 0)
+	})
+}
+
+func TestMapFilterParallelOrdered(t *testing.T) {
+	assert := assert.New(t)
+	var result []string
+	cf := consume.MapFilterParallelOrdered(
+		consume.AppendTo(&result),
+		4,
+		func(ptr *int) bool { return (*ptr)%3 == 0 },
+		func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		})
+	for i := 0; i < 1000; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	var expected []string
+	for i := 0; i < 1000; i += 3 {
+		expected = append(expected, strconv.Itoa(i))
+	}
+	assert.Equal(expected, result)
+}
+
+func TestMapFilterParallelOrderedStops(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	cf := consume.MapFilterParallelOrdered(
+		consume.Slice(consume.AppendTo(&result), 0, 5), 3)
+	for i := 0; cf.CanConsume(); i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Equal([]int{0, 1, 2, 3, 4}, result)
+	assert.Panics(func() {
+		consume.MapFilterParallelOrdered(consume.AppendTo(&result), -1)
+	})
+}
+
+func TestMapFilterParallelWorkerPanics(t *testing.T) {
+	assert := assert.New(t)
+	cf := consume.MapFilterParallel(
+		consume.ConsumerFunc(func(ptr interface{}) {}),
+		2,
+		func(ptr *int) bool { panic("oops") })
+	consume.FeedRange(0, 5, 1, cf)
+	stagePanic, ok := recoverFrom(cf.Finalize).(*consume.StagePanicError)
+	if assert.True(ok) {
+		assert.Equal("oops", stagePanic.Value)
+	}
+	var result []int
+	cf = consume.MapFilterParallel(
+		consume.ConsumerFunc(func(ptr interface{}) {
+			if *ptr.(*int) == 2 {
+				panic("consumer")
+			}
+			result = append(result, *ptr.(*int))
+		}),
+		1)
+	consume.FeedRange(0, 3, 1, cf)
+	assert.Equal("consumer", recoverFrom(cf.Finalize))
+	assert.Equal([]int{0, 1}, result)
+}
+
+func TestMapFilterParallelOrderedWorkerPanics(t *testing.T) {
+	assert := assert.New(t)
+	cf := consume.MapFilterParallelOrdered(
+		consume.ConsumerFunc(func(ptr interface{}) {}),
+		2,
+		func(ptr *int) bool { panic("oops") })
+	consume.FeedRange(0, 10, 1, cf)
+	stagePanic, ok := recoverFrom(cf.Finalize).(*consume.StagePanicError)
+	if assert.True(ok) {
+		assert.Equal("oops", stagePanic.Value)
+	}
+	var result []int
+	cf = consume.MapFilterParallelOrdered(
+		consume.ConsumerFunc(func(ptr interface{}) {
+			if *ptr.(*int) == 2 {
+				panic("consumer")
+			}
+			result = append(result, *ptr.(*int))
+		}),
+		2)
+	consume.FeedRange(0, 10, 1, cf)
+	assert.Equal("consumer", recoverFrom(cf.Finalize))
+	assert.Equal([]int{0, 1}, result)
+}
@@ -0,0 +1,100 @@
+package consume
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrAborted is what Pipeline.Err returns when the pipeline was aborted
+// with a nil error.
+var ErrAborted = errors.New("consume: pipeline aborted")
+
+// Pipeline lets any stage of a pipeline of consumers tear down the whole
+// pipeline. Wrap each stage of the pipeline with Stage or
+// StageFinalizer. Once any code calls Abort, the CanConsume method of
+// every wrapped stage returns false. Pipeline instances are safe to use
+// with multiple goroutines.
+type Pipeline struct {
+	mu      sync.Mutex
+	aborted bool
+	err     error
+}
+
+// NewPipeline returns a new Pipeline that is not aborted.
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Stage returns a Consumer that works like c except that its CanConsume
+// method returns false once p is aborted. Since abort can happen in the
+// middle of consuming a value, the Consume method of returned consumer
+// quietly ignores values after p is aborted instead of panicking.
+func (p *Pipeline) Stage(c Consumer) Consumer {
+	return &pipelineStage{Consumer: c, pipeline: p}
+}
+
+// StageFinalizer works like Stage for ConsumeFinalizers. Finalize still
+// finalizes cf after p is aborted.
+func (p *Pipeline) StageFinalizer(cf ConsumeFinalizer) ConsumeFinalizer {
+	return &pipelineStageFinalizer{
+		pipelineStage: pipelineStage{Consumer: cf, pipeline: p},
+		finalizer:     cf,
+	}
+}
+
+// Abort aborts p. Only the error from the first call to Abort is kept.
+func (p *Pipeline) Abort(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.aborted {
+		return
+	}
+	if err == nil {
+		err = ErrAborted
+	}
+	p.aborted = true
+	p.err = err
+}
+
+// Aborted returns true if p has been aborted.
+func (p *Pipeline) Aborted() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.aborted
+}
+
+// Err returns the error p was aborted with or nil if p is not aborted.
+func (p *Pipeline) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+type pipelineStage struct {
+	Consumer
+	pipeline *Pipeline
+}
+
+func (p *pipelineStage) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
+func (p *pipelineStage) CanConsume() bool {
+	return !p.pipeline.Aborted() && p.Consumer.CanConsume()
+}
+
+func (p *pipelineStage) Consume(ptr interface{}) {
+	if p.pipeline.Aborted() {
+		return
+	}
+	p.Consumer.Consume(ptr)
+}
+
+type pipelineStageFinalizer struct {
+	pipelineStage
+	finalizer ConsumeFinalizer
+}
+
+func (p *pipelineStageFinalizer) Finalize() {
+	p.finalizer.Finalize()
+}
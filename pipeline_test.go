@@ -0,0 +1,53 @@
+package consume_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineAbort(t *testing.T) {
+	assert := assert.New(t)
+	pipeline := consume.NewPipeline()
+	errTooBig := errors.New("too big")
+	var small, all []int
+	consumer := pipeline.Stage(consume.Compose(
+		pipeline.Stage(consume.MapFilter(
+			consume.AppendTo(&small),
+			func(ptr *int) bool {
+				if *ptr > 5 {
+					pipeline.Abort(errTooBig)
+					return false
+				}
+				return true
+			})),
+		pipeline.Stage(consume.AppendTo(&all))))
+	assert.NoError(pipeline.Err())
+	for i := 0; consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+	}
+	assert.True(pipeline.Aborted())
+	assert.Equal(errTooBig, pipeline.Err())
+	pipeline.Abort(errors.New("ignored"))
+	assert.Equal(errTooBig, pipeline.Err())
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, small)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, all)
+}
+
+func TestPipelineStageFinalizer(t *testing.T) {
+	assert := assert.New(t)
+	pipeline := consume.NewPipeline()
+	var ints []int
+	cf := pipeline.StageFinalizer(consume.AppendToSaveMemory(&ints))
+	for i := 0; i < 3; i++ {
+		cf.Consume(&i)
+	}
+	pipeline.Abort(nil)
+	assert.Equal(consume.ErrAborted, pipeline.Err())
+	assert.False(cf.CanConsume())
+	assert.NotPanics(func() { cf.Consume(new(int)) })
+	cf.Finalize()
+	assert.Equal([]int{0, 1, 2}, ints)
+}
@@ -0,0 +1,39 @@
+package consume
+
+import (
+	"sync"
+)
+
+// Pool recycles pipelines so that they don't have to be built for each
+// run. A Pool is safe to use from multiple goroutines.
+type Pool struct {
+	build func() ConsumeFinalizer
+	pool  sync.Pool
+}
+
+// NewPool returns a new Pool that calls build to create a new pipeline
+// when it has none to recycle.
+func NewPool(build func() ConsumeFinalizer) *Pool {
+	return &Pool{build: build}
+}
+
+// Get returns a pipeline from this pool. Get returns a recycled pipeline
+// if one is available or else builds a new one.
+func (p *Pool) Get() ConsumeFinalizer {
+	if cf, ok := p.pool.Get().(ConsumeFinalizer); ok {
+		return cf
+	}
+	return p.build()
+}
+
+// Put resets cf and returns it to this pool. Put ignores cf if it is not
+// a Resetter since there is no way to reuse it. Caller must not use cf
+// after calling Put. cf should come from the Get method of this pool.
+func (p *Pool) Put(cf ConsumeFinalizer) {
+	r, ok := cf.(Resetter)
+	if !ok {
+		return
+	}
+	r.Reset()
+	p.pool.Put(cf)
+}
@@ -0,0 +1,77 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	assert := assert.New(t)
+	var built []*recorder
+	pool := consume.NewPool(func() consume.ConsumeFinalizer {
+		r := &recorder{}
+		built = append(built, r)
+		return r
+	})
+	cf := pool.Get()
+	assert.Len(built, 1)
+	feedInts(t, consume.Slice(cf, 0, 3))
+	cf.Finalize()
+	first := built[0]
+	assert.Equal([]int{0, 1, 2}, first.values)
+	pool.Put(cf)
+
+	// Put must reset the pipeline before recycling it.
+	assert.Equal(1, first.resets)
+	assert.Empty(first.values)
+	assert.False(first.finalized)
+
+	// Whether sync.Pool hands back first or Get builds a new pipeline,
+	// the pipeline must start empty.
+	cf = pool.Get()
+	assert.True(cf.CanConsume())
+	assert.Empty(cf.(*recorder).values)
+	feedInts(t, consume.Slice(cf, 0, 2))
+	cf.Finalize()
+	assert.Equal([]int{0, 1}, cf.(*recorder).values)
+}
+
+// recorder records the ints it consumes and can be reset.
+type recorder struct {
+	values    []int
+	resets    int
+	finalized bool
+}
+
+func (r *recorder) CanConsume() bool {
+	return !r.finalized
+}
+
+func (r *recorder) Consume(ptr interface{}) {
+	consume.MustCanConsume(r)
+	r.values = append(r.values, *ptr.(*int))
+}
+
+func (r *recorder) Finalize() {
+	r.finalized = true
+}
+
+func (r *recorder) Reset() {
+	r.values = nil
+	r.finalized = false
+	r.resets++
+}
+
+func TestPoolNotResetter(t *testing.T) {
+	assert := assert.New(t)
+	builds := 0
+	pool := consume.NewPool(func() consume.ConsumeFinalizer {
+		builds++
+		return consume.SliceFromEnd(consume.Nil(), 3)
+	})
+	pool.Put(pool.Get())
+	pool.Get()
+	assert.Equal(2, builds)
+}
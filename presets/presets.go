@@ -0,0 +1,195 @@
+// Package presets provides ready-made pipelines for common tasks. Each
+// preset is assembled from the primitives in the consume package and
+// shows how to combine them correctly.
+package presets
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/keep94/consume"
+)
+
+// ExportCSVPage returns a ConsumeFinalizer that writes one page of the
+// consumed values to w as CSV using consume.ToCSV. schema may be nil as
+// in consume.ToCSV. If there are more pages after the page written,
+// ExportCSVPage sets morePages to true; otherwise, it sets morePages to
+// false. Like consume.Page, morePages is undefined until caller calls
+// Finalize() on the returned consumer. The returned consumer implements
+// consume.Health by reporting any error writing to w. ExportCSVPage
+// panics if zeroBasedPageNo is negative or if itemsPerPage <= 0.
+func ExportCSVPage(
+	w io.Writer,
+	schema *consume.Schema,
+	zeroBasedPageNo int,
+	itemsPerPage int,
+	morePages *bool) consume.ConsumeFinalizer {
+	if zeroBasedPageNo < 0 {
+		panic("zeroBasedPageNo must be non-negative")
+	}
+	if itemsPerPage <= 0 {
+		panic("itemsPerPage must be positive")
+	}
+	start := zeroBasedPageNo * itemsPerPage
+	end := start + itemsPerPage
+	sink := consume.ToCSV(w, schema)
+	*morePages = false
+	detectMore := consume.ConsumerFunc(func(ptr interface{}) {
+		*morePages = true
+	})
+	return &pipeline{
+		Consumer: consume.Compose(
+			consume.Slice(sink, start, end),
+			consume.Slice(detectMore, end, end+1)),
+		finalize: sink.Finalize,
+	}
+}
+
+// DedupAndCollect returns a Consumer that appends each value it has not
+// seen before to the slice aValueSlicePointer points to. hasher compares
+// values as in consume.DistinctHash. If hasher is nil, values are compared
+// with reflect.DeepEqual as in consume.DistinctFunc which takes O(N^2)
+// time for N values. DedupAndCollect panics if aValueSlicePointer is not
+// a pointer to a slice.
+func DedupAndCollect(
+	aValueSlicePointer interface{}, hasher consume.Hasher) consume.Consumer {
+	collect := consume.AppendTo(aValueSlicePointer)
+	if hasher == nil {
+		return consume.DistinctFunc(collect, reflect.DeepEqual)
+	}
+	return consume.DistinctHash(collect, hasher)
+}
+
+// TopNByField returns a ConsumeFinalizer that stores the n consumed
+// values with the largest field named field in the slice
+// aValueSlicePointer points to. The slice is sorted from largest to
+// smallest field. When values tie, the ones consumed first come first.
+// The field must be a number or a string. The returned consumer keeps at
+// most 2*n values in memory. The values stored at aValueSlicePointer
+// are undefined until caller calls Finalize() on the returned consumer.
+// TopNByField panics if n is not positive, if aValueSlicePointer is not
+// a pointer to a slice of structs, or if the structs have no such field
+// or the field is neither a number nor a string.
+func TopNByField(
+	aValueSlicePointer interface{},
+	n int,
+	field string) consume.ConsumeFinalizer {
+	if n <= 0 {
+		panic("n must be positive")
+	}
+	sliceType := reflect.TypeOf(aValueSlicePointer)
+	if sliceType == nil || sliceType.Kind() != reflect.Ptr ||
+		sliceType.Elem().Kind() != reflect.Slice {
+		panic("aValueSlicePointer must be a pointer to a slice")
+	}
+	structType := sliceType.Elem().Elem()
+	if structType.Kind() != reflect.Struct {
+		panic("aValueSlicePointer must be a pointer to a slice of structs")
+	}
+	structField, ok := structType.FieldByName(field)
+	if !ok {
+		panic(fmt.Sprintf("%v has no field %s", structType, field))
+	}
+	less := lessFunc(structField.Type)
+	if less == nil {
+		panic(fmt.Sprintf(
+			"field %s of %v must be a number or string", field, structType))
+	}
+	slice := reflect.ValueOf(aValueSlicePointer).Elem()
+	slice.Set(slice.Slice(0, 0))
+	result := &topNConsumer{slice: slice, n: n}
+	result.greater = func(i, j int) bool {
+		return less(
+			result.slice.Index(j).FieldByIndex(structField.Index),
+			result.slice.Index(i).FieldByIndex(structField.Index))
+	}
+	result.Consumer = consume.AppendTo(aValueSlicePointer)
+	return result
+}
+
+type topNConsumer struct {
+	consume.Consumer
+	slice     reflect.Value
+	n         int
+	greater   func(i, j int) bool
+	finalized bool
+}
+
+func (t *topNConsumer) CanConsume() bool {
+	return !t.finalized
+}
+
+func (t *topNConsumer) Consume(ptr interface{}) {
+	consume.MustCanConsume(t)
+	t.Consumer.Consume(ptr)
+	if t.slice.Len() >= 2*t.n {
+		t.truncate()
+	}
+}
+
+func (t *topNConsumer) Finalize() {
+	if t.finalized {
+		return
+	}
+	t.finalized = true
+	t.truncate()
+}
+
+func (t *topNConsumer) truncate() {
+	sort.SliceStable(t.slice.Interface(), t.greater)
+	if t.slice.Len() > t.n {
+		t.slice.Set(t.slice.Slice(0, t.n))
+	}
+}
+
+// lessFunc returns the function that compares values of type t or nil
+// if t is neither a number nor a string.
+func lessFunc(t reflect.Type) func(a, b reflect.Value) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		return func(a, b reflect.Value) bool {
+			return a.Float() < b.Float()
+		}
+	case reflect.String:
+		return func(a, b reflect.Value) bool {
+			return a.String() < b.String()
+		}
+	}
+	return nil
+}
+
+type pipeline struct {
+	consume.Consumer
+	finalize  func()
+	finalized bool
+}
+
+func (p *pipeline) CanConsume() bool {
+	return !p.finalized && p.Consumer.CanConsume()
+}
+
+func (p *pipeline) Consume(ptr interface{}) {
+	consume.MustCanConsume(p)
+	p.Consumer.Consume(ptr)
+}
+
+func (p *pipeline) Finalize() {
+	if p.finalized {
+		return
+	}
+	p.finalized = true
+	p.finalize()
+}
+
+// Healthy reports the health of the sinks in this pipeline.
+func (p *pipeline) Healthy() error {
+	return consume.CheckHealth(p.Consumer)
+}
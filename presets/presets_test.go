@@ -0,0 +1,99 @@
+package presets_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/keep94/consume/presets"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name string
+	Age  int
+}
+
+var people = []person{
+	{Name: "Mark", Age: 50},
+	{Name: "Stoney", Age: 49},
+	{Name: "Matt", Age: 46},
+	{Name: "Dillon", Age: 19},
+	{Name: "Beth", Age: 54},
+}
+
+func TestExportCSVPage(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	var morePages bool
+	cf := presets.ExportCSVPage(&sb, nil, 1, 2, &morePages)
+	consume.FeedSlice(people, cf)
+	assert.False(cf.CanConsume())
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal("Name,Age\nMatt,46\nDillon,19\n", sb.String())
+	assert.True(morePages)
+
+	sb.Reset()
+	cf = presets.ExportCSVPage(&sb, nil, 2, 2, &morePages)
+	consume.FeedSlice(people, cf)
+	cf.Finalize()
+	assert.Equal("Name,Age\nBeth,54\n", sb.String())
+	assert.False(morePages)
+	assert.Panics(func() {
+		presets.ExportCSVPage(&sb, nil, 0, 0, &morePages)
+	})
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestExportCSVPageHealth(t *testing.T) {
+	assert := assert.New(t)
+	var morePages bool
+	cf := presets.ExportCSVPage(errorWriter{}, nil, 0, 2, &morePages)
+	assert.NoError(consume.CheckHealth(cf))
+	consume.FeedSlice(people, cf)
+	cf.Finalize()
+	assert.Error(consume.CheckHealth(cf))
+}
+
+func TestDedupAndCollect(t *testing.T) {
+	assert := assert.New(t)
+	var result []person
+	consume.FeedSlice(
+		[]person{people[0], people[1], people[0], people[1], people[2]},
+		presets.DedupAndCollect(&result, nil))
+	assert.Equal(people[:3], result)
+}
+
+func TestTopNByField(t *testing.T) {
+	assert := assert.New(t)
+	result := []person{{Name: "stale"}}
+	cf := presets.TopNByField(&result, 2, "Age")
+	consume.FeedSlice(people, cf)
+	consume.FeedSlice(people[:1], cf)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Equal([]person{people[4], people[0]}, result)
+
+	cf = presets.TopNByField(&result, 3, "Name")
+	consume.FeedSlice(people, cf)
+	cf.Finalize()
+	assert.Equal([]person{people[1], people[2], people[0]}, result)
+}
+
+func TestTopNByFieldPanics(t *testing.T) {
+	assert := assert.New(t)
+	var result []person
+	var ints []int
+	assert.Panics(func() { presets.TopNByField(&result, 0, "Age") })
+	assert.Panics(func() { presets.TopNByField(&result, 1, "Height") })
+	assert.Panics(func() { presets.TopNByField(&ints, 1, "Age") })
+	assert.Panics(func() { presets.TopNByField(result, 1, "Age") })
+}
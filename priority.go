@@ -0,0 +1,139 @@
+package consume
+
+import (
+	"container/heap"
+	"reflect"
+)
+
+// PriorityMerge returns a ConsumeFinalizer that reorders the values it
+// consumes so that higher priority values reach c first. The returned
+// consumer keeps copies of up to bufferSize values. Once its buffer is
+// full, each value it consumes causes it to pass the highest priority
+// buffered value onto c. When caller calls Finalize, it passes the
+// buffered values onto c from highest to lowest priority. So the values
+// that reach c are only approximately in priority order; the larger
+// bufferSize, the better the ordering. Values with the same priority
+// reach c in the order they were consumed.
+//
+// priorityFunc takes a pointer to a consumed value and returns its
+// priority as a number, e.g func(ptr *Alert) int. priorityFunc can also
+// be a func(ptr interface{}) interface{} that returns a number. Larger
+// numbers mean higher priority. PriorityMerge panics if bufferSize is not
+// positive.
+func PriorityMerge(
+	c Consumer, priorityFunc interface{}, bufferSize int) ConsumeFinalizer {
+	if bufferSize <= 0 {
+		panic("bufferSize must be positive")
+	}
+	return &priorityMergeConsumer{
+		consumer:     c,
+		priorityFunc: newKeyFunc(priorityFunc),
+		bufferSize:   bufferSize,
+	}
+}
+
+type priorityEntry struct {
+	priority float64
+	seq      int
+	valuePtr reflect.Value
+}
+
+// priorityHeap is a max heap of entries. Among entries of equal priority,
+// the one with the lowest seq comes first.
+type priorityHeap []*priorityEntry
+
+func (h priorityHeap) Len() int { return len(h) }
+
+func (h priorityHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h priorityHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *priorityHeap) Push(x interface{}) {
+	*h = append(*h, x.(*priorityEntry))
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	result := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return result
+}
+
+type priorityMergeConsumer struct {
+	consumer     Consumer
+	priorityFunc keyFunc
+	bufferSize   int
+	buffer       priorityHeap
+	free         *priorityEntry
+	seq          int
+	finalized    bool
+}
+
+func (p *priorityMergeConsumer) children() []interface{} {
+	return []interface{}{p.consumer}
+}
+
+func (p *priorityMergeConsumer) CanConsume() bool {
+	return !p.finalized && p.consumer.CanConsume()
+}
+
+func (p *priorityMergeConsumer) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	entry := p.free
+	p.free = nil
+	if entry == nil {
+		entry = &priorityEntry{
+			valuePtr: reflect.New(reflect.TypeOf(ptr).Elem())}
+	}
+	entry.priority = toFloat64(p.priorityFunc.key(ptr))
+	entry.seq = p.seq
+	p.seq++
+	entry.valuePtr.Elem().Set(reflect.ValueOf(ptr).Elem())
+	heap.Push(&p.buffer, entry)
+	if len(p.buffer) > p.bufferSize {
+		p.emit()
+	}
+}
+
+func (p *priorityMergeConsumer) Finalize() {
+	if p.finalized {
+		return
+	}
+	p.finalized = true
+	for len(p.buffer) > 0 && p.consumer.CanConsume() {
+		p.emit()
+	}
+	p.buffer = nil
+	p.free = nil
+}
+
+// emit passes the highest priority buffered value onto the underlying
+// consumer and saves its entry for reuse.
+func (p *priorityMergeConsumer) emit() {
+	entry := heap.Pop(&p.buffer).(*priorityEntry)
+	p.consumer.Consume(entry.valuePtr.Interface())
+	p.free = entry
+}
+
+// toFloat64 converts x, which must be a number, to a float64.
+func toFloat64(x interface{}) float64 {
+	value := reflect.ValueOf(x)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	default:
+		panic("priorityFunc must return a number")
+	}
+}
@@ -0,0 +1,63 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityMerge(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.PriorityMerge(
+		consume.AppendTo(&ints), func(ptr *int) int { return *ptr }, 3)
+	for _, x := range []int{5, 1, 9, 3, 7, 2, 8} {
+		cf.Consume(&x)
+	}
+	assert.Equal([]int{9, 7, 5, 8}, ints)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal([]int{9, 7, 5, 8, 3, 2, 1}, ints)
+	assert.False(cf.CanConsume())
+}
+
+func TestPriorityMergeTies(t *testing.T) {
+	assert := assert.New(t)
+	var result []person
+	cf := consume.PriorityMerge(
+		consume.AppendTo(&result),
+		func(ptr interface{}) interface{} {
+			return ptr.(*person).Age / 100
+		},
+		10)
+	for i := range people {
+		cf.Consume(&people[i])
+	}
+	cf.Finalize()
+	assert.Equal(people, result)
+}
+
+func TestPriorityMergeStopsEarly(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.PriorityMerge(
+		consume.Slice(consume.AppendTo(&ints), 0, 2),
+		func(ptr *int) float64 { return -float64(*ptr) },
+		100)
+	feedInts(t, consume.Slice(cf, 0, 10))
+	cf.Finalize()
+	assert.Equal([]int{0, 1}, ints)
+}
+
+func TestPriorityMergePanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.PriorityMerge(
+			consume.Nil(), func(ptr *int) int { return *ptr }, 0)
+	})
+	var strs []string
+	cf := consume.PriorityMerge(
+		consume.AppendTo(&strs), func(ptr *string) string { return *ptr }, 1)
+	assert.Panics(func() { cf.Consume(new(string)) })
+}
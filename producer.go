@@ -0,0 +1,114 @@
+package consume
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Producer produces values one at a time.
+type Producer interface {
+
+	// Produce returns a pointer to the next value or nil if there are no
+	// more values. Once Produce returns nil, it should always return nil.
+	// The value that the returned pointer points to may change with each
+	// call to Produce.
+	Produce() interface{}
+}
+
+// The ProducerFunc type is an adapter to allow the use of an ordinary
+// function as a Producer.
+type ProducerFunc func() interface{}
+
+// Produce invokes p, this function.
+func (p ProducerFunc) Produce() interface{} {
+	return p()
+}
+
+// Prefetch returns a Producer that produces the same values as p. The
+// returned Producer runs p on a separate goroutine buffering up to depth
+// values ahead of the caller. Because p may change the value it returns
+// with each call, the returned Producer copies each value. The copies are
+// shallow. The pointer that the returned Producer returns stays valid
+// until the next call to Produce. The goroutine ends when p runs out of
+// values. The returned Producer also implements io.Closer. Caller should
+// call Close to end the goroutine if it stops calling Produce before p
+// runs out of values. Prefetch panics if depth is not positive.
+func Prefetch(p Producer, depth int) Producer {
+	if depth <= 0 {
+		panic("depth must be positive")
+	}
+	result := &prefetchProducer{
+		values: make(chan interface{}, depth),
+		free:   make(chan interface{}, depth+2),
+		done:   make(chan struct{}),
+	}
+	go result.run(p)
+	return result
+}
+
+type prefetchProducer struct {
+	values    chan interface{}
+	free      chan interface{}
+	done      chan struct{}
+	closeOnce sync.Once
+	current   interface{}
+}
+
+func (p *prefetchProducer) Produce() interface{} {
+	if p.current != nil {
+		select {
+		case p.free <- p.current:
+		default:
+		}
+		p.current = nil
+	}
+	select {
+	case <-p.done:
+		return nil
+	default:
+	}
+	select {
+	case <-p.done:
+		return nil
+	case ptr, ok := <-p.values:
+		if !ok {
+			return nil
+		}
+		p.current = ptr
+		return ptr
+	}
+}
+
+// Close ends the goroutine running the underlying Producer. After Close,
+// Produce returns nil. Close always returns nil.
+func (p *prefetchProducer) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *prefetchProducer) run(producer Producer) {
+	defer close(p.values)
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		ptr := producer.Produce()
+		if ptr == nil {
+			return
+		}
+		var slot interface{}
+		select {
+		case slot = <-p.free:
+			reflect.ValueOf(slot).Elem().Set(reflect.ValueOf(ptr).Elem())
+		default:
+			slot = shallowCopy(ptr)
+		}
+		select {
+		case <-p.done:
+			return
+		case p.values <- slot:
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package consume_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefetch(t *testing.T) {
+	assert := assert.New(t)
+	producer := consume.Prefetch(intProducer(0, 1, 2, 3, 4, 5, 6), 2)
+	var result []int
+	for ptr := producer.Produce(); ptr != nil; ptr = producer.Produce() {
+		result = append(result, *ptr.(*int))
+	}
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6}, result)
+	assert.Nil(producer.Produce())
+}
+
+func TestPrefetchClose(t *testing.T) {
+	assert := assert.New(t)
+	producer := consume.Prefetch(intProducer(0, 1, 2, 3, 4, 5, 6), 1)
+	assert.Equal(0, *producer.Produce().(*int))
+	assert.NoError(producer.(io.Closer).Close())
+	assert.NoError(producer.(io.Closer).Close())
+	assert.Nil(producer.Produce())
+}
+
+func TestPrefetchPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() { consume.Prefetch(intProducer(), 0) })
+}
@@ -0,0 +1,120 @@
+package consume
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+)
+
+// ProfileReport summarizes how long a consumer took to consume the
+// values that Profiled sampled.
+type ProfileReport struct {
+
+	// Stage is the name of the profiled consumer as reported by Describe.
+	Stage string
+
+	// Consumed is the number of values consumed.
+	Consumed int64
+
+	// Sampled is the number of values that were timed.
+	Sampled int64
+
+	// Total is the total time spent consuming the sampled values.
+	Total time.Duration
+
+	// Min is the shortest time spent consuming a sampled value. Min is 0
+	// if Sampled is 0.
+	Min time.Duration
+
+	// Max is the longest time spent consuming a sampled value.
+	Max time.Duration
+}
+
+// Avg returns the average time spent consuming a sampled value or 0 if
+// no values were sampled.
+func (p *ProfileReport) Avg() time.Duration {
+	if p.Sampled == 0 {
+		return 0
+	}
+	return p.Total / time.Duration(p.Sampled)
+}
+
+// Estimated returns the estimated time spent consuming all the values
+// based on the sampled values.
+func (p *ProfileReport) Estimated() time.Duration {
+	return p.Avg() * time.Duration(p.Consumed)
+}
+
+// ProfileReporter is implemented by the consumers that Profiled returns.
+type ProfileReporter interface {
+
+	// ProfileReport returns the report so far.
+	ProfileReport() ProfileReport
+}
+
+// Profiled returns a Consumer that passes the values it consumes onto c
+// timing every sampleEvery th value starting with the first. While c
+// consumes a sampled value, the goroutine carries the pprof label
+// "consume.stage" set to the name of c from Describe so that CPU profiles
+// can attribute cost to the stage. Sampling keeps the overhead low for
+// cheap stages. The returned consumer implements ProfileReporter which
+// gives the aggregated timings. The CanConsume method of returned
+// consumer returns the same as c.CanConsume(). Profiled panics if
+// sampleEvery is not positive.
+func Profiled(c Consumer, sampleEvery int) Consumer {
+	if sampleEvery <= 0 {
+		panic("sampleEvery must be positive")
+	}
+	stage := Describe(c).Name
+	return &profiledConsumer{
+		Consumer:    c,
+		sampleEvery: int64(sampleEvery),
+		labels:      pprof.Labels("consume.stage", stage),
+		report:      ProfileReport{Stage: stage},
+		clock:       systemClock{},
+	}
+}
+
+type profiledConsumer struct {
+	Consumer
+	sampleEvery int64
+	labels      pprof.LabelSet
+	report      ProfileReport
+	clock       Clock
+}
+
+func (p *profiledConsumer) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
+func (p *profiledConsumer) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	sample := p.report.Consumed%p.sampleEvery == 0
+	p.report.Consumed++
+	if !sample {
+		p.Consumer.Consume(ptr)
+		return
+	}
+	var elapsed time.Duration
+	pprof.Do(context.Background(), p.labels, func(context.Context) {
+		start := p.clock.Now()
+		p.Consumer.Consume(ptr)
+		elapsed = p.clock.Now().Sub(start)
+	})
+	p.record(elapsed)
+}
+
+func (p *profiledConsumer) ProfileReport() ProfileReport {
+	return p.report
+}
+
+func (p *profiledConsumer) record(elapsed time.Duration) {
+	if p.report.Sampled == 0 || elapsed < p.report.Min {
+		p.report.Min = elapsed
+	}
+	if elapsed > p.report.Max {
+		p.report.Max = elapsed
+	}
+	p.report.Sampled++
+	p.report.Total += elapsed
+}
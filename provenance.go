@@ -0,0 +1,179 @@
+package consume
+
+import (
+	"fmt"
+)
+
+// ProvenanceAction is what happened to a value at a stage.
+type ProvenanceAction int
+
+const (
+
+	// ProvenanceReached means the value reached the stage.
+	ProvenanceReached ProvenanceAction = iota + 1
+
+	// ProvenanceMapped means a function of the stage mapped the value to
+	// a new value.
+	ProvenanceMapped
+
+	// ProvenanceDropped means a function of the stage filtered the value
+	// out.
+	ProvenanceDropped
+)
+
+func (a ProvenanceAction) String() string {
+	switch a {
+	case ProvenanceReached:
+		return "Reached"
+	case ProvenanceMapped:
+		return "Mapped"
+	case ProvenanceDropped:
+		return "Dropped"
+	default:
+		return "ProvenanceAction(?)"
+	}
+}
+
+// ProvenanceEvent records what happened to a value at a stage.
+type ProvenanceEvent struct {
+
+	// Stage is the name of the stage.
+	Stage string
+
+	// Func is the zero based position of the function within the stage
+	// that mapped or dropped the value. Func is 0 for
+	// ProvenanceReached.
+	Func int
+
+	// Action is what happened to the value.
+	Action ProvenanceAction
+}
+
+func (e ProvenanceEvent) String() string {
+	if e.Action == ProvenanceReached {
+		return fmt.Sprintf("%s: %v", e.Stage, e.Action)
+	}
+	return fmt.Sprintf("%s[%d]: %v", e.Stage, e.Func, e.Action)
+}
+
+// Provenance records what each stage of a pipeline does with each value
+// so that caller can later find out why a value did or did not make it
+// through the pipeline. To use, wrap the first stage of the pipeline
+// with Source and build the stages to track with MapFilter and Stage.
+// Source numbers values in the order it consumes them starting at 0.
+// Provenance assumes that each value flows through the whole pipeline
+// before Source consumes the next value, so it does not work with
+// consumers that buffer values or pass them to other goroutines.
+// Provenance instances are not safe to use with multiple goroutines.
+type Provenance struct {
+	current int
+	next    int
+	events  map[int][]ProvenanceEvent
+}
+
+// NewProvenance returns a new Provenance with nothing recorded.
+func NewProvenance() *Provenance {
+	return &Provenance{events: make(map[int][]ProvenanceEvent)}
+}
+
+// Source returns a Consumer that numbers each value it consumes before
+// passing it onto c. The CanConsume method of the returned consumer
+// returns the same as c.CanConsume().
+func (p *Provenance) Source(c Consumer) Consumer {
+	return &provenanceSource{Consumer: c, provenance: p}
+}
+
+// MapFilter works like the MapFilter function except that the returned
+// consumer records under stage each value that one of funcs maps or
+// filters out.
+func (p *Provenance) MapFilter(
+	consumer Consumer, stage string, funcs ...interface{}) Consumer {
+	mapFilters := NewMapFilterer(funcs...)
+	return &provenanceMapFilter{
+		Consumer:   consumer,
+		provenance: p,
+		stage:      stage,
+		stages:     mapFilters.stages(),
+		ctx:        mapFilters.NewContext(),
+	}
+}
+
+// Stage returns a Consumer that records under stage each value that
+// reaches it before passing the value onto c. Use Stage to mark points
+// in a pipeline such as the final sink.
+func (p *Provenance) Stage(c Consumer, stage string) Consumer {
+	return &provenanceStage{Consumer: c, provenance: p, stage: stage}
+}
+
+// Report returns what happened to the index th value consumed by Source
+// in the order it happened.
+func (p *Provenance) Report(index int) []ProvenanceEvent {
+	return p.events[index]
+}
+
+func (p *Provenance) record(
+	stage string, funcIdx int, action ProvenanceAction) {
+	p.events[p.current] = append(
+		p.events[p.current],
+		ProvenanceEvent{Stage: stage, Func: funcIdx, Action: action})
+}
+
+type provenanceSource struct {
+	Consumer
+	provenance *Provenance
+}
+
+func (p *provenanceSource) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
+func (p *provenanceSource) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	p.provenance.current = p.provenance.next
+	p.provenance.next++
+	p.Consumer.Consume(ptr)
+}
+
+type provenanceMapFilter struct {
+	Consumer
+	provenance *Provenance
+	stage      string
+	stages     []mapFilterStage
+	ctx        *MapFilterContext
+}
+
+func (p *provenanceMapFilter) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
+func (p *provenanceMapFilter) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	for i, stage := range p.stages {
+		mapped := stage.mapFilter(ptr, p.ctx.scratch[i])
+		if mapped == nil {
+			p.provenance.record(p.stage, i, ProvenanceDropped)
+			return
+		}
+		if mapped != ptr {
+			p.provenance.record(p.stage, i, ProvenanceMapped)
+		}
+		ptr = mapped
+	}
+	p.Consumer.Consume(ptr)
+}
+
+type provenanceStage struct {
+	Consumer
+	provenance *Provenance
+	stage      string
+}
+
+func (p *provenanceStage) children() []interface{} {
+	return []interface{}{p.Consumer}
+}
+
+func (p *provenanceStage) Consume(ptr interface{}) {
+	MustCanConsume(p)
+	p.provenance.record(p.stage, 0, ProvenanceReached)
+	p.Consumer.Consume(ptr)
+}
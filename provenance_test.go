@@ -0,0 +1,44 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvenance(t *testing.T) {
+	assert := assert.New(t)
+	var strs []string
+	p := consume.NewProvenance()
+	consumer := p.Source(p.MapFilter(
+		consume.Slice(p.Stage(consume.AppendTo(&strs), "sink"), 0, 2),
+		"clean",
+		func(ptr *int) bool { return (*ptr)%2 == 0 },
+		func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		},
+		func(ptr *string) bool { return *ptr != "2" }))
+	for i := 0; consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]string{"0", "4"}, strs)
+	assert.Equal(
+		[]consume.ProvenanceEvent{
+			{Stage: "clean", Func: 1, Action: consume.ProvenanceMapped},
+			{Stage: "sink", Action: consume.ProvenanceReached},
+		},
+		p.Report(0))
+	assert.Equal(
+		[]consume.ProvenanceEvent{
+			{Stage: "clean", Func: 0, Action: consume.ProvenanceDropped},
+		},
+		p.Report(1))
+	report := p.Report(2)
+	assert.Len(report, 2)
+	assert.Equal("clean[2]: Dropped", report[1].String())
+
+	assert.Empty(p.Report(5))
+}
@@ -0,0 +1,147 @@
+package consume
+
+import (
+	"time"
+)
+
+// RateLimitPerKey returns a Consumer that passes values onto c at a
+// limited rate for each key. keyFunc extracts the key from a value. It
+// takes a pointer to the value and returns the key which must support
+// equality. keyFunc may also be a func(ptr interface{}) interface{}. For
+// each key, the returned consumer passes on at most burst values at once
+// and then one more value for every per duration that elapses. It drops
+// values that exceed the limit. The returned consumer periodically
+// forgets the keys whose limit has fully reset so that memory stays
+// bounded by the number of recently seen keys. The CanConsume method of
+// returned consumer returns the same as c.CanConsume(). RateLimitPerKey
+// panics if per or burst are not positive or if keyFunc returns keys that
+// don't support equality.
+func RateLimitPerKey(
+	c Consumer,
+	keyFunc interface{},
+	per time.Duration,
+	burst int) Consumer {
+	if per <= 0 {
+		panic("per must be positive")
+	}
+	if burst <= 0 {
+		panic("burst must be positive")
+	}
+	return &rateLimitPerKeyConsumer{
+		Consumer:  c,
+		keyFunc:   newMapKeyFunc(keyFunc),
+		per:       per,
+		tolerance: time.Duration(burst-1) * per,
+		arrivals:  make(map[interface{}]time.Time),
+		clock:     systemClock{},
+	}
+}
+
+// rateLimitPerKeyConsumer uses the generic cell rate algorithm. For
+// each key, arrivals stores the earliest time the bucket for that key
+// is empty. A key whose bucket is empty behaves the same as a key never
+// seen, so sweep removes such keys from arrivals. Since no arrival is
+// more than per + tolerance in the future, sweeping once per that
+// interval keeps arrivals from holding keys that went idle long ago.
+type rateLimitPerKeyConsumer struct {
+	Consumer
+	keyFunc   keyFunc
+	per       time.Duration
+	tolerance time.Duration
+	arrivals  map[interface{}]time.Time
+	nextSweep time.Time
+	clock     Clock
+}
+
+func (r *rateLimitPerKeyConsumer) children() []interface{} {
+	return []interface{}{r.Consumer}
+}
+
+func (r *rateLimitPerKeyConsumer) Consume(ptr interface{}) {
+	MustCanConsume(r)
+	key := r.keyFunc.key(ptr)
+	now := r.clock.Now()
+	if !now.Before(r.nextSweep) {
+		r.sweep(now)
+	}
+	arrival := r.arrivals[key]
+	if arrival.Before(now) {
+		arrival = now
+	}
+	if arrival.Sub(now) > r.tolerance {
+		return
+	}
+	r.arrivals[key] = arrival.Add(r.per)
+	r.Consumer.Consume(ptr)
+}
+
+// sweep removes the keys whose buckets are empty at now.
+func (r *rateLimitPerKeyConsumer) sweep(now time.Time) {
+	for key, arrival := range r.arrivals {
+		if !arrival.After(now) {
+			delete(r.arrivals, key)
+		}
+	}
+	r.nextSweep = now.Add(r.per + r.tolerance)
+}
+
+// Throttle returns a Consumer that passes at most maxPerWindow values
+// onto c in each time window of length window. The first window starts
+// when the returned consumer consumes its first value. Each later window
+// starts when the returned consumer consumes a value after the previous
+// window ends. Values exceeding the limit go to overflow if overflow can
+// consume them; otherwise they are dropped. overflow may be nil. The
+// CanConsume method of returned consumer returns the same as
+// c.CanConsume(). Throttle panics if maxPerWindow or window are not
+// positive.
+func Throttle(
+	c Consumer,
+	maxPerWindow int,
+	window time.Duration,
+	overflow Consumer) Consumer {
+	if maxPerWindow <= 0 {
+		panic("maxPerWindow must be positive")
+	}
+	if window <= 0 {
+		panic("window must be positive")
+	}
+	if overflow == nil {
+		overflow = nilConsumer{}
+	}
+	return &throttleConsumer{
+		Consumer:     c,
+		maxPerWindow: maxPerWindow,
+		window:       window,
+		overflow:     overflow,
+		clock:        systemClock{},
+	}
+}
+
+type throttleConsumer struct {
+	Consumer
+	maxPerWindow int
+	window       time.Duration
+	overflow     Consumer
+	windowEnd    time.Time
+	count        int
+	clock        Clock
+}
+
+func (t *throttleConsumer) children() []interface{} {
+	return []interface{}{t.Consumer, t.overflow}
+}
+
+func (t *throttleConsumer) Consume(ptr interface{}) {
+	MustCanConsume(t)
+	now := t.clock.Now()
+	if !now.Before(t.windowEnd) {
+		t.windowEnd = now.Add(t.window)
+		t.count = 0
+	}
+	if t.count < t.maxPerWindow {
+		t.count++
+		t.Consumer.Consume(ptr)
+	} else if t.overflow.CanConsume() {
+		t.overflow.Consume(ptr)
+	}
+}
@@ -0,0 +1,147 @@
+package consume
+
+import (
+	"container/heap"
+	"reflect"
+)
+
+// Reorder returns a ConsumeFinalizer that passes the values it consumes
+// onto c in order of their sequence numbers. Sequence numbers start at 0
+// with no gaps. seqFunc takes a pointer to a consumed value and returns
+// its sequence number as an integer, e.g func(ptr *Event) int64. seqFunc
+// can also be a func(ptr interface{}) interface{} that returns an
+// integer.
+//
+// The returned consumer passes a value onto c as soon as all the values
+// with smaller sequence numbers have been passed onto c. Until then, it
+// keeps a copy of the value. If it has to keep more than maxOutOfOrder
+// values, it gives up on the missing sequence numbers and passes the
+// value with the smallest sequence number onto c. Values that show up
+// after their sequence number was given up on, as well as values with
+// duplicate sequence numbers, are dropped. When caller calls Finalize,
+// the returned consumer passes the remaining values onto c in order.
+// Reorder panics if maxOutOfOrder is negative.
+func Reorder(
+	c Consumer, seqFunc interface{}, maxOutOfOrder int) ConsumeFinalizer {
+	if maxOutOfOrder < 0 {
+		panic("maxOutOfOrder must be non-negative")
+	}
+	return &reorderConsumer{
+		consumer:      c,
+		seqFunc:       newKeyFunc(seqFunc),
+		maxOutOfOrder: maxOutOfOrder,
+	}
+}
+
+type seqEntry struct {
+	seq      int64
+	valuePtr reflect.Value
+}
+
+// seqHeap is a min heap of entries by sequence number.
+type seqHeap []*seqEntry
+
+func (h seqHeap) Len() int { return len(h) }
+
+func (h seqHeap) Less(i, j int) bool { return h[i].seq < h[j].seq }
+
+func (h seqHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *seqHeap) Push(x interface{}) {
+	*h = append(*h, x.(*seqEntry))
+}
+
+func (h *seqHeap) Pop() interface{} {
+	old := *h
+	result := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return result
+}
+
+type reorderConsumer struct {
+	consumer      Consumer
+	seqFunc       keyFunc
+	maxOutOfOrder int
+	next          int64
+	buffer        seqHeap
+	free          []*seqEntry
+	finalized     bool
+}
+
+func (r *reorderConsumer) children() []interface{} {
+	return []interface{}{r.consumer}
+}
+
+func (r *reorderConsumer) CanConsume() bool {
+	return !r.finalized && r.consumer.CanConsume()
+}
+
+func (r *reorderConsumer) Consume(ptr interface{}) {
+	MustCanConsume(r)
+	seq := toInt64(r.seqFunc.key(ptr))
+	if seq < r.next {
+		return
+	}
+	if seq == r.next {
+		r.consumer.Consume(ptr)
+		r.next++
+		r.release()
+		return
+	}
+	var entry *seqEntry
+	if n := len(r.free); n > 0 {
+		entry = r.free[n-1]
+		r.free = r.free[:n-1]
+	} else {
+		entry = &seqEntry{valuePtr: reflect.New(reflect.TypeOf(ptr).Elem())}
+	}
+	entry.seq = seq
+	entry.valuePtr.Elem().Set(reflect.ValueOf(ptr).Elem())
+	heap.Push(&r.buffer, entry)
+	if len(r.buffer) > r.maxOutOfOrder {
+		r.next = r.buffer[0].seq
+		r.release()
+	}
+}
+
+func (r *reorderConsumer) Finalize() {
+	if r.finalized {
+		return
+	}
+	r.finalized = true
+	for len(r.buffer) > 0 && r.consumer.CanConsume() {
+		r.next = r.buffer[0].seq
+		r.release()
+	}
+	r.buffer = nil
+	r.free = nil
+}
+
+// release passes the buffered values whose turn has come onto the
+// underlying consumer dropping buffered duplicates along the way.
+func (r *reorderConsumer) release() {
+	for len(r.buffer) > 0 && r.buffer[0].seq <= r.next {
+		entry := heap.Pop(&r.buffer).(*seqEntry)
+		if entry.seq == r.next && r.consumer.CanConsume() {
+			r.consumer.Consume(entry.valuePtr.Interface())
+			r.next++
+		}
+		r.free = append(r.free, entry)
+	}
+}
+
+// toInt64 converts x, which must be an integer, to an int64.
+func toInt64(x interface{}) int64 {
+	value := reflect.ValueOf(x)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return int64(value.Uint())
+	default:
+		panic("seqFunc must return an integer")
+	}
+}
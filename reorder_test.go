@@ -0,0 +1,63 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorder(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.Reorder(
+		consume.AppendTo(&ints), func(ptr *int) int { return *ptr }, 3)
+	for _, x := range []int{1, 0, 3, 2, 2, 5, 4, 1} {
+		cf.Consume(&x)
+	}
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, ints)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal([]int{0, 1, 2, 3, 4, 5}, ints)
+	assert.False(cf.CanConsume())
+}
+
+func TestReorderGap(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.Reorder(
+		consume.AppendTo(&ints),
+		func(ptr interface{}) interface{} { return uint(*ptr.(*int)) },
+		2)
+	for _, x := range []int{0, 2, 3, 5, 1, 7, 6} {
+		cf.Consume(&x)
+	}
+
+	// Waiting for 1 gives up when 5 arrives. 1 arrives too late. Waiting
+	// for 4 gives up when 6 arrives.
+	assert.Equal([]int{0, 2, 3, 5, 6, 7}, ints)
+	cf.Finalize()
+	assert.Equal([]int{0, 2, 3, 5, 6, 7}, ints)
+}
+
+func TestReorderFinalize(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.Reorder(
+		consume.AppendTo(&ints),
+		func(ptr *int) int64 { return int64(*ptr) },
+		10)
+	for _, x := range []int{8, 3, 5, 5} {
+		cf.Consume(&x)
+	}
+	assert.Empty(ints)
+	cf.Finalize()
+	assert.Equal([]int{3, 5, 8}, ints)
+}
+
+func TestReorderPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.Reorder(consume.Nil(), func(ptr *int) int { return *ptr }, -1)
+	})
+}
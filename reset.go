@@ -0,0 +1,68 @@
+package consume
+
+// Resetter is implemented by consumers that can be reused. Reset returns
+// a consumer to the state it was in right after it was created so that
+// a pipeline can be built once and then run many times. Consumers that
+// wrap other consumers reset the consumers they wrap that are Resetters.
+type Resetter interface {
+	Reset()
+}
+
+// reset resets c if c is a Resetter.
+func reset(c Consumer) {
+	if r, ok := c.(Resetter); ok {
+		r.Reset()
+	}
+}
+
+// Reset empties the slice that this consumer appends to keeping its
+// capacity. Note that values appended after Reset overwrite the values
+// in any copy of the slice made before Reset.
+func (a *appendConsumer) Reset() {
+	a.buffer.Set(a.buffer.Slice(0, 0))
+}
+
+// Reset empties the slice that this consumer appends to keeping its
+// capacity. After Reset, this consumer can consume values even if it was
+// finalized.
+func (a *appendSaveMemoryConsumer) Reset() {
+	a.length = 0
+	a.finalized = false
+	expandToCapacity(a.buffer)
+}
+
+func (s *sliceConsumer) Reset() {
+	s.idx = 0
+	reset(s.consumer)
+}
+
+func (s *stepSliceConsumer) Reset() {
+	s.idx = 0
+	reset(s.consumer)
+}
+
+// Reset resets each consumer that this consumer was composed of including
+// the ones that stopped consuming.
+func (m *multiConsumer) Reset() {
+	m.consumers = append(m.consumers[:0], m.all...)
+	for _, consumer := range m.all {
+		reset(consumer)
+	}
+}
+
+// Reset empties the page slice. After Reset, this consumer can consume
+// values even if it was finalized.
+func (p *pageConsumer) Reset() {
+	p.finalized = false
+	p.Consumer = p.slice
+	reset(p.slice)
+}
+
+func (m *mapFilterConsumer) Reset() {
+	reset(m.Consumer)
+}
+
+func (t *takeWhileConsumer) Reset() {
+	t.done = false
+	reset(t.consumer)
+}
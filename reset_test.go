@@ -0,0 +1,100 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetAppendTo(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.AppendTo(&ints)
+	feedInts(t, consume.Slice(consumer, 0, 5))
+	capacity := cap(ints)
+	consumer.(consume.Resetter).Reset()
+	assert.Empty(ints)
+	assert.Equal(capacity, cap(ints))
+	feedInts(t, consume.Slice(consumer, 0, 2))
+	assert.Equal([]int{0, 1}, ints)
+}
+
+func TestResetAppendToSaveMemory(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.AppendToSaveMemory(&ints)
+	feedInts(t, consume.Slice(cf, 0, 10))
+	cf.Finalize()
+	assert.Len(ints, 10)
+	cf.(consume.Resetter).Reset()
+	assert.True(cf.CanConsume())
+	feedInts(t, consume.Slice(cf, 0, 3))
+	cf.Finalize()
+	assert.Equal([]int{0, 1, 2}, ints)
+}
+
+func TestResetPipeline(t *testing.T) {
+	assert := assert.New(t)
+	var strs []string
+	consumer := consume.MapFilter(
+		consume.Slice(consume.AppendTo(&strs), 1, 3),
+		func(ptr *int) bool { return (*ptr)%2 == 0 },
+		func(src *int, dest *string) bool {
+			*dest = strconv.Itoa(*src)
+			return true
+		})
+	feedInts(t, consumer)
+	assert.Equal([]string{"2", "4"}, strs)
+	consumer.(consume.Resetter).Reset()
+	assert.Empty(strs)
+	assert.True(consumer.CanConsume())
+	feedInts(t, consumer)
+	assert.Equal([]string{"2", "4"}, strs)
+}
+
+func TestResetTakeWhile(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer := consume.TakeWhile(
+		consume.AppendTo(&ints), func(ptr *int) bool { return *ptr < 3 })
+	feedInts(t, consumer)
+	consumer.(consume.Resetter).Reset()
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1, 2}, ints)
+}
+
+func TestResetCompose(t *testing.T) {
+	assert := assert.New(t)
+	var first, second []int
+	consumer := consume.Compose(
+		consume.Slice(consume.AppendTo(&first), 0, 2),
+		consume.Slice(consume.AppendTo(&second), 0, 4))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1}, first)
+	assert.Equal([]int{0, 1, 2, 3}, second)
+	consumer.(consume.Resetter).Reset()
+	assert.Empty(first)
+	assert.Empty(second)
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1}, first)
+	assert.Equal([]int{0, 1, 2, 3}, second)
+}
+
+func TestResetPage(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var morePages bool
+	cf := consume.Page(1, 3, &ints, &morePages)
+	feedInts(t, cf)
+	cf.Finalize()
+	assert.Equal([]int{3, 4, 5}, ints)
+	assert.True(morePages)
+	cf.(consume.Resetter).Reset()
+	assert.True(cf.CanConsume())
+	feedInts(t, consume.Slice(cf, 0, 5))
+	cf.Finalize()
+	assert.Equal([]int{3, 4}, ints)
+	assert.False(morePages)
+}
@@ -0,0 +1,147 @@
+package consume
+
+import (
+	"reflect"
+)
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Result holds either a value or the error that happened while
+// computing it. Results let fallible transformations flow through a
+// pipeline without panicking. Use AsResult to turn values into Results,
+// MapOk to transform the values of successful Results, and Ok and Err to
+// split Results back into values and errors.
+type Result struct {
+
+	// Value points to the value. Value is nil if Err is non-nil. Like
+	// any pointer passed to Consume, Value is valid only until Consume
+	// returns.
+	Value interface{}
+
+	// Err is the error that happened.
+	Err error
+}
+
+// AsResult returns a Mapper that maps *T values to *Result values holding
+// them.
+func AsResult() Mapper {
+	return &asResultMapper{}
+}
+
+// MapOk returns a MapFilterer of *Result values that transforms the value
+// of each successful Result with f. f takes a pointer to the value
+// followed by a pointer to where the transformed value goes and returns
+// an error if the transformation fails, e.g
+// func(src *string, dest *int) error. If f returns an error, the mapped
+// Result holds that error. Results that already hold an error pass
+// through unchanged. MapOk panics if f has the wrong type.
+func MapOk(f interface{}) MapFilterer {
+	ftype := reflect.TypeOf(f)
+	if ftype == nil || ftype.Kind() != reflect.Func ||
+		ftype.NumIn() != 2 || ftype.NumOut() != 1 ||
+		ftype.In(0).Kind() != reflect.Ptr ||
+		ftype.In(1).Kind() != reflect.Ptr ||
+		ftype.Out(0) != errorType {
+		panic("f must be like func(src *T, dest *U) error")
+	}
+	return &stageMapFilterer{list: []mapFilterStage{&mapOkStage{
+		value: reflect.ValueOf(f), resultType: ftype.In(1).Elem()}}}
+}
+
+// Ok returns a MapFilterer that maps each successful *Result to a pointer
+// to its value and filters out Results holding an error.
+func Ok() MapFilterer {
+	return &stageMapFilterer{list: []mapFilterStage{okStage{}}}
+}
+
+// Err returns a MapFilterer that maps each *Result holding an error to
+// a *error and filters out successful Results. Pass its output to
+// FirstError to fail fast.
+func Err() MapFilterer {
+	return &stageMapFilterer{list: []mapFilterStage{errStage{}}}
+}
+
+// CollectErrors returns a Consumer of *Result values that appends the
+// error of each Result holding an error to the slice errsPtr points to.
+// The returned consumer ignores successful Results and can always
+// consume.
+func CollectErrors(errsPtr *[]error) Consumer {
+	return ConsumerFunc(func(ptr interface{}) {
+		if err := ptr.(*Result).Err; err != nil {
+			*errsPtr = append(*errsPtr, err)
+		}
+	})
+}
+
+type asResultMapper struct {
+	result Result
+}
+
+func (a *asResultMapper) Map(ptr interface{}) interface{} {
+	a.result = Result{Value: ptr}
+	return &a.result
+}
+
+func (a *asResultMapper) Clone() Mapper {
+	return &asResultMapper{}
+}
+
+type mapOkStage struct {
+	value      reflect.Value
+	resultType reflect.Type
+}
+
+type mapOkScratch struct {
+	dest   reflect.Value
+	idest  interface{}
+	result Result
+}
+
+func (m *mapOkStage) newScratch() interface{} {
+	dest := reflect.New(m.resultType)
+	return &mapOkScratch{dest: dest, idest: dest.Interface()}
+}
+
+func (m *mapOkStage) mapFilter(
+	ptr interface{}, scratch interface{}) interface{} {
+	src := ptr.(*Result)
+	if src.Err != nil {
+		return ptr
+	}
+	s := scratch.(*mapOkScratch)
+	params := [...]reflect.Value{reflect.ValueOf(src.Value), s.dest}
+	if err := m.value.Call(params[:])[0].Interface(); err != nil {
+		s.result = Result{Err: err.(error)}
+	} else {
+		s.result = Result{Value: s.idest}
+	}
+	return &s.result
+}
+
+type okStage struct{}
+
+func (o okStage) newScratch() interface{} { return nil }
+
+func (o okStage) mapFilter(ptr interface{}, scratch interface{}) interface{} {
+	src := ptr.(*Result)
+	if src.Err != nil {
+		return nil
+	}
+	return src.Value
+}
+
+type errStage struct{}
+
+func (e errStage) newScratch() interface{} { return new(error) }
+
+func (e errStage) mapFilter(ptr interface{}, scratch interface{}) interface{} {
+	src := ptr.(*Result)
+	if src.Err == nil {
+		return nil
+	}
+	errPtr := scratch.(*error)
+	*errPtr = src.Err
+	return errPtr
+}
@@ -0,0 +1,52 @@
+package consume_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	var errs []error
+	var firstErr error
+	consumer := consume.MapFilter(
+		consume.Compose(
+			consume.MapFilter(consume.AppendTo(&ints), consume.Ok()),
+			consume.CollectErrors(&errs),
+			consume.MapFilter(
+				consume.ConsumerFunc(func(ptr interface{}) {
+					if firstErr == nil {
+						firstErr = *ptr.(*error)
+					}
+				}),
+				consume.Err())),
+		consume.AsResult(),
+		consume.MapOk(func(src *string, dest *int) error {
+			var err error
+			*dest, err = strconv.Atoi(*src)
+			return err
+		}),
+		consume.MapOk(func(src *int, dest *int) error {
+			*dest = 2 * *src
+			return nil
+		}))
+	for _, s := range []string{"1", "x", "3", "y"} {
+		consumer.Consume(&s)
+	}
+	assert.Equal([]int{2, 6}, ints)
+	assert.Len(errs, 2)
+	assert.Equal(errs[0], firstErr)
+	assert.Contains(firstErr.Error(), "\"x\"")
+}
+
+func TestMapOkPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.MapOk(func(src *string, dest *int) bool { return true })
+	})
+	assert.Panics(func() { consume.MapOk(nil) })
+}
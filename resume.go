@@ -0,0 +1,71 @@
+package consume
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ResumeToken records how far a consumer got through a sequence of values
+// so that a later run can pick up where it left off. The zero value
+// means start from the beginning.
+type ResumeToken struct {
+
+	// Offset is the number of values already consumed.
+	Offset int
+}
+
+// String encodes this token as a string suitable for an API response or
+// for storage. ParseResumeToken decodes the string.
+func (r ResumeToken) String() string {
+	return strconv.Itoa(r.Offset)
+}
+
+// ParseResumeToken decodes a string that ResumeToken.String returned. An
+// empty string decodes to the zero ResumeToken.
+func ParseResumeToken(s string) (ResumeToken, error) {
+	if s == "" {
+		return ResumeToken{}, nil
+	}
+	offset, err := strconv.Atoi(s)
+	if err != nil || offset < 0 {
+		return ResumeToken{}, errors.New("consume: invalid resume token")
+	}
+	return ResumeToken{Offset: offset}, nil
+}
+
+// SliceFrom returns a Consumer that skips the values that token says were
+// already consumed and passes the rest onto c. SliceFrom expects to
+// consume the same values in the same order as the run that produced
+// token. SliceFrom also returns a function that returns a ResumeToken
+// for the values consumed so far. A later run passes that token to
+// SliceFrom to continue from there. The returned consumer can consume as
+// long as c can.
+func SliceFrom(c Consumer, token ResumeToken) (Consumer, func() ResumeToken) {
+	result := &sliceFromConsumer{Consumer: c, skip: token.Offset}
+	return result, result.token
+}
+
+type sliceFromConsumer struct {
+	Consumer
+	skip int
+	idx  int
+}
+
+func (s *sliceFromConsumer) children() []interface{} {
+	return []interface{}{s.Consumer}
+}
+
+func (s *sliceFromConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	if s.idx >= s.skip {
+		s.Consumer.Consume(ptr)
+	}
+	s.idx++
+}
+
+func (s *sliceFromConsumer) token() ResumeToken {
+	if s.idx < s.skip {
+		return ResumeToken{Offset: s.skip}
+	}
+	return ResumeToken{Offset: s.idx}
+}
@@ -0,0 +1,45 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceFrom(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	consumer, token := consume.SliceFrom(
+		consume.Slice(consume.AppendTo(&ints), 0, 3), consume.ResumeToken{})
+	assert.Equal(consume.ResumeToken{}, token())
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 1, 2}, ints)
+	encoded := token().String()
+
+	// Resume in a later run.
+	resumeFrom, err := consume.ParseResumeToken(encoded)
+	assert.NoError(err)
+	assert.Equal(consume.ResumeToken{Offset: 3}, resumeFrom)
+	ints = nil
+	consumer, token = consume.SliceFrom(
+		consume.Slice(consume.AppendTo(&ints), 0, 4), resumeFrom)
+	consumer.Consume(new(int))
+	assert.Equal(consume.ResumeToken{Offset: 3}, token())
+	for i := 1; consumer.CanConsume(); i++ {
+		consumer.Consume(&i)
+	}
+	assert.Equal([]int{3, 4, 5, 6}, ints)
+	assert.Equal(consume.ResumeToken{Offset: 7}, token())
+}
+
+func TestParseResumeToken(t *testing.T) {
+	assert := assert.New(t)
+	token, err := consume.ParseResumeToken("")
+	assert.NoError(err)
+	assert.Equal(consume.ResumeToken{}, token)
+	_, err = consume.ParseResumeToken("abc")
+	assert.Error(err)
+	_, err = consume.ParseResumeToken("-3")
+	assert.Error(err)
+}
@@ -0,0 +1,104 @@
+package consume
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how Retry retries failed values.
+type RetryPolicy struct {
+
+	// MaxAttempts is the most times to try consuming a value including
+	// the first try. Zero or less means 1.
+	MaxAttempts int
+
+	// InitialBackoff is how long to wait before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps how long to wait before any retry. Zero means no
+	// cap.
+	MaxBackoff time.Duration
+
+	// Multiplier is what the wait gets multiplied by after each retry.
+	// Values less than 1 mean 2.
+	Multiplier float64
+
+	// Jitter is the fraction by which each wait is randomly increased or
+	// decreased so that many clients don't retry in lock step. 0 means
+	// no jitter; 0.2 means each wait varies by up to 20%.
+	Jitter float64
+
+	// Retryable returns true if err is transient and worth retrying.
+	// nil means all errors are worth retrying.
+	Retryable func(err error) bool
+
+	// DeadLetter, if non-nil, receives each value that could not be
+	// consumed as a *DeadLetterValue. When a value goes to DeadLetter,
+	// Consume returns nil since the value has been dealt with.
+	DeadLetter Consumer
+}
+
+// Retry returns an ErrConsumer that passes the values it consumes onto c
+// retrying each value that fails according to policy. Retry waits
+// between attempts with exponential backoff. The Consume method of the
+// returned consumer returns the error from the last attempt if all
+// attempts fail. Retry stops retrying early if an error is not retryable
+// or if c can no longer consume. The CanConsume method of the returned
+// consumer returns the same as c.CanConsume().
+func Retry(c ErrConsumer, policy RetryPolicy) ErrConsumer {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	return &retryConsumer{ErrConsumer: c, policy: policy, sleep: time.Sleep}
+}
+
+type retryConsumer struct {
+	ErrConsumer
+	policy RetryPolicy
+	sleep  func(d time.Duration)
+}
+
+func (r *retryConsumer) children() []interface{} {
+	return []interface{}{r.ErrConsumer, r.policy.DeadLetter}
+}
+
+func (r *retryConsumer) Consume(ptr interface{}) error {
+	mustCanConsumeE(r)
+	backoff := r.policy.InitialBackoff
+	err := r.ErrConsumer.Consume(ptr)
+	for attempt := 1; err != nil && r.shouldRetry(attempt, err); attempt++ {
+		r.sleep(r.jitter(backoff))
+		backoff = r.nextBackoff(backoff)
+		err = r.ErrConsumer.Consume(ptr)
+	}
+	if err != nil && r.policy.DeadLetter != nil {
+		return deadLetter(r.policy.DeadLetter, ptr, err)
+	}
+	return err
+}
+
+func (r *retryConsumer) shouldRetry(attempt int, err error) bool {
+	if attempt >= r.policy.MaxAttempts || !r.ErrConsumer.CanConsume() {
+		return false
+	}
+	return r.policy.Retryable == nil || r.policy.Retryable(err)
+}
+
+func (r *retryConsumer) jitter(backoff time.Duration) time.Duration {
+	if r.policy.Jitter <= 0 {
+		return backoff
+	}
+	factor := 1 + r.policy.Jitter*(2*rand.Float64()-1)
+	return time.Duration(float64(backoff) * factor)
+}
+
+func (r *retryConsumer) nextBackoff(backoff time.Duration) time.Duration {
+	result := time.Duration(float64(backoff) * r.policy.Multiplier)
+	if r.policy.MaxBackoff > 0 && result > r.policy.MaxBackoff {
+		return r.policy.MaxBackoff
+	}
+	return result
+}
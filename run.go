@@ -0,0 +1,124 @@
+package consume
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PanicError is the error Run reports when it recovers from a panic.
+type PanicError struct {
+
+	// Value is the value recovered from the panic.
+	Value interface{}
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("consume: recovered from panic: %v", p.Value)
+}
+
+// RunReport reports on a call to Run.
+type RunReport struct {
+
+	// Produced is the number of values taken from the producer.
+	Produced int
+
+	// Consumed is the number of values the consumer finished consuming.
+	Consumed int
+
+	// Duration is how long Run took including finalizing.
+	Duration time.Duration
+
+	// Err is the error that stopped Run early, the error from a recovered
+	// panic, or the error the consumer reports through Health. Err is nil
+	// if all went well.
+	Err error
+}
+
+// RunOption is an option for Run.
+type RunOption func(r *runner)
+
+// RunRecoverPanics makes Run recover from panics in the consumer or the
+// producer. Run still finalizes the consumer and reports the panic as a
+// *PanicError. By default, Run finalizes the consumer and lets the panic
+// continue.
+func RunRecoverPanics() RunOption {
+	return func(r *runner) {
+		r.recoverPanics = true
+	}
+}
+
+// RunContext makes Run stop early once ctx is done. Run still finalizes
+// the consumer and reports ctx.Err().
+func RunContext(ctx context.Context) RunOption {
+	return func(r *runner) {
+		r.ctx = ctx
+	}
+}
+
+// Run has c consume the values from p until p runs out of values or c
+// can no longer consume. Run never calls Consume on c when CanConsume
+// returns false, and it never takes a value from p that c can't consume.
+// When done, Run finalizes c. If c is not a ConsumeFinalizer, Run
+// finalizes the ConsumeFinalizers that the consumers of this package
+// such as MapFilter or Compose pass values onto. Run finalizes c even if
+// it stops early or panics. Finally, Run returns a report of what
+// happened.
+func Run(p Producer, c Consumer, options ...RunOption) RunReport {
+	r := &runner{ctx: context.Background(), clock: systemClock{}}
+	for _, option := range options {
+		option(r)
+	}
+	var report RunReport
+	start := r.clock.Now()
+	report.Err = r.run(p, c, &report)
+	report.Duration = r.clock.Now().Sub(start)
+	return report
+}
+
+type runner struct {
+	ctx           context.Context
+	recoverPanics bool
+	clock         Clock
+}
+
+func (r *runner) run(p Producer, c Consumer, report *RunReport) (err error) {
+	if r.recoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &PanicError{Value: v}
+			}
+		}()
+	}
+	defer func() {
+		finalizeAll(c)
+		if err == nil {
+			err = CheckHealth(c)
+		}
+	}()
+	for c.CanConsume() {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		ptr := p.Produce()
+		if ptr == nil {
+			return nil
+		}
+		report.Produced++
+		c.Consume(ptr)
+		report.Consumed++
+	}
+	return nil
+}
+
+// finalizeAll finalizes stage if it has a Finalize method. Otherwise it
+// finalizes the consumers and ErrConsumers that stage passes values onto.
+func finalizeAll(stage interface{}) {
+	if f, ok := stage.(interface{ Finalize() }); ok {
+		f.Finalize()
+		return
+	}
+	for _, child := range childStages(stage) {
+		finalizeAll(child)
+	}
+}
@@ -0,0 +1,138 @@
+package consume_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	report := consume.Run(countingProducer(5), consume.AppendTo(&result))
+	assert.Equal([]int{0, 1, 2, 3, 4}, result)
+	assert.Equal(5, report.Produced)
+	assert.Equal(5, report.Consumed)
+	assert.NoError(report.Err)
+}
+
+func TestRunStopsWhenConsumerFull(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	producer := countingProducer(10)
+	report := consume.Run(
+		producer, consume.Slice(consume.AppendTo(&result), 0, 3))
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.Equal(3, report.Produced)
+	assert.Equal(3, report.Consumed)
+
+	// Run must not take a value the consumer can't consume.
+	assert.Equal(3, *producer.Produce().(*int))
+}
+
+func TestRunFinalizesNested(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	finalizeCount := 0
+	cf := &finalizer{
+		Consumer: consume.AppendTo(&result),
+		finalize: func() { finalizeCount++ },
+	}
+	consumer := consume.Compose(
+		consume.MapFilter(cf, func(ptr *int) bool { return *ptr%2 == 0 }),
+		consume.Slice(consume.Nil(), 0, 2))
+	report := consume.Run(countingProducer(6), consumer)
+	assert.Equal([]int{0, 2, 4}, result)
+	assert.Equal(1, finalizeCount)
+	assert.Equal(6, report.Consumed)
+}
+
+func TestRunFinalizesThroughWrappers(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	encode := func(ptr interface{}) []byte {
+		return []byte(strconv.Itoa(*ptr.(*int)))
+	}
+	report := consume.Run(
+		countingProducer(3),
+		consume.Checksum(
+			consume.AppendToSaveMemory(&ints), sha256.New(), encode))
+	assert.NoError(report.Err)
+	assert.Equal([]int{0, 1, 2}, ints)
+	var err error
+	yaml := consume.ToYAML(errorWriter{})
+	report = consume.Run(
+		countingProducer(3),
+		consume.FromErrConsumer(consume.ToErrConsumer(yaml), &err))
+	assert.Error(report.Err)
+}
+
+func TestRunPanics(t *testing.T) {
+	assert := assert.New(t)
+	finalized := false
+	consumer := &finalizer{
+		Consumer: consume.ConsumerFunc(func(ptr interface{}) {
+			panic("oops")
+		}),
+		finalize: func() { finalized = true },
+	}
+	assert.Panics(func() {
+		consume.Run(countingProducer(3), consumer)
+	})
+	assert.True(finalized)
+}
+
+func TestRunRecoverPanics(t *testing.T) {
+	assert := assert.New(t)
+	finalized := false
+	consumer := &finalizer{
+		Consumer: consume.ConsumerFunc(func(ptr interface{}) {
+			if *ptr.(*int) == 2 {
+				panic("oops")
+			}
+		}),
+		finalize: func() { finalized = true },
+	}
+	report := consume.Run(
+		countingProducer(5), consumer, consume.RunRecoverPanics())
+	assert.True(finalized)
+	assert.Equal(3, report.Produced)
+	assert.Equal(2, report.Consumed)
+	var panicErr *consume.PanicError
+	assert.True(errors.As(report.Err, &panicErr))
+	assert.Equal("oops", panicErr.Value)
+}
+
+func TestRunContext(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var result []int
+	consumer := consume.ConsumerFunc(func(ptr interface{}) {
+		result = append(result, *ptr.(*int))
+		if len(result) == 2 {
+			cancel()
+		}
+	})
+	report := consume.Run(
+		countingProducer(5), consumer, consume.RunContext(ctx))
+	assert.Equal([]int{0, 1}, result)
+	assert.Equal(context.Canceled, report.Err)
+}
+
+func TestRunReportsHealth(t *testing.T) {
+	assert := assert.New(t)
+	report := consume.Run(countingProducer(2), consume.ToYAML(errorWriter{}))
+	assert.Error(report.Err)
+	report = consume.Run(
+		countingProducer(2),
+		consume.MapFilter(
+			consume.ToYAML(errorWriter{}),
+			func(ptr *int) bool { return true }))
+	assert.Error(report.Err)
+}
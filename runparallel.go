@@ -0,0 +1,100 @@
+package consume
+
+import (
+	"sync"
+)
+
+// RunParallel feeds the values from source to workers pipelines running
+// on separate goroutines. RunParallel calls build once per worker to
+// create each pipeline, so pipelines share nothing unless build makes
+// them. Idle workers take the next value as soon as they are ready, so
+// faster workers process more values. RunParallel passes each pipeline
+// a shallow copy of each value since source may reuse the value it
+// returns. A worker stops taking values once its pipeline can no longer
+// consume. After source runs out of values or all the pipelines are
+// full, RunParallel finalizes each pipeline the same way Run does and
+// returns. If a pipeline panics, RunParallel still finalizes it, stops
+// feeding values, and returns a *PanicError after the other workers
+// finish. If build or source panics, RunParallel closes off the workers
+// already started and waits for them to finalize their pipelines before
+// passing the panic on. RunParallel panics if workers is not positive.
+func RunParallel(
+	source Producer, build func() Consumer, workers int) error {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	r := &parallelRun{
+		input: make(chan interface{}, workers),
+		stop:  make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	inputClosed := false
+	defer func() {
+		// If build or source panics, let the workers already started
+		// finish before the panic propagates.
+		if !inputClosed {
+			close(r.input)
+			wg.Wait()
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		c := build()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(c)
+		}()
+	}
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+feed:
+	for ptr := source.Produce(); ptr != nil; ptr = source.Produce() {
+		select {
+		case r.input <- shallowCopy(ptr):
+		case <-allDone:
+			break feed
+		case <-r.stop:
+			break feed
+		}
+	}
+	close(r.input)
+	inputClosed = true
+	<-allDone
+	return r.err
+}
+
+type parallelRun struct {
+	input    chan interface{}
+	stop     chan struct{}
+	mu       sync.Mutex
+	err      error
+	stopOnce sync.Once
+}
+
+func (r *parallelRun) work(c Consumer) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			r.fail(&PanicError{Value: recovered})
+		}
+	}()
+	defer finalizeAll(c)
+	for c.CanConsume() {
+		ptr, ok := <-r.input
+		if !ok {
+			break
+		}
+		c.Consume(ptr)
+	}
+}
+
+func (r *parallelRun) fail(err error) {
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+	r.stopOnce.Do(func() { close(r.stop) })
+}
@@ -0,0 +1,146 @@
+package consume_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunParallel(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	var all []int
+	finalized := 0
+	err := consume.RunParallel(
+		countingProducer(100),
+		func() consume.Consumer {
+			var evens []int
+			return &finalizer{
+				Consumer: consume.MapFilter(
+					consume.AppendTo(&evens),
+					func(ptr *int) bool { return (*ptr)%2 == 0 }),
+				finalize: func() {
+					mu.Lock()
+					defer mu.Unlock()
+					all = append(all, evens...)
+					finalized++
+				},
+			}
+		},
+		4)
+	assert.NoError(err)
+	sort.Ints(all)
+	assert.Len(all, 50)
+	assert.Equal(98, all[49])
+	assert.Equal(4, finalized)
+}
+
+func TestRunParallelFinalizes(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	total := 0
+	finalized := 0
+	err := consume.RunParallel(
+		countingProducer(1000),
+		func() consume.Consumer {
+			count := 0
+			return &finalizer{
+				Consumer: consume.Slice(
+					consume.ConsumerFunc(func(ptr interface{}) { count++ }),
+					0,
+					10),
+				finalize: func() {
+					mu.Lock()
+					defer mu.Unlock()
+					total += count
+					finalized++
+				},
+			}
+		},
+		3)
+	assert.NoError(err)
+	assert.Equal(30, total)
+	assert.Equal(3, finalized)
+}
+
+func TestRunParallelPanic(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	finalized := 0
+	err := consume.RunParallel(
+		countingProducer(1000000),
+		func() consume.Consumer {
+			return &finalizer{
+				Consumer: consume.ConsumerFunc(func(ptr interface{}) {
+					if *ptr.(*int) == 50 {
+						panic("bad value")
+					}
+				}),
+				finalize: func() {
+					mu.Lock()
+					defer mu.Unlock()
+					finalized++
+				},
+			}
+		},
+		2)
+	assert.Equal(&consume.PanicError{Value: "bad value"}, err)
+	assert.Equal(2, finalized)
+	assert.Panics(func() {
+		consume.RunParallel(countingProducer(1), nil, 0)
+	})
+}
+
+// countingProducer produces 0, 1, 2, ... n-1 reusing the same pointer.
+func countingProducer(n int) consume.Producer {
+	var i int
+	next := 0
+	return consume.ProducerFunc(func() interface{} {
+		if next == n {
+			return nil
+		}
+		i = next
+		next++
+		return &i
+	})
+}
+
+type finalizer struct {
+	consume.Consumer
+	finalize func()
+}
+
+func (f *finalizer) Finalize() {
+	f.finalize()
+}
+
+func TestRunParallelBuildPanics(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	built := 0
+	finalized := 0
+	recovered := recoverFrom(func() {
+		consume.RunParallel(
+			countingProducer(100),
+			func() consume.Consumer {
+				if built == 2 {
+					panic("oops")
+				}
+				built++
+				return &finalizer{
+					Consumer: consume.Nil(),
+					finalize: func() {
+						mu.Lock()
+						defer mu.Unlock()
+						finalized++
+					},
+				}
+			},
+			4)
+	})
+	assert.Equal("oops", recovered)
+	assert.Equal(2, finalized)
+}
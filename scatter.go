@@ -0,0 +1,145 @@
+package consume
+
+// ScatterWeighted returns a Consumer that sends each value it consumes to
+// exactly one of consumers in proportion to weights. weights[i] is the
+// weight of consumers[i]. For example, with weights 3 and 1, the first
+// consumer gets 3 values for every value the second consumer gets.
+// ScatterWeighted uses smooth weighted round-robin so that values to the
+// same consumer are spread out rather than sent in runs. Consumers that
+// can no longer consume are skipped, and the remaining consumers share
+// their values in proportion to their weights. The CanConsume method of
+// returned consumer returns false when no consumer can consume.
+// ScatterWeighted panics if weights and consumers differ in length or if
+// any weight is not positive.
+func ScatterWeighted(weights []int, consumers ...Consumer) Consumer {
+	if len(weights) != len(consumers) {
+		panic("weights and consumers must have the same length")
+	}
+	for _, w := range weights {
+		if w <= 0 {
+			panic("weights must be positive")
+		}
+	}
+	weightsCopy := make([]int, len(weights))
+	copy(weightsCopy, weights)
+	consumersCopy := make([]Consumer, len(consumers))
+	copy(consumersCopy, consumers)
+	return &scatterWeightedConsumer{
+		weights:   weightsCopy,
+		consumers: consumersCopy,
+		current:   make([]int, len(weights)),
+	}
+}
+
+type scatterWeightedConsumer struct {
+	weights   []int
+	consumers []Consumer
+	current   []int
+}
+
+func (s *scatterWeightedConsumer) children() []interface{} {
+	return asStages(s.consumers)
+}
+
+func (s *scatterWeightedConsumer) CanConsume() bool {
+	for _, c := range s.consumers {
+		if c.CanConsume() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *scatterWeightedConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	total := 0
+	best := -1
+	for i, c := range s.consumers {
+		if !c.CanConsume() {
+			continue
+		}
+		total += s.weights[i]
+		s.current[i] += s.weights[i]
+		if best == -1 || s.current[i] > s.current[best] {
+			best = i
+		}
+	}
+	s.current[best] -= total
+	s.consumers[best].Consume(ptr)
+}
+
+// PartitionByKeyRebalancing returns a Consumer that sends each value it
+// consumes to one of consumers chosen by the value's key so that values
+// with the same key go to the same consumer. keyFunc extracts the key
+// from a value. It takes a pointer to the value and returns the key which
+// must support equality. keyFunc may also be a
+// func(ptr interface{}) interface{}. The first time the returned consumer
+// sees a key, it assigns the key to the consumer that can still consume
+// and has the fewest keys. When a consumer can no longer consume, its
+// keys get reassigned the same way as they come up again. Values for a
+// key reach their consumer in order, and only a reassignment moves a key
+// to a different consumer. The returned consumer remembers every key it
+// sees. Its CanConsume method returns false when no consumer can
+// consume. PartitionByKeyRebalancing panics if keyFunc returns keys that
+// don't support equality.
+func PartitionByKeyRebalancing(
+	keyFunc interface{}, consumers ...Consumer) Consumer {
+	consumersCopy := make([]Consumer, len(consumers))
+	copy(consumersCopy, consumers)
+	return &rebalancingPartitionConsumer{
+		keyFunc:     newMapKeyFunc(keyFunc),
+		consumers:   consumersCopy,
+		assignments: make(map[interface{}]int),
+		keyCounts:   make([]int, len(consumers)),
+	}
+}
+
+type rebalancingPartitionConsumer struct {
+	keyFunc     keyFunc
+	consumers   []Consumer
+	assignments map[interface{}]int
+	keyCounts   []int
+}
+
+func (r *rebalancingPartitionConsumer) children() []interface{} {
+	return asStages(r.consumers)
+}
+
+func (r *rebalancingPartitionConsumer) CanConsume() bool {
+	for _, c := range r.consumers {
+		if c.CanConsume() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *rebalancingPartitionConsumer) Consume(ptr interface{}) {
+	MustCanConsume(r)
+	key := r.keyFunc.key(ptr)
+	idx, ok := r.assignments[key]
+	if !ok || !r.consumers[idx].CanConsume() {
+		if ok {
+			r.keyCounts[idx]--
+		}
+		idx = r.leastLoaded()
+		r.assignments[key] = idx
+		r.keyCounts[idx]++
+	}
+	r.consumers[idx].Consume(ptr)
+}
+
+// leastLoaded returns the index of the consumer that can still consume
+// with the fewest keys.
+func (r *rebalancingPartitionConsumer) leastLoaded() int {
+	result := -1
+	for i, c := range r.consumers {
+		if !c.CanConsume() {
+			continue
+		}
+		if result == -1 || r.keyCounts[i] < r.keyCounts[result] {
+			result = i
+		}
+	}
+	return result
+}
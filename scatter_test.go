@@ -0,0 +1,69 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScatterWeighted(t *testing.T) {
+	assert := assert.New(t)
+	var heavy, light []int
+	consumer := consume.ScatterWeighted(
+		[]int{2, 1}, consume.AppendTo(&heavy), consume.AppendTo(&light))
+	consume.FeedRange(0, 9, 1, consumer)
+	assert.Equal([]int{0, 2, 3, 5, 6, 8}, heavy)
+	assert.Equal([]int{1, 4, 7}, light)
+}
+
+func TestScatterWeightedSkipsExhausted(t *testing.T) {
+	assert := assert.New(t)
+	var first, second []int
+	consumer := consume.ScatterWeighted(
+		[]int{1, 1},
+		consume.Slice(consume.AppendTo(&first), 0, 2),
+		consume.Slice(consume.AppendTo(&second), 0, 4))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 2}, first)
+	assert.Equal([]int{1, 3, 4, 5}, second)
+}
+
+func TestScatterWeightedPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() {
+		consume.ScatterWeighted([]int{1}, consume.Nil(), consume.Nil())
+	})
+	assert.Panics(func() {
+		consume.ScatterWeighted([]int{0}, consume.Nil())
+	})
+	assert.False(consume.ScatterWeighted(nil).CanConsume())
+}
+
+func TestPartitionByKeyRebalancing(t *testing.T) {
+	assert := assert.New(t)
+	var first, second, third []int
+	consumer := consume.PartitionByKeyRebalancing(
+		func(ptr *int) int { return *ptr % 3 },
+		consume.AppendTo(&first),
+		consume.Slice(consume.AppendTo(&second), 0, 2),
+		consume.AppendTo(&third))
+	consume.FeedRange(0, 12, 1, consumer)
+	assert.Equal([]int{0, 3, 6, 7, 9, 10}, first)
+	assert.Equal([]int{1, 4}, second)
+	assert.Equal([]int{2, 5, 8, 11}, third)
+}
+
+func TestPartitionByKeyRebalancingExhausted(t *testing.T) {
+	assert := assert.New(t)
+	var first, second []int
+	consumer := consume.PartitionByKeyRebalancing(
+		func(ptr interface{}) interface{} { return *ptr.(*int) % 2 },
+		consume.Slice(consume.AppendTo(&first), 0, 2),
+		consume.Slice(consume.AppendTo(&second), 0, 3))
+	feedInts(t, consumer)
+	assert.Equal([]int{0, 2}, first)
+	assert.Equal([]int{1, 3, 4}, second)
+	assert.False(consume.PartitionByKeyRebalancing(
+		func(ptr *int) int { return 0 }).CanConsume())
+}
@@ -0,0 +1,314 @@
+package consume
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// SchemaField describes one field of a Schema.
+type SchemaField struct {
+
+	// Name is the name of the field. Sinks use it as the column header.
+	// Name also selects the struct field that holds the value: either the
+	// struct field with a matching `consume:"name"` tag or, for untagged
+	// fields, the struct field with the same Go name.
+	Name string
+
+	// Type is the Go type of the field. ConformsTo rejects values whose
+	// field can't be assigned to Type. A nil Type accepts any type.
+	Type reflect.Type
+
+	// Required means that ConformsTo rejects values that lack this field
+	// or whose field is a nil pointer, interface, slice, or map.
+	Required bool
+
+	// Min and Max, if non-nil, are the inclusive range that ConformsTo
+	// allows for numeric fields.
+	Min, Max *float64
+}
+
+// Schema describes the columns that encoding sinks such as ToCSV and
+// ToTableSchema write for struct values. Callers can build a Schema by
+// hand to pick and order columns or use InferSchema.
+type Schema struct {
+	Fields []SchemaField
+}
+
+// InferSchema returns the Schema for the struct aStructPointer points to.
+// The schema has one field for each exported field of the struct in
+// declaration order. A `consume:"name"` tag on a struct field changes
+// the name of its schema field; a `consume:"-"` tag leaves the struct
+// field out. InferSchema panics if aStructPointer is not a pointer to a
+// struct.
+func InferSchema(aStructPointer interface{}) *Schema {
+	structType := structTypeFromP(aStructPointer)
+	var fields []SchemaField
+	for i := 0; i < structType.NumField(); i++ {
+		name, ok := schemaName(structType.Field(i))
+		if !ok {
+			continue
+		}
+		fields = append(
+			fields,
+			SchemaField{Name: name, Type: structType.Field(i).Type})
+	}
+	return &Schema{Fields: fields}
+}
+
+// Headers returns the names of the fields of s in order.
+func (s *Schema) Headers() []string {
+	result := make([]string, len(s.Fields))
+	for i := range s.Fields {
+		result[i] = s.Fields[i].Name
+	}
+	return result
+}
+
+// Columns returns the columns of s for use with ToTable. The value of
+// each column is the struct field formatted with fmt.Sprint or the empty
+// string if the struct has no such field.
+func (s *Schema) Columns() []Column {
+	result := make([]Column, len(s.Fields))
+	for i := range s.Fields {
+		locator := &fieldLocator{name: s.Fields[i].Name}
+		result[i] = Column{
+			Header: s.Fields[i].Name,
+			Value:  locator.format,
+		}
+	}
+	return result
+}
+
+// ToTableSchema works like ToTable except that its columns come from
+// schema. If schema is nil, ToTableSchema infers the schema from the
+// first consumed value using InferSchema. In that case, if caller
+// consumes no values, Finalize writes nothing to w.
+func ToTableSchema(w io.Writer, schema *Schema) ConsumeFinalizer {
+	return newSchemaConsumer(schema, func(s *Schema) ConsumeFinalizer {
+		return ToTable(w, s.Columns())
+	})
+}
+
+// ToCSV returns a ConsumeFinalizer that writes consumed struct values to
+// w as CSV. The first record contains the headers of schema. Each
+// consumed value becomes one record with each field formatted using
+// fmt.Sprint. If schema is nil, ToCSV infers the schema from the first
+// consumed value using InferSchema. In that case, if caller consumes no
+// values, ToCSV writes nothing. Caller must call Finalize() on the
+// returned consumer when done so that any buffered output gets written
+// to w. If a write to w fails, CanConsume() returns false from then on,
+// and the Healthy method of the returned consumer reports the error.
+func ToCSV(w io.Writer, schema *Schema) ConsumeFinalizer {
+	return newSchemaConsumer(schema, func(s *Schema) ConsumeFinalizer {
+		return newCSVConsumer(w, s)
+	})
+}
+
+type csvConsumer struct {
+	writer    *csv.Writer
+	columns   []Column
+	record    []string
+	err       error
+	finalized bool
+}
+
+func newCSVConsumer(w io.Writer, schema *Schema) *csvConsumer {
+	result := &csvConsumer{
+		writer:  csv.NewWriter(w),
+		columns: schema.Columns(),
+	}
+	result.record = make([]string, len(result.columns))
+	result.write(schema.Headers())
+	return result
+}
+
+func (c *csvConsumer) CanConsume() bool {
+	return c.err == nil && !c.finalized
+}
+
+func (c *csvConsumer) Consume(ptr interface{}) {
+	MustCanConsume(c)
+	for i := range c.columns {
+		c.record[i] = c.columns[i].Value(ptr)
+	}
+	c.write(c.record)
+}
+
+func (c *csvConsumer) Finalize() {
+	if c.finalized {
+		return
+	}
+	c.finalized = true
+	c.writer.Flush()
+}
+
+func (c *csvConsumer) write(record []string) {
+	c.err = c.writer.Write(record)
+}
+
+func (c *csvConsumer) Healthy() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.writer.Error()
+}
+
+// schemaConsumer builds its underlying sink from its schema. If it has
+// no schema, it infers one from the first value it consumes.
+type schemaConsumer struct {
+	build     func(schema *Schema) ConsumeFinalizer
+	sink      ConsumeFinalizer
+	finalized bool
+}
+
+func (s *schemaConsumer) children() []interface{} {
+	return []interface{}{s.sink}
+}
+
+func newSchemaConsumer(
+	schema *Schema,
+	build func(schema *Schema) ConsumeFinalizer) *schemaConsumer {
+	result := &schemaConsumer{build: build}
+	if schema != nil {
+		result.sink = build(schema)
+	}
+	return result
+}
+
+func (s *schemaConsumer) CanConsume() bool {
+	if s.finalized {
+		return false
+	}
+	return s.sink == nil || s.sink.CanConsume()
+}
+
+func (s *schemaConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	if s.sink == nil {
+		s.sink = s.build(InferSchema(ptr))
+	}
+	s.sink.Consume(ptr)
+}
+
+func (s *schemaConsumer) Finalize() {
+	if s.finalized {
+		return
+	}
+	s.finalized = true
+	if s.sink != nil {
+		s.sink.Finalize()
+	}
+}
+
+func (s *schemaConsumer) Healthy() error {
+	if s.sink == nil {
+		return nil
+	}
+	return CheckHealth(s.sink)
+}
+
+// fieldLocator finds the struct field with a given schema name. It
+// remembers where the field was in the last struct type it saw.
+type fieldLocator struct {
+	name     string
+	lastType reflect.Type
+	index    int
+}
+
+// field returns the field that ptr points to or false if there is no
+// such field.
+func (f *fieldLocator) field(ptr interface{}) (reflect.Value, bool) {
+	value := reflect.ValueOf(ptr).Elem()
+	if value.Type() != f.lastType {
+		f.lastType = value.Type()
+		f.index = schemaFieldIndex(f.lastType, f.name)
+	}
+	if f.index < 0 {
+		return reflect.Value{}, false
+	}
+	return value.Field(f.index), true
+}
+
+func (f *fieldLocator) format(ptr interface{}) string {
+	field, ok := f.field(ptr)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+// schemaFieldIndex returns the index of the struct field in structType
+// with given schema name or -1 if there is no such field or if
+// structType is not a struct.
+func schemaFieldIndex(structType reflect.Type, name string) int {
+	if structType.Kind() != reflect.Struct {
+		return -1
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		fieldName, ok := schemaName(structType.Field(i))
+		if ok && fieldName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// schemaName returns the schema name of field or false if field does not
+// belong in a schema.
+func schemaName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	tag := field.Tag.Get("consume")
+	if tag == "-" {
+		return "", false
+	}
+	if tag != "" {
+		return tag, true
+	}
+	return field.Name, true
+}
+
+func structTypeFromP(aStructPointer interface{}) reflect.Type {
+	ptrType := reflect.TypeOf(aStructPointer)
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr ||
+		ptrType.Elem().Kind() != reflect.Struct {
+		panic("aStructPointer must be a pointer to a struct")
+	}
+	return ptrType.Elem()
+}
+
+// indirectValue follows pointers and interfaces from value. It returns
+// the invalid value if value is invalid or if it reaches a nil.
+func indirectValue(value reflect.Value) reflect.Value {
+	for value.IsValid() &&
+		(value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// floatValue returns the number value holds as a float64 or false if
+// value does not hold a number.
+func floatValue(value reflect.Value) (float64, bool) {
+	value = indirectValue(value)
+	if !value.IsValid() {
+		return 0, false
+	}
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return float64(value.Uint()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,89 @@
+package consume_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+type account struct {
+	ID      int    `consume:"id"`
+	Owner   string `consume:"owner"`
+	Balance float64
+	Secret  string `consume:"-"`
+	note    string
+}
+
+var accounts = []account{
+	{ID: 1, Owner: "Mark", Balance: 12.5, Secret: "x", note: "a"},
+	{ID: 2, Owner: "Beth", Balance: 3, Secret: "y", note: "b"},
+}
+
+func TestInferSchema(t *testing.T) {
+	assert := assert.New(t)
+	schema := consume.InferSchema(&account{})
+	assert.Equal([]string{"id", "owner", "Balance"}, schema.Headers())
+	assert.Equal(reflect.TypeOf(0), schema.Fields[0].Type)
+	assert.Equal(reflect.TypeOf(""), schema.Fields[1].Type)
+	assert.Equal(reflect.TypeOf(0.0), schema.Fields[2].Type)
+	assert.Panics(func() { consume.InferSchema(account{}) })
+	assert.Panics(func() { consume.InferSchema(new(int)) })
+}
+
+func TestToCSV(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToCSV(&sb, nil)
+	consume.FeedSlice(accounts, cf)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	expected := `id,owner,Balance
+1,Mark,12.5
+2,Beth,3
+`
+	assert.Equal(expected, sb.String())
+}
+
+func TestToCSVExplicitSchema(t *testing.T) {
+	assert := assert.New(t)
+	schema := &consume.Schema{
+		Fields: []consume.SchemaField{
+			{Name: "owner"}, {Name: "missing"}, {Name: "id"},
+		},
+	}
+	var sb strings.Builder
+	cf := consume.ToCSV(&sb, schema)
+	cf.Finalize()
+	assert.Equal("owner,missing,id\n", sb.String())
+
+	sb.Reset()
+	cf = consume.ToCSV(&sb, schema)
+	consume.FeedSlice(accounts[:1], cf)
+	cf.Finalize()
+	assert.Equal("owner,missing,id\nMark,,1\n", sb.String())
+}
+
+func TestToCSVInferredEmpty(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToCSV(&sb, nil)
+	cf.Finalize()
+	assert.Empty(sb.String())
+}
+
+func TestToTableSchema(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToTableSchema(&sb, nil)
+	consume.FeedSlice(people[:2], cf)
+	cf.Finalize()
+	expected := `Name    Age
+Mark    50
+Stoney  49
+`
+	assert.Equal(expected, sb.String())
+}
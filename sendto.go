@@ -0,0 +1,58 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// SendOption is an option for SendTo.
+type SendOption func(s *sendConsumer)
+
+// SendNonBlocking makes the consumer that SendTo returns stop consuming
+// instead of blocking when the channel is full. The value that did not
+// fit is dropped, and CanConsume returns false from then on.
+func SendNonBlocking() SendOption {
+	return func(s *sendConsumer) {
+		s.nonBlocking = true
+	}
+}
+
+// SendTo returns a Consumer that sends a copy of each value it consumes
+// on ch. ch is a channel of values, not a pointer to one, and may be
+// send only. Since the values are copied, the receiver may hold onto
+// them. The copies are shallow. By default, Consume blocks until the
+// value is sent, and the returned consumer can always consume. SendTo
+// never closes ch. SendTo panics if ch is not a channel that can send.
+func SendTo(ch interface{}, options ...SendOption) Consumer {
+	chValue := reflect.ValueOf(ch)
+	if chValue.Kind() != reflect.Chan ||
+		chValue.Type().ChanDir()&reflect.SendDir == 0 {
+		panic("a sendable channel is expected.")
+	}
+	result := &sendConsumer{ch: chValue}
+	for _, option := range options {
+		option(result)
+	}
+	return result
+}
+
+type sendConsumer struct {
+	ch          reflect.Value
+	nonBlocking bool
+	full        bool
+}
+
+func (s *sendConsumer) CanConsume() bool {
+	return !s.full
+}
+
+func (s *sendConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	value := reflect.ValueOf(ptr).Elem()
+	if !s.nonBlocking {
+		s.ch.Send(value)
+		return
+	}
+	if !s.ch.TrySend(value) {
+		s.full = true
+	}
+}
@@ -0,0 +1,35 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendTo(t *testing.T) {
+	assert := assert.New(t)
+	ch := make(chan person)
+	go func() {
+		consume.FeedSlice(people, consume.SendTo(ch))
+		close(ch)
+	}()
+	var result []person
+	consume.ConsumeFromChannel(ch, consume.AppendTo(&result))
+	assert.Equal(people, result)
+}
+
+func TestSendToNonBlocking(t *testing.T) {
+	assert := assert.New(t)
+	ch := make(chan int, 3)
+	var sendOnly chan<- int = ch
+	consumer := consume.SendTo(sendOnly, consume.SendNonBlocking())
+	consume.FeedRange(0, 10, 1, consumer)
+	assert.False(consumer.CanConsume())
+	close(ch)
+	var result []int
+	consume.ConsumeFromChannel(ch, consume.AppendTo(&result))
+	assert.Equal([]int{0, 1, 2}, result)
+	assert.Panics(func() { consume.SendTo(people) })
+	assert.Panics(func() { consume.SendTo(make(<-chan int)) })
+}
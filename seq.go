@@ -0,0 +1,49 @@
+//go:build go1.23
+// +build go1.23
+
+package consume
+
+import (
+	"iter"
+)
+
+// FromSeq has consumer consume each value that seq yields. FromSeq stops
+// early if consumer can no longer consume.
+func FromSeq[T any](seq iter.Seq[T], consumer Consumer) {
+	if !consumer.CanConsume() {
+		return
+	}
+	for value := range seq {
+		consumer.Consume(&value)
+		if !consumer.CanConsume() {
+			return
+		}
+	}
+}
+
+// ToSeq returns an iter.Seq that yields the values that feed has a
+// consumer consume. Each time the returned iter.Seq is ranged over, it
+// calls feed with a new consumer. That consumer yields the *T values it
+// consumes and stops consuming when the range loop exits early. Since
+// feed may reuse the values it feeds, the yielded values are only valid
+// until the next iteration. ToSeq lets a pipeline built with MapFilter
+// and friends be used in a range over func loop.
+func ToSeq[T any](feed func(consumer Consumer)) iter.Seq[*T] {
+	return func(yield func(*T) bool) {
+		feed(&yieldConsumer[T]{yield: yield})
+	}
+}
+
+type yieldConsumer[T any] struct {
+	yield func(*T) bool
+	done  bool
+}
+
+func (y *yieldConsumer[T]) CanConsume() bool {
+	return !y.done
+}
+
+func (y *yieldConsumer[T]) Consume(ptr interface{}) {
+	MustCanConsume(y)
+	y.done = !y.yield(ptr.(*T))
+}
@@ -0,0 +1,50 @@
+//go:build go1.23
+// +build go1.23
+
+package consume_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSeq(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consume.FromSeq(
+		slices.Values([]int{1, 2, 3, 4, 5}),
+		consume.Slice(consume.AppendTo(&result), 1, 3))
+	assert.Equal([]int{2, 3}, result)
+	consume.FromSeq(slices.Values([]int{6}), consume.Nil())
+}
+
+func TestToSeq(t *testing.T) {
+	assert := assert.New(t)
+	seq := consume.ToSeq[string](func(consumer consume.Consumer) {
+		consume.FeedSlice(
+			people,
+			consume.MapFilter(
+				consumer,
+				func(src *person, dest *string) bool {
+					*dest = src.Name
+					return src.Age > 40
+				}))
+	})
+	var names []string
+	for name := range seq {
+		names = append(names, *name)
+	}
+	assert.Equal([]string{"Mark", "Stoney", "Matt", "Beth"}, names)
+
+	names = nil
+	for name := range seq {
+		if *name == "Matt" {
+			break
+		}
+		names = append(names, *name)
+	}
+	assert.Equal([]string{"Mark", "Stoney"}, names)
+}
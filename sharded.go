@@ -0,0 +1,35 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// ShardedAppendTo returns shards consumers that each append to their own
+// private shard so that separate goroutines can collect values at the
+// same time without locking. Each consumer must be used by only one
+// goroutine at a time. After all goroutines are done, caller calls
+// finalize to append the shards, in order, to the slice that slicesPtr
+// points to. Like AppendTo, the consumers can always consume.
+// ShardedAppendTo panics if shards is not positive or if slicesPtr is not
+// a pointer to a slice.
+func ShardedAppendTo(
+	slicesPtr interface{}, shards int) (
+	consumers []Consumer, finalize func()) {
+	if shards <= 0 {
+		panic("shards must be positive")
+	}
+	aSliceValue := sliceValueFromP(slicesPtr, false)
+	shardPtrs := make([]reflect.Value, shards)
+	consumers = make([]Consumer, shards)
+	for i := range shardPtrs {
+		shardPtrs[i] = reflect.New(aSliceValue.Type())
+		consumers[i] = AppendTo(shardPtrs[i].Interface())
+	}
+	finalize = func() {
+		for _, shardPtr := range shardPtrs {
+			aSliceValue.Set(reflect.AppendSlice(aSliceValue, shardPtr.Elem()))
+			shardPtr.Elem().Set(reflect.Zero(aSliceValue.Type()))
+		}
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package consume_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedAppendTo(t *testing.T) {
+	assert := assert.New(t)
+	ints := []int{-1}
+	consumers, finalize := consume.ShardedAppendTo(&ints, 3)
+	assert.Len(consumers, 3)
+	var wg sync.WaitGroup
+	for i, consumer := range consumers {
+		wg.Add(1)
+		go func(start int, consumer consume.Consumer) {
+			defer wg.Done()
+			for j := start; j < start+3; j++ {
+				consumer.Consume(&j)
+			}
+		}(10*i, consumer)
+	}
+	wg.Wait()
+	finalize()
+	assert.Equal([]int{-1, 0, 1, 2, 10, 11, 12, 20, 21, 22}, ints)
+}
+
+func TestShardedAppendToPanics(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	assert.Panics(func() { consume.ShardedAppendTo(&ints, 0) })
+	assert.Panics(func() { consume.ShardedAppendTo(ints, 2) })
+}
@@ -0,0 +1,65 @@
+package consume
+
+// SizeReport summarizes the sizes of values.
+type SizeReport struct {
+
+	// Count is the number of values.
+	Count int
+
+	// Min is the smallest size. Min is 0 if Count is 0.
+	Min int
+
+	// Max is the largest size. Max is 0 if Count is 0.
+	Max int
+
+	// Total is the sum of all the sizes.
+	Total int64
+
+	// Avg is the average size. Avg is 0 if Count is 0.
+	Avg float64
+}
+
+// SizeStats returns a ConsumeFinalizer that measures each value it
+// consumes with sizeFunc and summarizes the sizes in out. sizeFunc takes
+// a pointer to a consumed value and returns its size in whatever unit
+// caller chooses such as bytes or number of elements. Note that out is
+// undefined until caller calls Finalize() on the returned consumer.
+func SizeStats(
+	out *SizeReport, sizeFunc func(ptr interface{}) int) ConsumeFinalizer {
+	return &sizeStatsConsumer{out: out, sizeFunc: sizeFunc}
+}
+
+type sizeStatsConsumer struct {
+	out       *SizeReport
+	sizeFunc  func(ptr interface{}) int
+	report    SizeReport
+	finalized bool
+}
+
+func (s *sizeStatsConsumer) CanConsume() bool {
+	return !s.finalized
+}
+
+func (s *sizeStatsConsumer) Consume(ptr interface{}) {
+	MustCanConsume(s)
+	size := s.sizeFunc(ptr)
+	if s.report.Count == 0 || size < s.report.Min {
+		s.report.Min = size
+	}
+	if s.report.Count == 0 || size > s.report.Max {
+		s.report.Max = size
+	}
+	s.report.Count++
+	s.report.Total += int64(size)
+}
+
+func (s *sizeStatsConsumer) Finalize() {
+	if s.finalized {
+		return
+	}
+	s.finalized = true
+	if s.report.Count > 0 {
+		s.report.Avg = float64(s.report.Total) / float64(s.report.Count)
+	}
+	*s.out = s.report
+}
@@ -0,0 +1,33 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeStats(t *testing.T) {
+	assert := assert.New(t)
+	var report consume.SizeReport
+	cf := consume.SizeStats(&report, func(ptr interface{}) int {
+		return len(*ptr.(*string))
+	})
+	for _, s := range []string{"hello", "a", "", "world!"} {
+		cf.Consume(&s)
+	}
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal(
+		consume.SizeReport{Count: 4, Min: 0, Max: 6, Total: 12, Avg: 3.0},
+		report)
+	assert.False(cf.CanConsume())
+}
+
+func TestSizeStatsEmpty(t *testing.T) {
+	assert := assert.New(t)
+	report := consume.SizeReport{Count: 7}
+	cf := consume.SizeStats(&report, func(ptr interface{}) int { return 1 })
+	cf.Finalize()
+	assert.Equal(consume.SizeReport{}, report)
+}
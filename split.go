@@ -0,0 +1,33 @@
+package consume
+
+import (
+	"sort"
+)
+
+// Split returns a Consumer that sends a different projection of each
+// value it consumes to each sink. For each name in extractors, Split
+// applies extractors[name] to each consumed value and passes the result
+// onto sinks[name]. An extractor can be anything that MapFilter accepts
+// such as a function like func(src *Person, dest *int64) bool, a Mapper,
+// a Filterer, or a MapFilterer. Sinks get values in order of their names.
+// The returned consumer can consume as long as any sink can. Split panics
+// if extractors and sinks don't have the same names.
+func Split(
+	extractors map[string]interface{}, sinks map[string]Consumer) Consumer {
+	if len(extractors) != len(sinks) {
+		panic("extractors and sinks must have the same names")
+	}
+	names := make([]string, 0, len(extractors))
+	for name := range extractors {
+		if _, ok := sinks[name]; !ok {
+			panic("no sink for extractor " + name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	consumers := make([]Consumer, len(names))
+	for i, name := range names {
+		consumers[i] = MapFilter(sinks[name], extractors[name])
+	}
+	return Compose(consumers...)
+}
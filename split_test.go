@@ -0,0 +1,56 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert := assert.New(t)
+	var names []string
+	var ages []int
+	consumer := consume.Split(
+		map[string]interface{}{
+			"names": func(src *person, dest *string) bool {
+				*dest = src.Name
+				return true
+			},
+			"ages": func(src *person, dest *int) bool {
+				*dest = src.Age
+				return src.Age >= 40
+			},
+		},
+		map[string]consume.Consumer{
+			"names": consume.Slice(consume.AppendTo(&names), 0, 2),
+			"ages":  consume.AppendTo(&ages),
+		})
+	for i := range people {
+		consumer.Consume(&people[i])
+	}
+	assert.Equal([]string{people[0].Name, people[1].Name}, names)
+	var expectedAges []int
+	for _, p := range people {
+		if p.Age >= 40 {
+			expectedAges = append(expectedAges, p.Age)
+		}
+	}
+	assert.Equal(expectedAges, ages)
+}
+
+func TestSplitPanics(t *testing.T) {
+	assert := assert.New(t)
+	filter := func(ptr *int) bool { return true }
+	assert.Panics(func() {
+		consume.Split(
+			map[string]interface{}{"a": filter},
+			map[string]consume.Consumer{"b": consume.Nil()})
+	})
+	assert.Panics(func() {
+		consume.Split(
+			map[string]interface{}{"a": filter},
+			map[string]consume.Consumer{
+				"a": consume.Nil(), "b": consume.Nil()})
+	})
+}
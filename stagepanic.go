@@ -0,0 +1,82 @@
+package consume
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// StagePanicError is what the consumers from MapFilter and TakeWhile
+// panic with when one of the functions, Mappers, or Filterers passed to
+// them panics. It records which stage panicked and the type of the value
+// the stage was processing.
+type StagePanicError struct {
+
+	// Stage is the zero based index of the stage that panicked.
+	Stage int
+
+	// Name names the stage. For functions, Name is the name of the
+	// function such as "main.main.func1". For Mappers and Filterers, Name
+	// is their Go type.
+	Name string
+
+	// Type is the type of the value the stage was processing.
+	Type reflect.Type
+
+	// Value is the value recovered from the panic.
+	Value interface{}
+}
+
+func (s *StagePanicError) Error() string {
+	return fmt.Sprintf(
+		"consume: stage %d (%s) panicked processing %v: %v",
+		s.Stage, s.Name, s.Type, s.Value)
+}
+
+// Unwrap returns Value if it is an error and nil otherwise.
+func (s *StagePanicError) Unwrap() error {
+	err, _ := s.Value.(error)
+	return err
+}
+
+// rePanicWithStage re-panics with a *StagePanicError if recovered is
+// non nil. If recovered is already a *StagePanicError from a nested
+// stage, rePanicWithStage re-panics with it as is.
+func rePanicWithStage(
+	recovered interface{}, index int, stage mapFilterStage,
+	ptr interface{}) {
+	if recovered == nil {
+		return
+	}
+	if _, ok := recovered.(*StagePanicError); ok {
+		panic(recovered)
+	}
+	panic(&StagePanicError{
+		Stage: index,
+		Name:  stageName(stage),
+		Type:  reflect.TypeOf(ptr),
+		Value: recovered,
+	})
+}
+
+func stageName(stage mapFilterStage) string {
+	switch s := stage.(type) {
+	case *filtererInterfaceWrapper:
+		return fmt.Sprintf("%T", s.value)
+	case *mapperInterfaceWrapper:
+		return fmt.Sprintf("%T", s.value)
+	case *filterer:
+		return funcName(s.value)
+	case *mapper:
+		return funcName(s.value)
+	default:
+		return fmt.Sprintf("%T", stage)
+	}
+}
+
+func funcName(f reflect.Value) string {
+	if fn := runtime.FuncForPC(f.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return f.Type().String()
+}
@@ -0,0 +1,81 @@
+package consume_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStagePanicError(t *testing.T) {
+	assert := assert.New(t)
+	errBad := errors.New("bad value")
+	consumer := consume.MapFilter(
+		consume.ConsumerFunc(func(ptr interface{}) {}),
+		func(ptr *int) bool { return true },
+		func(src *int, dest *string) bool {
+			if *src == 3 {
+				panic(errBad)
+			}
+			*dest = "x"
+			return true
+		})
+	recovered := recoverFrom(func() {
+		consume.FeedRange(0, 5, 1, consumer)
+	})
+	stagePanic, ok := recovered.(*consume.StagePanicError)
+	if !assert.True(ok) {
+		return
+	}
+	assert.Equal(1, stagePanic.Stage)
+	assert.True(strings.HasPrefix(stagePanic.Name, "github.com/keep94/"))
+	assert.Equal(reflect.TypeOf((*int)(nil)), stagePanic.Type)
+	assert.Equal(errBad, stagePanic.Value)
+	assert.True(errors.Is(stagePanic, errBad))
+}
+
+func TestStagePanicErrorTakeWhile(t *testing.T) {
+	assert := assert.New(t)
+	var result []int
+	consumer := consume.TakeWhile(
+		consume.AppendTo(&result),
+		consume.NewMapFilterer(func(ptr *int) bool {
+			if *ptr == 2 {
+				panic("oops")
+			}
+			return true
+		}))
+	recovered := recoverFrom(func() {
+		consume.FeedRange(0, 5, 1, consumer)
+	})
+	stagePanic, ok := recovered.(*consume.StagePanicError)
+	if !assert.True(ok) {
+		return
+	}
+	assert.Equal(0, stagePanic.Stage)
+	assert.Equal("oops", stagePanic.Value)
+	assert.Nil(stagePanic.Unwrap())
+	assert.Equal([]int{0, 1}, result)
+}
+
+func TestStagePanicErrorDownstream(t *testing.T) {
+	assert := assert.New(t)
+	consumer := consume.MapFilter(
+		consume.ConsumerFunc(func(ptr interface{}) { panic("downstream") }),
+		func(ptr *int) bool { return true })
+	recovered := recoverFrom(func() {
+		consume.FeedRange(0, 5, 1, consumer)
+	})
+	assert.Equal("downstream", recovered)
+}
+
+func recoverFrom(f func()) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,69 @@
+package consume
+
+import (
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Column describes a single column of a table that ToTable writes.
+type Column struct {
+
+	// Header is the heading of the column.
+	Header string
+
+	// Value returns the text of this column for the value ptr points to.
+	Value func(ptr interface{}) string
+}
+
+// ToTable returns a ConsumeFinalizer that writes consumed values to w as
+// an aligned text table. The first row of the table contains the headers
+// of columns. Each consumed value becomes one row of the table. Because
+// columns cannot be aligned until all rows are known, nothing is written
+// to w until caller calls Finalize() on the returned consumer.
+func ToTable(w io.Writer, columns []Column) ConsumeFinalizer {
+	columnsCopy := make([]Column, len(columns))
+	copy(columnsCopy, columns)
+	result := &tableConsumer{
+		writer:  tabwriter.NewWriter(w, 0, 8, 2, ' ', 0),
+		columns: columnsCopy,
+		row:     make([]string, len(columns)),
+	}
+	for i := range columnsCopy {
+		result.row[i] = columnsCopy[i].Header
+	}
+	result.writeRow()
+	return result
+}
+
+type tableConsumer struct {
+	writer    *tabwriter.Writer
+	columns   []Column
+	row       []string
+	finalized bool
+}
+
+func (t *tableConsumer) CanConsume() bool {
+	return !t.finalized
+}
+
+func (t *tableConsumer) Consume(ptr interface{}) {
+	MustCanConsume(t)
+	for i := range t.columns {
+		t.row[i] = t.columns[i].Value(ptr)
+	}
+	t.writeRow()
+}
+
+func (t *tableConsumer) Finalize() {
+	if t.finalized {
+		return
+	}
+	t.finalized = true
+	t.writer.Flush()
+}
+
+func (t *tableConsumer) writeRow() {
+	io.WriteString(t.writer, strings.Join(t.row, "\t"))
+	io.WriteString(t.writer, "\n")
+}
@@ -0,0 +1,49 @@
+package consume_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+var personColumns = []consume.Column{
+	{
+		Header: "Name",
+		Value:  func(ptr interface{}) string { return ptr.(*person).Name },
+	},
+	{
+		Header: "Age",
+		Value: func(ptr interface{}) string {
+			return strconv.Itoa(ptr.(*person).Age)
+		},
+	},
+}
+
+func TestToTable(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToTable(&sb, personColumns)
+	writePeopleInLoop(people[:], consume.Slice(cf, 0, 3))
+	assert.Empty(sb.String())
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Panics(func() { cf.Consume(&people[mark]) })
+	expected := `Name    Age
+Mark    50
+Stoney  49
+Matt    46
+`
+	assert.Equal(expected, sb.String())
+}
+
+func TestToTableEmpty(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToTable(&sb, personColumns)
+	cf.Finalize()
+	assert.Equal("Name  Age\n", sb.String())
+}
@@ -0,0 +1,164 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// SliceFromEnd returns a ConsumeFinalizer that passes only the last lastN
+// values it consumes onto c. Since the returned consumer can't know which
+// values are last until it has consumed them all, it keeps copies of the
+// last lastN values in a ring buffer and passes them onto c when caller
+// calls Finalize. Finalize releases the ring buffer. SliceFromEnd panics
+// if lastN is negative.
+func SliceFromEnd(c Consumer, lastN int) ConsumeFinalizer {
+	if lastN < 0 {
+		panic("lastN must be non-negative")
+	}
+	return &relativeSliceConsumer{
+		consumer:     c,
+		start:        lastN,
+		startFromEnd: true,
+		endFromEnd:   true,
+	}
+}
+
+// SliceRelative works like Slice except that a negative start or end
+// counts from the end of the values consumed. For example,
+// SliceRelative(c, -10, -2) passes the 10th to last value inclusive to the
+// 2nd to last value exclusive onto c, and SliceRelative(c, 3, -1) passes
+// all values but the first 3 and the last one onto c. When start or end is
+// negative, the returned consumer keeps copies of values in a ring buffer
+// as large as the absolute value of start or end, and caller must call
+// Finalize to pass any values still in the ring buffer onto c. Finalize
+// releases the ring buffer.
+func SliceRelative(c Consumer, start, end int) ConsumeFinalizer {
+	result := &relativeSliceConsumer{consumer: c, start: start, end: end}
+	if start < 0 {
+		result.start = -start
+		result.startFromEnd = true
+	}
+	if end < 0 {
+		result.end = -end
+		result.endFromEnd = true
+	}
+	return result
+}
+
+// relativeSliceConsumer stores start and end as non-negative values.
+// startFromEnd and endFromEnd indicate which of them count from the end.
+type relativeSliceConsumer struct {
+	consumer     Consumer
+	start        int
+	end          int
+	startFromEnd bool
+	endFromEnd   bool
+	idx          int
+	ring         ringBuffer
+	finalized    bool
+}
+
+func (r *relativeSliceConsumer) children() []interface{} {
+	return []interface{}{r.consumer}
+}
+
+func (r *relativeSliceConsumer) CanConsume() bool {
+	if r.finalized || !r.consumer.CanConsume() {
+		return false
+	}
+	return r.startFromEnd || r.endFromEnd || r.idx < r.end
+}
+
+func (r *relativeSliceConsumer) Consume(ptr interface{}) {
+	MustCanConsume(r)
+	idx := r.idx
+	r.idx++
+	switch {
+	case r.startFromEnd:
+		r.ring.push(r.start, ptr)
+	case r.endFromEnd:
+
+		// Hold back the last end values. A value leaving the ring buffer
+		// is known not to be among them.
+		if r.end == 0 {
+			if idx >= r.start {
+				r.consumer.Consume(ptr)
+			}
+			return
+		}
+		if r.ring.len() == r.end && idx-r.end >= r.start {
+			r.consumer.Consume(r.ring.oldest())
+		}
+		r.ring.push(r.end, ptr)
+	default:
+		if idx >= r.start {
+			r.consumer.Consume(ptr)
+		}
+	}
+}
+
+func (r *relativeSliceConsumer) Finalize() {
+	if r.finalized {
+		return
+	}
+	r.finalized = true
+	defer r.ring.release()
+	if !r.startFromEnd {
+		return
+	}
+	n := r.idx
+	hi := r.end
+	if r.endFromEnd {
+		hi = n - r.end
+	} else if hi > n {
+		hi = n
+	}
+	first := n - r.ring.len()
+	for i := 0; first+i < hi && r.consumer.CanConsume(); i++ {
+		r.consumer.Consume(r.ring.at(i))
+	}
+}
+
+// ringBuffer holds copies of the most recent values pushed to it. It
+// allocates slots as they are first filled so that a large capacity costs
+// nothing until values arrive. Until the ring buffer is full, head is 0.
+type ringBuffer struct {
+	slots []reflect.Value
+	head  int
+	count int
+}
+
+// push copies the value ptr points to into this ring buffer evicting the
+// oldest value if this ring buffer already holds capacity values.
+func (r *ringBuffer) push(capacity int, ptr interface{}) {
+	if capacity == 0 {
+		return
+	}
+	value := reflect.ValueOf(ptr).Elem()
+	var slot reflect.Value
+	if r.count < capacity {
+		slot = reflect.New(value.Type())
+		r.slots = append(r.slots, slot)
+		r.count++
+	} else {
+		slot = r.slots[r.head]
+		r.head = (r.head + 1) % capacity
+	}
+	slot.Elem().Set(value)
+}
+
+func (r *ringBuffer) len() int {
+	return r.count
+}
+
+// at returns a pointer to the i th oldest value.
+func (r *ringBuffer) at(i int) interface{} {
+	return r.slots[(r.head+i)%len(r.slots)].Interface()
+}
+
+func (r *ringBuffer) oldest() interface{} {
+	return r.at(0)
+}
+
+func (r *ringBuffer) release() {
+	*r = ringBuffer{}
+}
@@ -0,0 +1,85 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceFromEnd(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.SliceFromEnd(consume.AppendTo(&ints), 3)
+	for i := 0; i < 10; i++ {
+		cf.Consume(&i)
+	}
+	assert.Empty(ints)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal([]int{7, 8, 9}, ints)
+	assert.False(cf.CanConsume())
+	assert.Panics(func() { cf.Consume(new(int)) })
+}
+
+func TestSliceFromEndFewValues(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.SliceFromEnd(consume.AppendTo(&ints), 5)
+	for i := 0; i < 2; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Equal([]int{0, 1}, ints)
+	ints = nil
+	cf = consume.SliceFromEnd(consume.AppendTo(&ints), 1<<30)
+	for i := 0; i < 2; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Equal([]int{0, 1}, ints)
+	var none []int
+	cf = consume.SliceFromEnd(consume.AppendTo(&none), 0)
+	cf.Consume(new(int))
+	cf.Finalize()
+	assert.Empty(none)
+	assert.Panics(func() { consume.SliceFromEnd(consume.AppendTo(&none), -1) })
+}
+
+func TestSliceRelative(t *testing.T) {
+	assert := assert.New(t)
+	bounds := []struct {
+		start, end int
+		expected   []int
+	}{
+		{-4, -1, []int{6, 7, 8}},
+		{3, -2, []int{3, 4, 5, 6, 7}},
+		{-3, 9, []int{7, 8}},
+		{-3, 5, nil},
+		{2, 5, []int{2, 3, 4}},
+		{8, -3, nil},
+		{0, -15, nil},
+		{-15, 2, []int{0, 1}},
+	}
+	for _, b := range bounds {
+		var ints []int
+		cf := consume.SliceRelative(consume.AppendTo(&ints), b.start, b.end)
+		for i := 0; i < 10 && cf.CanConsume(); i++ {
+			cf.Consume(&i)
+		}
+		cf.Finalize()
+		assert.Equal(b.expected, ints, "%d %d", b.start, b.end)
+	}
+}
+
+func TestSliceRelativeStopsEarly(t *testing.T) {
+	assert := assert.New(t)
+	var ints []int
+	cf := consume.SliceRelative(
+		consume.Slice(consume.AppendTo(&ints), 0, 2), -5, -1)
+	for i := 0; i < 10; i++ {
+		cf.Consume(&i)
+	}
+	cf.Finalize()
+	assert.Equal([]int{5, 6}, ints)
+}
@@ -0,0 +1,30 @@
+package consume
+
+import (
+	"time"
+)
+
+// Timed returns a Consumer that passes the values it consumes onto c and
+// calls record with how long each call to c.Consume took. record can feed
+// a histogram to find slow stages. The CanConsume method of the returned
+// consumer returns the same as c.CanConsume().
+func Timed(c Consumer, record func(d time.Duration)) Consumer {
+	return &timedConsumer{Consumer: c, record: record, clock: systemClock{}}
+}
+
+type timedConsumer struct {
+	Consumer
+	record func(d time.Duration)
+	clock  Clock
+}
+
+func (t *timedConsumer) children() []interface{} {
+	return []interface{}{t.Consumer}
+}
+
+func (t *timedConsumer) Consume(ptr interface{}) {
+	MustCanConsume(t)
+	start := t.clock.Now()
+	t.Consumer.Consume(ptr)
+	t.record(t.clock.Now().Sub(start))
+}
@@ -0,0 +1,71 @@
+package consume
+
+// Tracer starts spans. Tracer is a small subset of what tracing libraries
+// such as OpenTelemetry offer so that this package does not depend on
+// any of them. Write a thin adapter to use a real tracer.
+type Tracer interface {
+
+	// StartSpan starts a new span with given name.
+	StartSpan(name string) Span
+}
+
+// Span is a span that a Tracer started.
+type Span interface {
+
+	// SetInt sets an integer attribute on this span.
+	SetInt(key string, value int)
+
+	// End ends this span.
+	End()
+}
+
+// Traced returns a ConsumeFinalizer that passes the values it consumes
+// onto c while recording them in a span. The span, named name, starts
+// when the returned consumer consumes its first value and ends when
+// caller calls Finalize. Before the span ends, Traced sets the
+// "consume.count" attribute to the number of values consumed. If no
+// values are consumed, no span is started. Finalize also finalizes c if
+// c is a ConsumeFinalizer.
+func Traced(c Consumer, tracer Tracer, name string) ConsumeFinalizer {
+	return &tracedConsumer{Consumer: c, tracer: tracer, name: name}
+}
+
+type tracedConsumer struct {
+	Consumer
+	tracer    Tracer
+	name      string
+	span      Span
+	count     int
+	finalized bool
+}
+
+func (t *tracedConsumer) children() []interface{} {
+	return []interface{}{t.Consumer}
+}
+
+func (t *tracedConsumer) CanConsume() bool {
+	return !t.finalized && t.Consumer.CanConsume()
+}
+
+func (t *tracedConsumer) Consume(ptr interface{}) {
+	MustCanConsume(t)
+	if t.span == nil {
+		t.span = t.tracer.StartSpan(t.name)
+	}
+	t.count++
+	t.Consumer.Consume(ptr)
+}
+
+func (t *tracedConsumer) Finalize() {
+	if t.finalized {
+		return
+	}
+	t.finalized = true
+	if cf, ok := t.Consumer.(ConsumeFinalizer); ok {
+		cf.Finalize()
+	}
+	if t.span != nil {
+		t.span.SetInt("consume.count", t.count)
+		t.span.End()
+	}
+}
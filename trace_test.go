@@ -0,0 +1,62 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraced(t *testing.T) {
+	assert := assert.New(t)
+	tracer := &fakeTracer{}
+	var ints []int
+	cf := consume.Traced(
+		consume.AppendToSaveMemory(&ints), tracer, "collect")
+	feedInts(t, consume.Slice(cf, 0, 4))
+	assert.Len(tracer.spans, 1)
+	assert.False(tracer.spans[0].ended)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.Equal([]int{0, 1, 2, 3}, ints)
+	assert.Equal(
+		&fakeSpan{
+			name:  "collect",
+			attrs: map[string]int{"consume.count": 4},
+			ended: true,
+		},
+		tracer.spans[0])
+	assert.False(cf.CanConsume())
+}
+
+func TestTracedNoValues(t *testing.T) {
+	assert := assert.New(t)
+	tracer := &fakeTracer{}
+	cf := consume.Traced(consume.Nil(), tracer, "nothing")
+	cf.Finalize()
+	assert.Empty(tracer.spans)
+}
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (f *fakeTracer) StartSpan(name string) consume.Span {
+	span := &fakeSpan{name: name, attrs: make(map[string]int)}
+	f.spans = append(f.spans, span)
+	return span
+}
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]int
+	ended bool
+}
+
+func (f *fakeSpan) SetInt(key string, value int) {
+	f.attrs[key] = value
+}
+
+func (f *fakeSpan) End() {
+	f.ended = true
+}
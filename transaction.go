@@ -0,0 +1,129 @@
+package consume
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrTransactionDone is what the methods of TransactionalConsumer return
+// after the transaction has already been committed or rolled back.
+var ErrTransactionDone = errors.New(
+	"consume: transaction already committed or rolled back")
+
+// TransactionalConsumer is a ConsumeFinalizer whose output becomes
+// visible all at once or not at all. Finalize works like Commit except
+// that it ignores the error. Once committed or rolled back, a
+// TransactionalConsumer can no longer consume.
+type TransactionalConsumer interface {
+	ConsumeFinalizer
+
+	// Prepare finishes all the work needed to commit so that Commit is
+	// as unlikely to fail as possible. Prepare returns an error if the
+	// output can't be committed. After Prepare, the consumer can no
+	// longer consume.
+	Prepare() error
+
+	// Commit makes the output visible calling Prepare first if needed.
+	// If Commit returns an error, the output did not fully become
+	// visible. Depending on the implementation, part of it may have
+	// become visible.
+	Commit() error
+
+	// Rollback discards the output.
+	Rollback() error
+}
+
+// Transactional returns a TransactionalConsumer that works like the
+// ConsumeFinalizer that newSink returns except that newSink writes to an
+// in-memory buffer rather than to w. Prepare finalizes that
+// ConsumeFinalizer and reports the error from its Healthy method if it
+// implements Health. Commit writes the buffered output to w in one call.
+// If that write fails, w may still have received part of the output.
+// Rollback writes nothing. The returned consumer also implements Health.
+// Healthy reports the first error from Prepare or Commit so that callers
+// of Finalize such as Run can see it.
+func Transactional(
+	newSink func(w io.Writer) ConsumeFinalizer,
+	w io.Writer) TransactionalConsumer {
+	result := &transactionalConsumer{w: w}
+	result.sink = newSink(&result.buffer)
+	awaitCommit(result.sink)
+	return result
+}
+
+type transactionalConsumer struct {
+	w        io.Writer
+	buffer   bytes.Buffer
+	sink     ConsumeFinalizer
+	err      error
+	prepared bool
+	done     bool
+}
+
+func (t *transactionalConsumer) children() []interface{} {
+	return []interface{}{t.sink}
+}
+
+func (t *transactionalConsumer) CanConsume() bool {
+	return !t.prepared && !t.done && t.sink.CanConsume()
+}
+
+func (t *transactionalConsumer) Consume(ptr interface{}) {
+	MustCanConsume(t)
+	t.sink.Consume(ptr)
+}
+
+func (t *transactionalConsumer) Finalize() {
+	t.Commit()
+}
+
+func (t *transactionalConsumer) Prepare() error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	if t.prepared {
+		return t.err
+	}
+	t.prepared = true
+	t.sink.Finalize()
+	t.setErr(CheckHealth(t.sink))
+	return t.err
+}
+
+func (t *transactionalConsumer) Commit() error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.Prepare()
+	t.done = true
+	if t.err == nil {
+		_, err := t.w.Write(t.buffer.Bytes())
+		t.setErr(err)
+	}
+	if t.err == nil {
+		notifyCommitted(t.sink)
+	}
+	t.buffer = bytes.Buffer{}
+	return t.err
+}
+
+func (t *transactionalConsumer) Rollback() error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.done = true
+	t.sink.Finalize()
+	t.buffer = bytes.Buffer{}
+	return nil
+}
+
+func (t *transactionalConsumer) Healthy() error {
+	return t.err
+}
+
+func (t *transactionalConsumer) setErr(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
@@ -0,0 +1,84 @@
+package consume_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCSVSink(w io.Writer) consume.ConsumeFinalizer {
+	return consume.ToCSV(w, nil)
+}
+
+func TestTransactionalCommit(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	tc := consume.Transactional(newCSVSink, &sb)
+	consume.FeedSlice(people[:2], tc)
+	assert.Empty(sb.String())
+	assert.NoError(tc.Prepare())
+	assert.False(tc.CanConsume())
+	assert.Empty(sb.String())
+	assert.NoError(tc.Commit())
+	assert.Equal("Name,Age\nMark,50\nStoney,49\n", sb.String())
+	assert.Equal(consume.ErrTransactionDone, tc.Commit())
+	assert.Equal(consume.ErrTransactionDone, tc.Rollback())
+	assert.Equal(consume.ErrTransactionDone, tc.Prepare())
+	tc.Finalize()
+	assert.Equal("Name,Age\nMark,50\nStoney,49\n", sb.String())
+}
+
+func TestTransactionalFinalize(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	tc := consume.Transactional(newCSVSink, &sb)
+	consume.FeedSlice(people[:1], tc)
+	tc.Finalize()
+	assert.False(tc.CanConsume())
+	assert.Equal("Name,Age\nMark,50\n", sb.String())
+}
+
+func TestTransactionalRollback(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	tc := consume.Transactional(newCSVSink, &sb)
+	consume.FeedSlice(people, tc)
+	assert.NoError(tc.Rollback())
+	assert.False(tc.CanConsume())
+	tc.Finalize()
+	assert.Empty(sb.String())
+}
+
+func TestTransactionalPrepareError(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	tc := consume.Transactional(
+		func(w io.Writer) consume.ConsumeFinalizer {
+			return consume.ToAvro(w, `"string"`)
+		},
+		&sb)
+	x := 3
+	tc.Consume(&x)
+	assert.Error(tc.Prepare())
+	assert.Error(tc.Commit())
+	assert.Empty(sb.String())
+}
+
+func TestTransactionalWriteError(t *testing.T) {
+	assert := assert.New(t)
+	tc := consume.Transactional(newCSVSink, errorWriter{})
+	consume.FeedSlice(people[:1], tc)
+	assert.EqualError(tc.Commit(), "write failed")
+}
+
+func TestTransactionalWriteErrorReported(t *testing.T) {
+	assert := assert.New(t)
+	tc := consume.Transactional(consume.ToYAML, errorWriter{})
+	report := consume.Run(countingProducer(3), tc)
+	assert.EqualError(report.Err, "write failed")
+	assert.EqualError(consume.CheckHealth(tc), "write failed")
+	assert.Equal(consume.ErrTransactionDone, tc.Commit())
+}
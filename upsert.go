@@ -0,0 +1,69 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// UpsertMap returns a Consumer that stores the values it consumes in the
+// map that aMapPointer points to. keyFunc takes a pointer to a consumed
+// value and returns its key, e.g func(ptr *Person) int64. keyFunc can
+// also be a func(ptr interface{}) interface{}. If the key is new,
+// the returned consumer stores a copy of the value under the key.
+// Otherwise, it calls mergeFunc to merge the consumed value into the
+// value already in the map. mergeFunc takes a pointer to the existing
+// value followed by a pointer to the consumed value, e.g
+// func(existing, src *Person). The consumed values must be of the map's
+// value type. If the map is nil, UpsertMap creates it. The returned
+// consumer can always consume. UpsertMap panics if aMapPointer is not a
+// pointer to a map or if keyFunc or mergeFunc have the wrong types.
+func UpsertMap(aMapPointer, keyFunc, mergeFunc interface{}) Consumer {
+	mapPtr := reflect.ValueOf(aMapPointer)
+	if mapPtr.Kind() != reflect.Ptr || mapPtr.Elem().Kind() != reflect.Map {
+		panic("A pointer to a map is expected.")
+	}
+	aMap := mapPtr.Elem()
+	if aMap.IsNil() {
+		aMap.Set(reflect.MakeMap(aMap.Type()))
+	}
+	valuePtrType := reflect.PtrTo(aMap.Type().Elem())
+	mergeType := reflect.TypeOf(mergeFunc)
+	if mergeType == nil || mergeType.Kind() != reflect.Func ||
+		mergeType.NumIn() != 2 || mergeType.NumOut() != 0 ||
+		mergeType.In(0) != valuePtrType || mergeType.In(1) != valuePtrType {
+		panic("mergeFunc must be like func(existing, src *V)")
+	}
+	return &upsertMapConsumer{
+		aMap:      aMap,
+		keyFunc:   newKeyFunc(keyFunc),
+		mergeFunc: reflect.ValueOf(mergeFunc),
+		existing:  reflect.New(aMap.Type().Elem()),
+	}
+}
+
+type upsertMapConsumer struct {
+	aMap      reflect.Value
+	keyFunc   keyFunc
+	mergeFunc reflect.Value
+
+	// existing holds a copy of the value being merged since map values
+	// aren't addressable.
+	existing reflect.Value
+}
+
+func (u *upsertMapConsumer) CanConsume() bool {
+	return true
+}
+
+func (u *upsertMapConsumer) Consume(ptr interface{}) {
+	key := reflect.ValueOf(u.keyFunc.key(ptr))
+	src := reflect.ValueOf(ptr)
+	value := u.aMap.MapIndex(key)
+	if !value.IsValid() {
+		u.aMap.SetMapIndex(key, src.Elem())
+		return
+	}
+	u.existing.Elem().Set(value)
+	params := [...]reflect.Value{u.existing, src}
+	u.mergeFunc.Call(params[:])
+	u.aMap.SetMapIndex(key, u.existing.Elem())
+}
@@ -0,0 +1,60 @@
+package consume_test
+
+import (
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpsertMap(t *testing.T) {
+	assert := assert.New(t)
+	type total struct {
+		Name  string
+		Count int
+	}
+	var totals map[string]total
+	consumer := consume.UpsertMap(
+		&totals,
+		func(ptr *total) string { return ptr.Name },
+		func(existing, src *total) { existing.Count += src.Count })
+	assert.NotNil(totals)
+	assert.True(consumer.CanConsume())
+	consumer.Consume(&total{Name: "a", Count: 1})
+	consumer.Consume(&total{Name: "b", Count: 2})
+	consumer.Consume(&total{Name: "a", Count: 5})
+	assert.Equal(
+		map[string]total{
+			"a": {Name: "a", Count: 6},
+			"b": {Name: "b", Count: 2},
+		},
+		totals)
+}
+
+func TestUpsertMapExisting(t *testing.T) {
+	assert := assert.New(t)
+	ages := map[string]int{"x": 3}
+	consumer := consume.UpsertMap(
+		&ages,
+		func(ptr interface{}) interface{} { return "x" },
+		func(existing, src *int) {
+			if *src > *existing {
+				*existing = *src
+			}
+		})
+	feedInts(t, consume.Slice(consumer, 0, 10))
+	assert.Equal(map[string]int{"x": 9}, ages)
+}
+
+func TestUpsertMapPanics(t *testing.T) {
+	assert := assert.New(t)
+	var ages map[string]int
+	key := func(ptr *int) string { return "" }
+	merge := func(existing, src *int) {}
+	assert.Panics(func() { consume.UpsertMap(ages, key, merge) })
+	assert.Panics(func() { consume.UpsertMap(&[]int{}, key, merge) })
+	assert.Panics(func() {
+		consume.UpsertMap(&ages, key, func(existing, src *string) {})
+	})
+	assert.Panics(func() { consume.UpsertMap(&ages, nil, merge) })
+}
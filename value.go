@@ -0,0 +1,43 @@
+package consume
+
+import (
+	"reflect"
+)
+
+// valueConsumer is implemented by built-in consumers that can consume a
+// value already in reflect.Value form. Built-in stages use it to pass
+// values to each other without converting each value to an interface{}
+// and back at every stage.
+type valueConsumer interface {
+
+	// consumeValue works like Consume except that value is the
+	// addressable value to consume rather than a pointer to it.
+	consumeValue(value reflect.Value)
+}
+
+// consumeValue has c consume value. value must be addressable.
+func consumeValue(c Consumer, value reflect.Value) {
+	if vc, ok := c.(valueConsumer); ok {
+		vc.consumeValue(value)
+		return
+	}
+	c.Consume(value.Addr().Interface())
+}
+
+// appendValue appends value to the slice aSliceValue. Unlike
+// reflect.Append, appendValue does not allocate unless the slice must
+// grow.
+func appendValue(aSliceValue reflect.Value, value reflect.Value) {
+	length := aSliceValue.Len()
+	if length == aSliceValue.Cap() {
+		capacity := 2 * length
+		if capacity < 4 {
+			capacity = 4
+		}
+		newSlice := reflect.MakeSlice(aSliceValue.Type(), length, capacity)
+		reflect.Copy(newSlice, aSliceValue)
+		aSliceValue.Set(newSlice)
+	}
+	aSliceValue.SetLen(length + 1)
+	aSliceValue.Index(length).Set(value)
+}
@@ -0,0 +1,314 @@
+package consume
+
+import (
+	"reflect"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// WindowedAggregate returns a ConsumeFinalizer that aggregates the values
+// it consumes over sliding time windows and passes one aggregate value
+// per window onto c. Each window is window long, and a new window starts
+// every slide. Windows start at multiples of slide since the zero time.
+// When slide equals window, the windows don't overlap.
+//
+// timeFunc takes a pointer to a consumed value and returns its time.Time.
+// initFunc initializes the aggregate of a new window. It takes the start
+// and end time of the window followed by a pointer to the aggregate, e.g
+// func(start, end time.Time, agg *Stats). reduceFunc folds a consumed
+// value into the aggregate of each window that the value belongs to. It
+// takes a pointer to the aggregate followed by a pointer to the value,
+// e.g func(agg *Stats, ptr *Order).
+//
+// The returned consumer expects values in time order. It passes the
+// aggregate of a window onto c as soon as it consumes a value with a
+// time at or after the end of that window. Windows with no values produce
+// no aggregate. Values belonging only to windows already passed onto c
+// are dropped unless the LateTo option is given. Caller must call
+// Finalize() to pass the aggregates of the remaining windows onto c. Use
+// AllowLateness to tolerate values that are out of time order. Use
+// ProcessingTime to window values by when they are consumed instead.
+// WindowedAggregate panics if window or slide are not positive or if the
+// functions passed in have the wrong types.
+func WindowedAggregate(
+	c Consumer,
+	window, slide time.Duration,
+	timeFunc, initFunc, reduceFunc interface{},
+	options ...WindowOption) ConsumeFinalizer {
+	return newWindowConsumer(
+		c,
+		window,
+		slide,
+		timeFunc,
+		newAggregateAccumulator(initFunc, reduceFunc),
+		options)
+}
+
+// TumblingWindow returns a ConsumeFinalizer that groups the values it
+// consumes into consecutive non-overlapping time windows each window long
+// and passes each window onto c as a pointer to a slice of values. That
+// is, if the returned consumer consumes *T values, it passes *[]T values
+// onto c. Windows start at multiples of window since the zero time.
+// timeFunc takes a pointer to a consumed value and returns its time.Time.
+// The returned consumer expects values in time order. It passes a window
+// onto c as soon as it consumes a value with a time at or after the end
+// of that window. Empty windows are never passed onto c. Caller must call
+// Finalize() to pass the last, partial window onto c. Use AllowLateness
+// to tolerate values that are out of time order. Use ProcessingTime to
+// window values by when they are consumed instead. TumblingWindow panics
+// if window is not positive.
+func TumblingWindow(
+	c Consumer,
+	window time.Duration,
+	timeFunc interface{},
+	options ...WindowOption) ConsumeFinalizer {
+	return newWindowConsumer(
+		c, window, window, timeFunc, sliceAccumulator{}, options)
+}
+
+// WindowOption is an option for WindowedAggregate and TumblingWindow.
+type WindowOption func(w *windowConsumer)
+
+// AllowLateness lets values arrive up to d out of time order. The
+// watermark of a windowing consumer is the latest time it has seen minus
+// d. A window stays open until the watermark reaches its end, so values
+// up to d late still get merged into their window. AllowLateness panics
+// if d is negative.
+func AllowLateness(d time.Duration) WindowOption {
+	if d < 0 {
+		panic("d must be non-negative")
+	}
+	return func(w *windowConsumer) {
+		w.lateness = d
+	}
+}
+
+// LateTo sends values that arrive after all of their windows have closed
+// to c instead of dropping them. Values go to c only if c can consume
+// them.
+func LateTo(c Consumer) WindowOption {
+	return func(w *windowConsumer) {
+		w.late = c
+	}
+}
+
+// ProcessingTime makes a windowing consumer use processing time instead
+// of event time. That is, the time of each value is the time that clock
+// reports when the value is consumed rather than the time timeFunc
+// returns. With this option, timeFunc is ignored and may be nil. If clock
+// is nil, the system clock is used.
+func ProcessingTime(clock Clock) WindowOption {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return func(w *windowConsumer) {
+		w.clock = clock
+	}
+}
+
+// windowAccumulator accumulates the values of a single window.
+type windowAccumulator interface {
+
+	// init returns a pointer to a new accumulator for a window. ptr
+	// points to the first value of the window.
+	init(start, end time.Time, ptr interface{}) reflect.Value
+
+	// add adds the value ptr points to to acc.
+	add(acc reflect.Value, ptr interface{})
+}
+
+type timeWindow struct {
+	start time.Time
+	end   time.Time
+	acc   reflect.Value
+}
+
+type windowConsumer struct {
+	consumer     Consumer
+	window       time.Duration
+	slide        time.Duration
+	timeFunc     keyFunc
+	clock        Clock
+	accumulator  windowAccumulator
+	lateness     time.Duration
+	late         Consumer
+	open         []*timeWindow
+	latest       time.Time
+	watermark    time.Time
+	hasWatermark bool
+	finalized    bool
+}
+
+func (w *windowConsumer) children() []interface{} {
+	return []interface{}{w.consumer, w.late}
+}
+
+func newWindowConsumer(
+	c Consumer,
+	window, slide time.Duration,
+	timeFunc interface{},
+	accumulator windowAccumulator,
+	options []WindowOption) *windowConsumer {
+	if window <= 0 {
+		panic("window must be positive")
+	}
+	if slide <= 0 {
+		panic("slide must be positive")
+	}
+	result := &windowConsumer{
+		consumer:    c,
+		window:      window,
+		slide:       slide,
+		accumulator: accumulator,
+		late:        nilConsumer{},
+	}
+	for _, option := range options {
+		option(result)
+	}
+	if result.clock == nil {
+		result.timeFunc = newKeyFunc(timeFunc)
+	}
+	return result
+}
+
+func (w *windowConsumer) CanConsume() bool {
+	return !w.finalized && w.consumer.CanConsume()
+}
+
+func (w *windowConsumer) Consume(ptr interface{}) {
+	MustCanConsume(w)
+	t := w.timeOf(ptr)
+	added := false
+	isLate := false
+	start := t.Truncate(w.slide)
+	for ; start.Add(w.window).After(t); start = start.Add(-w.slide) {
+		end := start.Add(w.window)
+		if w.hasWatermark && !end.After(w.watermark) {
+			isLate = true
+			break
+		}
+		w.accumulator.add(w.windowFor(start, end, ptr).acc, ptr)
+		added = true
+	}
+	if isLate && !added && w.late.CanConsume() {
+		w.late.Consume(ptr)
+	}
+	if !w.hasWatermark || t.After(w.latest) {
+		w.latest = t
+		w.watermark = t.Add(-w.lateness)
+		w.hasWatermark = true
+	}
+	w.emitClosed()
+}
+
+func (w *windowConsumer) Finalize() {
+	if w.finalized {
+		return
+	}
+	w.finalized = true
+	for _, win := range w.open {
+		w.emit(win)
+	}
+	w.open = nil
+}
+
+func (w *windowConsumer) timeOf(ptr interface{}) time.Time {
+	if w.clock != nil {
+		return w.clock.Now()
+	}
+	return w.timeFunc.key(ptr).(time.Time)
+}
+
+// windowFor returns the open window starting at start creating it if
+// needed. open is kept sorted by start time.
+func (w *windowConsumer) windowFor(
+	start, end time.Time, ptr interface{}) *timeWindow {
+	idx := len(w.open)
+	for idx > 0 && !w.open[idx-1].start.Before(start) {
+		idx--
+	}
+	if idx < len(w.open) && w.open[idx].start.Equal(start) {
+		return w.open[idx]
+	}
+	win := &timeWindow{
+		start: start, end: end, acc: w.accumulator.init(start, end, ptr)}
+	w.open = append(w.open, nil)
+	copy(w.open[idx+1:], w.open[idx:])
+	w.open[idx] = win
+	return win
+}
+
+func (w *windowConsumer) emitClosed() {
+	idx := 0
+	for idx < len(w.open) && !w.open[idx].end.After(w.watermark) {
+		w.emit(w.open[idx])
+		w.open[idx] = nil
+		idx++
+	}
+	w.open = w.open[idx:]
+}
+
+func (w *windowConsumer) emit(win *timeWindow) {
+	if w.consumer.CanConsume() {
+		w.consumer.Consume(win.acc.Interface())
+	}
+}
+
+type aggregateAccumulator struct {
+	aggType    reflect.Type
+	initFunc   reflect.Value
+	reduceFunc reflect.Value
+}
+
+func newAggregateAccumulator(
+	initFunc, reduceFunc interface{}) *aggregateAccumulator {
+	initType := reflect.TypeOf(initFunc)
+	if initType == nil || initType.Kind() != reflect.Func ||
+		initType.NumIn() != 3 || initType.NumOut() != 0 ||
+		initType.In(0) != timeType || initType.In(1) != timeType ||
+		initType.In(2).Kind() != reflect.Ptr {
+		panic("initFunc must be like func(start, end time.Time, agg *A)")
+	}
+	aggPtrType := initType.In(2)
+	reduceType := reflect.TypeOf(reduceFunc)
+	if reduceType == nil || reduceType.Kind() != reflect.Func ||
+		reduceType.NumIn() != 2 || reduceType.NumOut() != 0 ||
+		reduceType.In(0) != aggPtrType ||
+		reduceType.In(1).Kind() != reflect.Ptr {
+		panic("reduceFunc must be like func(agg *A, ptr *T)")
+	}
+	return &aggregateAccumulator{
+		aggType:    aggPtrType.Elem(),
+		initFunc:   reflect.ValueOf(initFunc),
+		reduceFunc: reflect.ValueOf(reduceFunc),
+	}
+}
+
+func (a *aggregateAccumulator) init(
+	start, end time.Time, ptr interface{}) reflect.Value {
+	result := reflect.New(a.aggType)
+	params := [...]reflect.Value{
+		reflect.ValueOf(start), reflect.ValueOf(end), result}
+	a.initFunc.Call(params[:])
+	return result
+}
+
+func (a *aggregateAccumulator) add(acc reflect.Value, ptr interface{}) {
+	params := [...]reflect.Value{acc, reflect.ValueOf(ptr)}
+	a.reduceFunc.Call(params[:])
+}
+
+type sliceAccumulator struct{}
+
+func (s sliceAccumulator) init(
+	start, end time.Time, ptr interface{}) reflect.Value {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(ptr).Elem()))
+}
+
+func (s sliceAccumulator) add(acc reflect.Value, ptr interface{}) {
+	slice := acc.Elem()
+	slice.Set(reflect.Append(slice, reflect.ValueOf(ptr).Elem()))
+}
@@ -0,0 +1,252 @@
+package consume_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	Time  time.Time
+	Value int
+}
+
+type eventSum struct {
+	Start time.Time
+	End   time.Time
+	Count int
+	Total int
+}
+
+var windowEpoch = time.Date(2021, 11, 25, 12, 0, 0, 0, time.UTC)
+
+func eventAt(minutes, value int) event {
+	return event{
+		Time:  windowEpoch.Add(time.Duration(minutes) * time.Minute),
+		Value: value,
+	}
+}
+
+func eventTime(ptr *event) time.Time {
+	return ptr.Time
+}
+
+func initEventSum(start, end time.Time, sum *eventSum) {
+	*sum = eventSum{Start: start, End: end}
+}
+
+func addEvent(sum *eventSum, ptr *event) {
+	sum.Count++
+	sum.Total += ptr.Value
+}
+
+func sumAt(startMinutes, endMinutes, count, total int) eventSum {
+	return eventSum{
+		Start: windowEpoch.Add(time.Duration(startMinutes) * time.Minute),
+		End:   windowEpoch.Add(time.Duration(endMinutes) * time.Minute),
+		Count: count,
+		Total: total,
+	}
+}
+
+func feedEvents(consumer consume.Consumer, events ...event) {
+	for i := range events {
+		if !consumer.CanConsume() {
+			return
+		}
+		consumer.Consume(&events[i])
+	}
+}
+
+func TestWindowedAggregateTumbling(t *testing.T) {
+	assert := assert.New(t)
+	var sums []eventSum
+	cf := consume.WindowedAggregate(
+		consume.AppendTo(&sums),
+		10*time.Minute,
+		10*time.Minute,
+		eventTime,
+		initEventSum,
+		addEvent)
+	feedEvents(
+		cf,
+		eventAt(1, 1), eventAt(9, 2), eventAt(10, 4), eventAt(35, 8))
+	assert.Equal([]eventSum{sumAt(0, 10, 2, 3), sumAt(10, 20, 1, 4)}, sums)
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Equal(
+		[]eventSum{
+			sumAt(0, 10, 2, 3), sumAt(10, 20, 1, 4), sumAt(30, 40, 1, 8)},
+		sums)
+}
+
+func TestWindowedAggregateSliding(t *testing.T) {
+	assert := assert.New(t)
+	var sums []eventSum
+	cf := consume.WindowedAggregate(
+		consume.AppendTo(&sums),
+		10*time.Minute,
+		5*time.Minute,
+		eventTime,
+		initEventSum,
+		addEvent)
+	feedEvents(cf, eventAt(1, 1), eventAt(7, 2), eventAt(12, 4))
+	cf.Finalize()
+	assert.Equal(
+		[]eventSum{
+			sumAt(-5, 5, 1, 1),
+			sumAt(0, 10, 2, 3),
+			sumAt(5, 15, 2, 6),
+			sumAt(10, 20, 1, 4),
+		},
+		sums)
+}
+
+func TestWindowedAggregateDropsLate(t *testing.T) {
+	assert := assert.New(t)
+	var sums []eventSum
+	cf := consume.WindowedAggregate(
+		consume.AppendTo(&sums),
+		10*time.Minute,
+		10*time.Minute,
+		eventTime,
+		initEventSum,
+		addEvent)
+	feedEvents(cf, eventAt(1, 1), eventAt(12, 2), eventAt(3, 4))
+	cf.Finalize()
+	assert.Equal([]eventSum{sumAt(0, 10, 1, 1), sumAt(10, 20, 1, 2)}, sums)
+}
+
+func TestWindowedAggregatePanics(t *testing.T) {
+	assert := assert.New(t)
+	var sums []eventSum
+	assert.Panics(func() {
+		consume.WindowedAggregate(
+			consume.AppendTo(&sums), 0, time.Minute,
+			eventTime, initEventSum, addEvent)
+	})
+	assert.Panics(func() {
+		consume.WindowedAggregate(
+			consume.AppendTo(&sums), time.Minute, 0,
+			eventTime, initEventSum, addEvent)
+	})
+	assert.Panics(func() {
+		consume.WindowedAggregate(
+			consume.AppendTo(&sums), time.Minute, time.Minute,
+			eventTime, func(sum *eventSum) {}, addEvent)
+	})
+	assert.Panics(func() {
+		consume.WindowedAggregate(
+			consume.AppendTo(&sums), time.Minute, time.Minute,
+			eventTime, initEventSum, func(sum *int, ptr *event) {})
+	})
+}
+
+func TestTumblingWindow(t *testing.T) {
+	assert := assert.New(t)
+	var windows [][]event
+	cf := consume.TumblingWindow(
+		consume.AppendTo(&windows), 10*time.Minute, eventTime)
+	feedEvents(
+		cf,
+		eventAt(1, 1), eventAt(9, 2), eventAt(10, 4), eventAt(35, 8),
+		eventAt(36, 16))
+	assert.Equal(
+		[][]event{
+			{eventAt(1, 1), eventAt(9, 2)},
+			{eventAt(10, 4)},
+		},
+		windows)
+	cf.Finalize()
+	assert.Equal(
+		[][]event{
+			{eventAt(1, 1), eventAt(9, 2)},
+			{eventAt(10, 4)},
+			{eventAt(35, 8), eventAt(36, 16)},
+		},
+		windows)
+}
+
+func TestTumblingWindowStopsWhenDownstreamFull(t *testing.T) {
+	assert := assert.New(t)
+	var windows [][]event
+	cf := consume.TumblingWindow(
+		consume.Slice(consume.AppendTo(&windows), 0, 1),
+		time.Minute,
+		eventTime)
+	feedEvents(cf, eventAt(0, 1), eventAt(1, 2), eventAt(2, 4))
+	assert.False(cf.CanConsume())
+	cf.Finalize()
+	assert.Equal([][]event{{eventAt(0, 1)}}, windows)
+}
+
+func TestWindowAllowLateness(t *testing.T) {
+	assert := assert.New(t)
+	var sums []eventSum
+	var late []event
+	cf := consume.WindowedAggregate(
+		consume.AppendTo(&sums),
+		10*time.Minute,
+		10*time.Minute,
+		eventTime,
+		initEventSum,
+		addEvent,
+		consume.AllowLateness(5*time.Minute),
+		consume.LateTo(consume.AppendTo(&late)))
+	feedEvents(cf, eventAt(1, 1), eventAt(12, 2), eventAt(3, 4))
+	assert.Empty(sums)
+	feedEvents(cf, eventAt(15, 8))
+	assert.Equal([]eventSum{sumAt(0, 10, 2, 5)}, sums)
+	feedEvents(cf, eventAt(9, 16), eventAt(11, 32))
+	cf.Finalize()
+	assert.Equal([]eventSum{sumAt(0, 10, 2, 5), sumAt(10, 20, 3, 42)}, sums)
+	assert.Equal([]event{eventAt(9, 16)}, late)
+}
+
+func TestTumblingWindowLateTo(t *testing.T) {
+	assert := assert.New(t)
+	var windows [][]event
+	var late []event
+	cf := consume.TumblingWindow(
+		consume.AppendTo(&windows),
+		time.Minute,
+		eventTime,
+		consume.LateTo(consume.AppendTo(&late)))
+	feedEvents(cf, eventAt(0, 1), eventAt(1, 2), eventAt(0, 4))
+	cf.Finalize()
+	assert.Equal([][]event{{eventAt(0, 1)}, {eventAt(1, 2)}}, windows)
+	assert.Equal([]event{eventAt(0, 4)}, late)
+}
+
+func TestAllowLatenessPanics(t *testing.T) {
+	assert := assert.New(t)
+	assert.Panics(func() { consume.AllowLateness(-time.Second) })
+}
+
+func TestWindowProcessingTime(t *testing.T) {
+	assert := assert.New(t)
+	clock := &fakeClock{now: windowEpoch}
+	var windows [][]int
+	cf := consume.TumblingWindow(
+		consume.AppendTo(&windows),
+		time.Minute,
+		nil,
+		consume.ProcessingTime(clock))
+	for i := 0; i < 5; i++ {
+		cf.Consume(&i)
+		clock.now = clock.now.Add(25 * time.Second)
+	}
+	cf.Finalize()
+	assert.Equal([][]int{{0, 1, 2}, {3, 4}}, windows)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
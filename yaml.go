@@ -0,0 +1,44 @@
+package consume
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML returns a ConsumeFinalizer that writes each consumed value to w
+// as a separate YAML document. Documents are separated by "---". Caller
+// must call Finalize() on the returned consumer when done so that any
+// buffered output gets written to w. If encoding a value fails,
+// CanConsume() returns false from then on, and the Healthy method of the
+// returned consumer reports the error. See Health.
+func ToYAML(w io.Writer) ConsumeFinalizer {
+	return &yamlConsumer{encoder: yaml.NewEncoder(w)}
+}
+
+type yamlConsumer struct {
+	encoder   *yaml.Encoder
+	err       error
+	finalized bool
+}
+
+func (y *yamlConsumer) CanConsume() bool {
+	return y.err == nil && !y.finalized
+}
+
+func (y *yamlConsumer) Consume(ptr interface{}) {
+	MustCanConsume(y)
+	y.err = y.encoder.Encode(ptr)
+}
+
+func (y *yamlConsumer) Finalize() {
+	if y.finalized {
+		return
+	}
+	y.finalized = true
+	y.encoder.Close()
+}
+
+func (y *yamlConsumer) Healthy() error {
+	return y.err
+}
@@ -0,0 +1,30 @@
+package consume_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keep94/consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToYAML(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToYAML(&sb)
+	writePeopleInLoop(people[:], consume.Slice(cf, 0, 2))
+	cf.Finalize()
+	cf.Finalize() // idempotent
+	assert.False(cf.CanConsume())
+	assert.Panics(func() { cf.Consume(&people[mark]) })
+	assert.Equal(
+		"name: Mark\nage: 50\n---\nname: Stoney\nage: 49\n", sb.String())
+}
+
+func TestToYAMLEmpty(t *testing.T) {
+	assert := assert.New(t)
+	var sb strings.Builder
+	cf := consume.ToYAML(&sb)
+	cf.Finalize()
+	assert.Empty(sb.String())
+}